@@ -1,10 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/micro/micro-a2a/pkg/a2a"
+	"github.com/prometheus/client_golang/prometheus"
 	"go-micro.dev/v5/store"
 )
 
@@ -15,22 +17,28 @@ type MyAgentHandlers struct {
 func (a *MyAgentHandlers) TaskHandler(req a2a.JSONRPCRequest) a2a.JSONRPCResponse {
 	prompt := req.Params.(a2a.TaskSendParams).Message.Parts[0].(a2a.TextPart).Text
 
+	// the agent hands over the task with its full history, a follow-up message
+	// for the same task ID continues it instead of starting a new one
+	task, _ := a2a.TaskFromContext(req.Context())
+
 	return a2a.JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      req.ID,
 		Result: &a2a.Task{
-			ID:        uuid.New().String(),
-			ContextID: uuid.New().String(),
+			ID:        task.ID,
+			ContextID: task.ContextID,
 			Status: a2a.TaskStatus{
 				State: a2a.TaskStateCompleted,
 			},
+			History: task.History,
 			Artifacts: []a2a.Artifact{
 				{
-					Name: prompt,
+					ArtifactID: uuid.New().String(),
+					Name:       prompt,
 					Parts: []a2a.Part{
 						a2a.TextPart{
 							Kind: a2a.PartTypeText,
-							Text: time.Now().String(),
+							Text: fmt.Sprintf("%v (turn %d)", time.Now(), len(task.History)),
 						},
 					},
 				},
@@ -55,7 +63,8 @@ func (a *MyAgentHandlers) StreamHandler(req a2a.JSONRPCRequest, res chan a2a.JSO
 				Result: &a2a.TaskArtifactUpdateEvent{
 					ID: uuid.New().String(),
 					Artifact: a2a.Artifact{
-						Name: "time ticks every 1 second",
+						ArtifactID: uuid.New().String(),
+						Name:       "time ticks every 1 second",
 						Parts: []a2a.Part{
 							a2a.TextPart{
 								Kind: a2a.PartTypeText,
@@ -81,6 +90,11 @@ func (a *MyAgentHandlers) StreamHandler(req a2a.JSONRPCRequest, res chan a2a.JSO
 
 			close(res)
 			return
+
+		// the agent is shutting down
+		case <-req.Context().Done():
+			close(res)
+			return
 		}
 	}
 }
@@ -99,6 +113,8 @@ func main() {
 		a2a.WithStore(store.NewMemoryStore()),
 		a2a.WithAgentHandler(agentHandlers),
 		a2a.WithAgentStreamHandler(agentHandlers),
+		a2a.WithShutdownGracePeriod(time.Second*5),
+		a2a.WithMetrics(prometheus.NewRegistry()),
 	)
 
 	agent.SwitchOn()
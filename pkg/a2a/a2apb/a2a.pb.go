@@ -0,0 +1,1661 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: a2a.proto
+
+// The gRPC binding of the A2A operations served by micro-a2a Agents. The messages
+// mirror the objects of the JSON-RPC binding field for field, under the same JSON
+// names, so both bindings carry the same tasks.
+//
+// Regenerate the Go code with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	       --go-grpc_out=. --go-grpc_opt=paths=source_relative a2a.proto
+
+package a2apb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Task struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	ContextId     string                 `protobuf:"bytes,3,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	Status        *TaskStatus            `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	History       []*Message             `protobuf:"bytes,5,rep,name=history,proto3" json:"history,omitempty"`
+	Artifacts     []*Artifact            `protobuf:"bytes,6,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,7,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_a2a_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Task) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Task) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Task) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *Task) GetStatus() *TaskStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *Task) GetHistory() []*Message {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *Task) GetArtifacts() []*Artifact {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+func (x *Task) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type TaskStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// submitted, working, input-required, auth-required, completed, canceled,
+	// failed, rejected or unknown
+	State   string   `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Message *Message `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// RFC 3339
+	Timestamp     string `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskStatus) Reset() {
+	*x = TaskStatus{}
+	mi := &file_a2a_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskStatus) ProtoMessage() {}
+
+func (x *TaskStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskStatus.ProtoReflect.Descriptor instead.
+func (*TaskStatus) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TaskStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *TaskStatus) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *TaskStatus) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+type Message struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Kind      string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	MessageId string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// user or agent
+	Role             string           `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	Parts            []*Part          `protobuf:"bytes,4,rep,name=parts,proto3" json:"parts,omitempty"`
+	Metadata         *structpb.Struct `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	TaskId           string           `protobuf:"bytes,6,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	ContextId        string           `protobuf:"bytes,7,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	ReferenceTaskIds []string         `protobuf:"bytes,8,rep,name=reference_task_ids,json=referenceTaskIds,proto3" json:"reference_task_ids,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_a2a_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Message) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Message) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *Message) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Message) GetParts() []*Part {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+func (x *Message) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Message) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *Message) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *Message) GetReferenceTaskIds() []string {
+	if x != nil {
+		return x.ReferenceTaskIds
+	}
+	return nil
+}
+
+// Part is a text, file or data part, kind tells which of text, file and data is set
+type Part struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	File          *FileContent           `protobuf:"bytes,3,opt,name=file,proto3" json:"file,omitempty"`
+	Data          *structpb.Struct       `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Part) Reset() {
+	*x = Part{}
+	mi := &file_a2a_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Part) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Part) ProtoMessage() {}
+
+func (x *Part) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Part.ProtoReflect.Descriptor instead.
+func (*Part) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Part) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Part) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Part) GetFile() *FileContent {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *Part) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Part) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type FileContent struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Name     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	MimeType string                 `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	// base64 encoded content
+	Bytes         string `protobuf:"bytes,3,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Uri           string `protobuf:"bytes,4,opt,name=uri,proto3" json:"uri,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileContent) Reset() {
+	*x = FileContent{}
+	mi := &file_a2a_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileContent) ProtoMessage() {}
+
+func (x *FileContent) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileContent.ProtoReflect.Descriptor instead.
+func (*FileContent) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FileContent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileContent) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *FileContent) GetBytes() string {
+	if x != nil {
+		return x.Bytes
+	}
+	return ""
+}
+
+func (x *FileContent) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+type Artifact struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ArtifactId    string                 `protobuf:"bytes,1,opt,name=artifact_id,json=artifactId,proto3" json:"artifact_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Parts         []*Part                `protobuf:"bytes,4,rep,name=parts,proto3" json:"parts,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Artifact) Reset() {
+	*x = Artifact{}
+	mi := &file_a2a_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Artifact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifact) ProtoMessage() {}
+
+func (x *Artifact) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
+func (*Artifact) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Artifact) GetArtifactId() string {
+	if x != nil {
+		return x.ArtifactId
+	}
+	return ""
+}
+
+func (x *Artifact) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Artifact) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Artifact) GetParts() []*Part {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+func (x *Artifact) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type TaskStatusUpdateEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status        *TaskStatus            `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Final         bool                   `protobuf:"varint,3,opt,name=final,proto3" json:"final,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskStatusUpdateEvent) Reset() {
+	*x = TaskStatusUpdateEvent{}
+	mi := &file_a2a_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskStatusUpdateEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskStatusUpdateEvent) ProtoMessage() {}
+
+func (x *TaskStatusUpdateEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskStatusUpdateEvent.ProtoReflect.Descriptor instead.
+func (*TaskStatusUpdateEvent) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TaskStatusUpdateEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TaskStatusUpdateEvent) GetStatus() *TaskStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *TaskStatusUpdateEvent) GetFinal() bool {
+	if x != nil {
+		return x.Final
+	}
+	return false
+}
+
+func (x *TaskStatusUpdateEvent) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type TaskArtifactUpdateEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Artifact      *Artifact              `protobuf:"bytes,2,opt,name=artifact,proto3" json:"artifact,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskArtifactUpdateEvent) Reset() {
+	*x = TaskArtifactUpdateEvent{}
+	mi := &file_a2a_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskArtifactUpdateEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskArtifactUpdateEvent) ProtoMessage() {}
+
+func (x *TaskArtifactUpdateEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskArtifactUpdateEvent.ProtoReflect.Descriptor instead.
+func (*TaskArtifactUpdateEvent) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TaskArtifactUpdateEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TaskArtifactUpdateEvent) GetArtifact() *Artifact {
+	if x != nil {
+		return x.Artifact
+	}
+	return nil
+}
+
+func (x *TaskArtifactUpdateEvent) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// TaskEvent is an event of a streamed task
+type TaskEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*TaskEvent_StatusUpdate
+	//	*TaskEvent_ArtifactUpdate
+	Event isTaskEvent_Event `protobuf_oneof:"event"`
+	// the sequence number of the event in the task, ResubscribeTaskRequest resumes
+	// the stream after it
+	EventId       string `protobuf:"bytes,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskEvent) Reset() {
+	*x = TaskEvent{}
+	mi := &file_a2a_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskEvent) ProtoMessage() {}
+
+func (x *TaskEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskEvent.ProtoReflect.Descriptor instead.
+func (*TaskEvent) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TaskEvent) GetEvent() isTaskEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *TaskEvent) GetStatusUpdate() *TaskStatusUpdateEvent {
+	if x != nil {
+		if x, ok := x.Event.(*TaskEvent_StatusUpdate); ok {
+			return x.StatusUpdate
+		}
+	}
+	return nil
+}
+
+func (x *TaskEvent) GetArtifactUpdate() *TaskArtifactUpdateEvent {
+	if x != nil {
+		if x, ok := x.Event.(*TaskEvent_ArtifactUpdate); ok {
+			return x.ArtifactUpdate
+		}
+	}
+	return nil
+}
+
+func (x *TaskEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+type isTaskEvent_Event interface {
+	isTaskEvent_Event()
+}
+
+type TaskEvent_StatusUpdate struct {
+	StatusUpdate *TaskStatusUpdateEvent `protobuf:"bytes,1,opt,name=status_update,json=statusUpdate,proto3,oneof"`
+}
+
+type TaskEvent_ArtifactUpdate struct {
+	ArtifactUpdate *TaskArtifactUpdateEvent `protobuf:"bytes,2,opt,name=artifact_update,json=artifactUpdate,proto3,oneof"`
+}
+
+func (*TaskEvent_StatusUpdate) isTaskEvent_Event() {}
+
+func (*TaskEvent_ArtifactUpdate) isTaskEvent_Event() {}
+
+type AuthenticationInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schemes       []string               `protobuf:"bytes,1,rep,name=schemes,proto3" json:"schemes,omitempty"`
+	Credentials   string                 `protobuf:"bytes,2,opt,name=credentials,proto3" json:"credentials,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthenticationInfo) Reset() {
+	*x = AuthenticationInfo{}
+	mi := &file_a2a_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthenticationInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthenticationInfo) ProtoMessage() {}
+
+func (x *AuthenticationInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthenticationInfo.ProtoReflect.Descriptor instead.
+func (*AuthenticationInfo) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AuthenticationInfo) GetSchemes() []string {
+	if x != nil {
+		return x.Schemes
+	}
+	return nil
+}
+
+func (x *AuthenticationInfo) GetCredentials() string {
+	if x != nil {
+		return x.Credentials
+	}
+	return ""
+}
+
+type PushNotificationConfig struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Url            string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Token          string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	Authentication *AuthenticationInfo    `protobuf:"bytes,3,opt,name=authentication,proto3" json:"authentication,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PushNotificationConfig) Reset() {
+	*x = PushNotificationConfig{}
+	mi := &file_a2a_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushNotificationConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushNotificationConfig) ProtoMessage() {}
+
+func (x *PushNotificationConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushNotificationConfig.ProtoReflect.Descriptor instead.
+func (*PushNotificationConfig) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PushNotificationConfig) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *PushNotificationConfig) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *PushNotificationConfig) GetAuthentication() *AuthenticationInfo {
+	if x != nil {
+		return x.Authentication
+	}
+	return nil
+}
+
+type TaskPushNotificationConfig struct {
+	state                  protoimpl.MessageState  `protogen:"open.v1"`
+	Id                     string                  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PushNotificationConfig *PushNotificationConfig `protobuf:"bytes,2,opt,name=push_notification_config,json=pushNotificationConfig,proto3" json:"push_notification_config,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *TaskPushNotificationConfig) Reset() {
+	*x = TaskPushNotificationConfig{}
+	mi := &file_a2a_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskPushNotificationConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskPushNotificationConfig) ProtoMessage() {}
+
+func (x *TaskPushNotificationConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskPushNotificationConfig.ProtoReflect.Descriptor instead.
+func (*TaskPushNotificationConfig) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TaskPushNotificationConfig) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TaskPushNotificationConfig) GetPushNotificationConfig() *PushNotificationConfig {
+	if x != nil {
+		return x.PushNotificationConfig
+	}
+	return nil
+}
+
+type SendTaskRequest struct {
+	state               protoimpl.MessageState  `protogen:"open.v1"`
+	Id                  string                  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SessionId           string                  `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Message             *Message                `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	HistoryLength       int32                   `protobuf:"varint,4,opt,name=history_length,json=historyLength,proto3" json:"history_length,omitempty"`
+	PushNotification    *PushNotificationConfig `protobuf:"bytes,5,opt,name=push_notification,json=pushNotification,proto3" json:"push_notification,omitempty"`
+	Metadata            *structpb.Struct        `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	AcceptedOutputModes []string                `protobuf:"bytes,7,rep,name=accepted_output_modes,json=acceptedOutputModes,proto3" json:"accepted_output_modes,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SendTaskRequest) Reset() {
+	*x = SendTaskRequest{}
+	mi := &file_a2a_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendTaskRequest) ProtoMessage() {}
+
+func (x *SendTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendTaskRequest.ProtoReflect.Descriptor instead.
+func (*SendTaskRequest) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SendTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SendTaskRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SendTaskRequest) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *SendTaskRequest) GetHistoryLength() int32 {
+	if x != nil {
+		return x.HistoryLength
+	}
+	return 0
+}
+
+func (x *SendTaskRequest) GetPushNotification() *PushNotificationConfig {
+	if x != nil {
+		return x.PushNotification
+	}
+	return nil
+}
+
+func (x *SendTaskRequest) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *SendTaskRequest) GetAcceptedOutputModes() []string {
+	if x != nil {
+		return x.AcceptedOutputModes
+	}
+	return nil
+}
+
+type GetTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	HistoryLength int32                  `protobuf:"varint,2,opt,name=history_length,json=historyLength,proto3" json:"history_length,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskRequest) Reset() {
+	*x = GetTaskRequest{}
+	mi := &file_a2a_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskRequest) ProtoMessage() {}
+
+func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskRequest) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetTaskRequest) GetHistoryLength() int32 {
+	if x != nil {
+		return x.HistoryLength
+	}
+	return 0
+}
+
+func (x *GetTaskRequest) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type CancelTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTaskRequest) Reset() {
+	*x = CancelTaskRequest{}
+	mi := &file_a2a_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTaskRequest) ProtoMessage() {}
+
+func (x *CancelTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTaskRequest.ProtoReflect.Descriptor instead.
+func (*CancelTaskRequest) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CancelTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CancelTaskRequest) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ResubscribeTaskRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Metadata *structpb.Struct       `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// the event_id of the last event received, the events after it are replayed
+	LastEventId   string `protobuf:"bytes,3,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResubscribeTaskRequest) Reset() {
+	*x = ResubscribeTaskRequest{}
+	mi := &file_a2a_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResubscribeTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResubscribeTaskRequest) ProtoMessage() {}
+
+func (x *ResubscribeTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResubscribeTaskRequest.ProtoReflect.Descriptor instead.
+func (*ResubscribeTaskRequest) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ResubscribeTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ResubscribeTaskRequest) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ResubscribeTaskRequest) GetLastEventId() string {
+	if x != nil {
+		return x.LastEventId
+	}
+	return ""
+}
+
+type GetTaskPushNotificationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskPushNotificationRequest) Reset() {
+	*x = GetTaskPushNotificationRequest{}
+	mi := &file_a2a_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskPushNotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskPushNotificationRequest) ProtoMessage() {}
+
+func (x *GetTaskPushNotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskPushNotificationRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskPushNotificationRequest) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetTaskPushNotificationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetTaskPushNotificationRequest) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ListTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	ContextId     string                 `protobuf:"bytes,2,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	SkillId       string                 `protobuf:"bytes,3,opt,name=skill_id,json=skillId,proto3" json:"skill_id,omitempty"`
+	Caller        string                 `protobuf:"bytes,4,opt,name=caller,proto3" json:"caller,omitempty"`
+	CreatedAfter  string                 `protobuf:"bytes,5,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore string                 `protobuf:"bytes,6,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	Cursor        string                 `protobuf:"bytes,7,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	PageSize      int32                  `protobuf:"varint,8,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	HistoryLength int32                  `protobuf:"varint,9,opt,name=history_length,json=historyLength,proto3" json:"history_length,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,10,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_a2a_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListTasksRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetSkillId() string {
+	if x != nil {
+		return x.SkillId
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetCaller() string {
+	if x != nil {
+		return x.Caller
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetCreatedAfter() string {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetCreatedBefore() string {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetHistoryLength() int32 {
+	if x != nil {
+		return x.HistoryLength
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type TaskList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskList) Reset() {
+	*x = TaskList{}
+	mi := &file_a2a_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskList) ProtoMessage() {}
+
+func (x *TaskList) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskList.ProtoReflect.Descriptor instead.
+func (*TaskList) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TaskList) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+func (x *TaskList) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type GetAgentCardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAgentCardRequest) Reset() {
+	*x = GetAgentCardRequest{}
+	mi := &file_a2a_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAgentCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAgentCardRequest) ProtoMessage() {}
+
+func (x *GetAgentCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_a2a_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentCardRequest.ProtoReflect.Descriptor instead.
+func (*GetAgentCardRequest) Descriptor() ([]byte, []int) {
+	return file_a2a_proto_rawDescGZIP(), []int{19}
+}
+
+var File_a2a_proto protoreflect.FileDescriptor
+
+const file_a2a_proto_rawDesc = "" +
+	"\n" +
+	"\ta2a.proto\x12\x06a2a.v1\x1a\x1cgoogle/protobuf/struct.proto\"\x85\x02\n" +
+	"\x04Task\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x03 \x01(\tR\tcontextId\x12*\n" +
+	"\x06status\x18\x04 \x01(\v2\x12.a2a.v1.TaskStatusR\x06status\x12)\n" +
+	"\ahistory\x18\x05 \x03(\v2\x0f.a2a.v1.MessageR\ahistory\x12.\n" +
+	"\tartifacts\x18\x06 \x03(\v2\x10.a2a.v1.ArtifactR\tartifacts\x123\n" +
+	"\bmetadata\x18\a \x01(\v2\x17.google.protobuf.StructR\bmetadata\"k\n" +
+	"\n" +
+	"TaskStatus\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x12)\n" +
+	"\amessage\x18\x02 \x01(\v2\x0f.a2a.v1.MessageR\amessage\x12\x1c\n" +
+	"\ttimestamp\x18\x03 \x01(\tR\ttimestamp\"\x8f\x02\n" +
+	"\aMessage\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\x12\"\n" +
+	"\x05parts\x18\x04 \x03(\v2\f.a2a.v1.PartR\x05parts\x123\n" +
+	"\bmetadata\x18\x05 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12\x17\n" +
+	"\atask_id\x18\x06 \x01(\tR\x06taskId\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\a \x01(\tR\tcontextId\x12,\n" +
+	"\x12reference_task_ids\x18\b \x03(\tR\x10referenceTaskIds\"\xb9\x01\n" +
+	"\x04Part\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12'\n" +
+	"\x04file\x18\x03 \x01(\v2\x13.a2a.v1.FileContentR\x04file\x12+\n" +
+	"\x04data\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x04data\x123\n" +
+	"\bmetadata\x18\x05 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"f\n" +
+	"\vFileContent\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\tR\x05bytes\x12\x10\n" +
+	"\x03uri\x18\x04 \x01(\tR\x03uri\"\xba\x01\n" +
+	"\bArtifact\x12\x1f\n" +
+	"\vartifact_id\x18\x01 \x01(\tR\n" +
+	"artifactId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\"\n" +
+	"\x05parts\x18\x04 \x03(\v2\f.a2a.v1.PartR\x05parts\x123\n" +
+	"\bmetadata\x18\x05 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"\x9e\x01\n" +
+	"\x15TaskStatusUpdateEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12*\n" +
+	"\x06status\x18\x02 \x01(\v2\x12.a2a.v1.TaskStatusR\x06status\x12\x14\n" +
+	"\x05final\x18\x03 \x01(\bR\x05final\x123\n" +
+	"\bmetadata\x18\x04 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"\x8c\x01\n" +
+	"\x17TaskArtifactUpdateEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
+	"\bartifact\x18\x02 \x01(\v2\x10.a2a.v1.ArtifactR\bartifact\x123\n" +
+	"\bmetadata\x18\x03 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"\xc1\x01\n" +
+	"\tTaskEvent\x12D\n" +
+	"\rstatus_update\x18\x01 \x01(\v2\x1d.a2a.v1.TaskStatusUpdateEventH\x00R\fstatusUpdate\x12J\n" +
+	"\x0fartifact_update\x18\x02 \x01(\v2\x1f.a2a.v1.TaskArtifactUpdateEventH\x00R\x0eartifactUpdate\x12\x19\n" +
+	"\bevent_id\x18\x03 \x01(\tR\aeventIdB\a\n" +
+	"\x05event\"P\n" +
+	"\x12AuthenticationInfo\x12\x18\n" +
+	"\aschemes\x18\x01 \x03(\tR\aschemes\x12 \n" +
+	"\vcredentials\x18\x02 \x01(\tR\vcredentials\"\x84\x01\n" +
+	"\x16PushNotificationConfig\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12B\n" +
+	"\x0eauthentication\x18\x03 \x01(\v2\x1a.a2a.v1.AuthenticationInfoR\x0eauthentication\"\x86\x01\n" +
+	"\x1aTaskPushNotificationConfig\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12X\n" +
+	"\x18push_notification_config\x18\x02 \x01(\v2\x1e.a2a.v1.PushNotificationConfigR\x16pushNotificationConfig\"\xc8\x02\n" +
+	"\x0fSendTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12)\n" +
+	"\amessage\x18\x03 \x01(\v2\x0f.a2a.v1.MessageR\amessage\x12%\n" +
+	"\x0ehistory_length\x18\x04 \x01(\x05R\rhistoryLength\x12K\n" +
+	"\x11push_notification\x18\x05 \x01(\v2\x1e.a2a.v1.PushNotificationConfigR\x10pushNotification\x123\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x122\n" +
+	"\x15accepted_output_modes\x18\a \x03(\tR\x13acceptedOutputModes\"|\n" +
+	"\x0eGetTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12%\n" +
+	"\x0ehistory_length\x18\x02 \x01(\x05R\rhistoryLength\x123\n" +
+	"\bmetadata\x18\x03 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"X\n" +
+	"\x11CancelTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x123\n" +
+	"\bmetadata\x18\x02 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"\x81\x01\n" +
+	"\x16ResubscribeTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x123\n" +
+	"\bmetadata\x18\x02 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12\"\n" +
+	"\rlast_event_id\x18\x03 \x01(\tR\vlastEventId\"e\n" +
+	"\x1eGetTaskPushNotificationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x123\n" +
+	"\bmetadata\x18\x02 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"\xd7\x02\n" +
+	"\x10ListTasksRequest\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x02 \x01(\tR\tcontextId\x12\x19\n" +
+	"\bskill_id\x18\x03 \x01(\tR\askillId\x12\x16\n" +
+	"\x06caller\x18\x04 \x01(\tR\x06caller\x12#\n" +
+	"\rcreated_after\x18\x05 \x01(\tR\fcreatedAfter\x12%\n" +
+	"\x0ecreated_before\x18\x06 \x01(\tR\rcreatedBefore\x12\x16\n" +
+	"\x06cursor\x18\a \x01(\tR\x06cursor\x12\x1b\n" +
+	"\tpage_size\x18\b \x01(\x05R\bpageSize\x12%\n" +
+	"\x0ehistory_length\x18\t \x01(\x05R\rhistoryLength\x123\n" +
+	"\bmetadata\x18\n" +
+	" \x01(\v2\x17.google.protobuf.StructR\bmetadata\"O\n" +
+	"\bTaskList\x12\"\n" +
+	"\x05tasks\x18\x01 \x03(\v2\f.a2a.v1.TaskR\x05tasks\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"\x15\n" +
+	"\x13GetAgentCardRequest2\xfb\x04\n" +
+	"\n" +
+	"A2AService\x121\n" +
+	"\bSendTask\x12\x17.a2a.v1.SendTaskRequest\x1a\f.a2a.v1.Task\x12A\n" +
+	"\x11SendTaskSubscribe\x12\x17.a2a.v1.SendTaskRequest\x1a\x11.a2a.v1.TaskEvent0\x01\x12/\n" +
+	"\aGetTask\x12\x16.a2a.v1.GetTaskRequest\x1a\f.a2a.v1.Task\x125\n" +
+	"\n" +
+	"CancelTask\x12\x19.a2a.v1.CancelTaskRequest\x1a\f.a2a.v1.Task\x12F\n" +
+	"\x0fResubscribeTask\x12\x1e.a2a.v1.ResubscribeTaskRequest\x1a\x11.a2a.v1.TaskEvent0\x01\x12a\n" +
+	"\x17SetTaskPushNotification\x12\".a2a.v1.TaskPushNotificationConfig\x1a\".a2a.v1.TaskPushNotificationConfig\x12e\n" +
+	"\x17GetTaskPushNotification\x12&.a2a.v1.GetTaskPushNotificationRequest\x1a\".a2a.v1.TaskPushNotificationConfig\x127\n" +
+	"\tListTasks\x12\x18.a2a.v1.ListTasksRequest\x1a\x10.a2a.v1.TaskList\x12D\n" +
+	"\fGetAgentCard\x12\x1b.a2a.v1.GetAgentCardRequest\x1a\x17.google.protobuf.StructB0Z.github.com/micro/micro-a2a/pkg/a2a/a2apb;a2apbb\x06proto3"
+
+var (
+	file_a2a_proto_rawDescOnce sync.Once
+	file_a2a_proto_rawDescData []byte
+)
+
+func file_a2a_proto_rawDescGZIP() []byte {
+	file_a2a_proto_rawDescOnce.Do(func() {
+		file_a2a_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_a2a_proto_rawDesc), len(file_a2a_proto_rawDesc)))
+	})
+	return file_a2a_proto_rawDescData
+}
+
+var file_a2a_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_a2a_proto_goTypes = []any{
+	(*Task)(nil),                           // 0: a2a.v1.Task
+	(*TaskStatus)(nil),                     // 1: a2a.v1.TaskStatus
+	(*Message)(nil),                        // 2: a2a.v1.Message
+	(*Part)(nil),                           // 3: a2a.v1.Part
+	(*FileContent)(nil),                    // 4: a2a.v1.FileContent
+	(*Artifact)(nil),                       // 5: a2a.v1.Artifact
+	(*TaskStatusUpdateEvent)(nil),          // 6: a2a.v1.TaskStatusUpdateEvent
+	(*TaskArtifactUpdateEvent)(nil),        // 7: a2a.v1.TaskArtifactUpdateEvent
+	(*TaskEvent)(nil),                      // 8: a2a.v1.TaskEvent
+	(*AuthenticationInfo)(nil),             // 9: a2a.v1.AuthenticationInfo
+	(*PushNotificationConfig)(nil),         // 10: a2a.v1.PushNotificationConfig
+	(*TaskPushNotificationConfig)(nil),     // 11: a2a.v1.TaskPushNotificationConfig
+	(*SendTaskRequest)(nil),                // 12: a2a.v1.SendTaskRequest
+	(*GetTaskRequest)(nil),                 // 13: a2a.v1.GetTaskRequest
+	(*CancelTaskRequest)(nil),              // 14: a2a.v1.CancelTaskRequest
+	(*ResubscribeTaskRequest)(nil),         // 15: a2a.v1.ResubscribeTaskRequest
+	(*GetTaskPushNotificationRequest)(nil), // 16: a2a.v1.GetTaskPushNotificationRequest
+	(*ListTasksRequest)(nil),               // 17: a2a.v1.ListTasksRequest
+	(*TaskList)(nil),                       // 18: a2a.v1.TaskList
+	(*GetAgentCardRequest)(nil),            // 19: a2a.v1.GetAgentCardRequest
+	(*structpb.Struct)(nil),                // 20: google.protobuf.Struct
+}
+var file_a2a_proto_depIdxs = []int32{
+	1,  // 0: a2a.v1.Task.status:type_name -> a2a.v1.TaskStatus
+	2,  // 1: a2a.v1.Task.history:type_name -> a2a.v1.Message
+	5,  // 2: a2a.v1.Task.artifacts:type_name -> a2a.v1.Artifact
+	20, // 3: a2a.v1.Task.metadata:type_name -> google.protobuf.Struct
+	2,  // 4: a2a.v1.TaskStatus.message:type_name -> a2a.v1.Message
+	3,  // 5: a2a.v1.Message.parts:type_name -> a2a.v1.Part
+	20, // 6: a2a.v1.Message.metadata:type_name -> google.protobuf.Struct
+	4,  // 7: a2a.v1.Part.file:type_name -> a2a.v1.FileContent
+	20, // 8: a2a.v1.Part.data:type_name -> google.protobuf.Struct
+	20, // 9: a2a.v1.Part.metadata:type_name -> google.protobuf.Struct
+	3,  // 10: a2a.v1.Artifact.parts:type_name -> a2a.v1.Part
+	20, // 11: a2a.v1.Artifact.metadata:type_name -> google.protobuf.Struct
+	1,  // 12: a2a.v1.TaskStatusUpdateEvent.status:type_name -> a2a.v1.TaskStatus
+	20, // 13: a2a.v1.TaskStatusUpdateEvent.metadata:type_name -> google.protobuf.Struct
+	5,  // 14: a2a.v1.TaskArtifactUpdateEvent.artifact:type_name -> a2a.v1.Artifact
+	20, // 15: a2a.v1.TaskArtifactUpdateEvent.metadata:type_name -> google.protobuf.Struct
+	6,  // 16: a2a.v1.TaskEvent.status_update:type_name -> a2a.v1.TaskStatusUpdateEvent
+	7,  // 17: a2a.v1.TaskEvent.artifact_update:type_name -> a2a.v1.TaskArtifactUpdateEvent
+	9,  // 18: a2a.v1.PushNotificationConfig.authentication:type_name -> a2a.v1.AuthenticationInfo
+	10, // 19: a2a.v1.TaskPushNotificationConfig.push_notification_config:type_name -> a2a.v1.PushNotificationConfig
+	2,  // 20: a2a.v1.SendTaskRequest.message:type_name -> a2a.v1.Message
+	10, // 21: a2a.v1.SendTaskRequest.push_notification:type_name -> a2a.v1.PushNotificationConfig
+	20, // 22: a2a.v1.SendTaskRequest.metadata:type_name -> google.protobuf.Struct
+	20, // 23: a2a.v1.GetTaskRequest.metadata:type_name -> google.protobuf.Struct
+	20, // 24: a2a.v1.CancelTaskRequest.metadata:type_name -> google.protobuf.Struct
+	20, // 25: a2a.v1.ResubscribeTaskRequest.metadata:type_name -> google.protobuf.Struct
+	20, // 26: a2a.v1.GetTaskPushNotificationRequest.metadata:type_name -> google.protobuf.Struct
+	20, // 27: a2a.v1.ListTasksRequest.metadata:type_name -> google.protobuf.Struct
+	0,  // 28: a2a.v1.TaskList.tasks:type_name -> a2a.v1.Task
+	12, // 29: a2a.v1.A2AService.SendTask:input_type -> a2a.v1.SendTaskRequest
+	12, // 30: a2a.v1.A2AService.SendTaskSubscribe:input_type -> a2a.v1.SendTaskRequest
+	13, // 31: a2a.v1.A2AService.GetTask:input_type -> a2a.v1.GetTaskRequest
+	14, // 32: a2a.v1.A2AService.CancelTask:input_type -> a2a.v1.CancelTaskRequest
+	15, // 33: a2a.v1.A2AService.ResubscribeTask:input_type -> a2a.v1.ResubscribeTaskRequest
+	11, // 34: a2a.v1.A2AService.SetTaskPushNotification:input_type -> a2a.v1.TaskPushNotificationConfig
+	16, // 35: a2a.v1.A2AService.GetTaskPushNotification:input_type -> a2a.v1.GetTaskPushNotificationRequest
+	17, // 36: a2a.v1.A2AService.ListTasks:input_type -> a2a.v1.ListTasksRequest
+	19, // 37: a2a.v1.A2AService.GetAgentCard:input_type -> a2a.v1.GetAgentCardRequest
+	0,  // 38: a2a.v1.A2AService.SendTask:output_type -> a2a.v1.Task
+	8,  // 39: a2a.v1.A2AService.SendTaskSubscribe:output_type -> a2a.v1.TaskEvent
+	0,  // 40: a2a.v1.A2AService.GetTask:output_type -> a2a.v1.Task
+	0,  // 41: a2a.v1.A2AService.CancelTask:output_type -> a2a.v1.Task
+	8,  // 42: a2a.v1.A2AService.ResubscribeTask:output_type -> a2a.v1.TaskEvent
+	11, // 43: a2a.v1.A2AService.SetTaskPushNotification:output_type -> a2a.v1.TaskPushNotificationConfig
+	11, // 44: a2a.v1.A2AService.GetTaskPushNotification:output_type -> a2a.v1.TaskPushNotificationConfig
+	18, // 45: a2a.v1.A2AService.ListTasks:output_type -> a2a.v1.TaskList
+	20, // 46: a2a.v1.A2AService.GetAgentCard:output_type -> google.protobuf.Struct
+	38, // [38:47] is the sub-list for method output_type
+	29, // [29:38] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
+}
+
+func init() { file_a2a_proto_init() }
+func file_a2a_proto_init() {
+	if File_a2a_proto != nil {
+		return
+	}
+	file_a2a_proto_msgTypes[8].OneofWrappers = []any{
+		(*TaskEvent_StatusUpdate)(nil),
+		(*TaskEvent_ArtifactUpdate)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_a2a_proto_rawDesc), len(file_a2a_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   20,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_a2a_proto_goTypes,
+		DependencyIndexes: file_a2a_proto_depIdxs,
+		MessageInfos:      file_a2a_proto_msgTypes,
+	}.Build()
+	File_a2a_proto = out.File
+	file_a2a_proto_goTypes = nil
+	file_a2a_proto_depIdxs = nil
+}
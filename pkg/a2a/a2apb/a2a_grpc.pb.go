@@ -0,0 +1,468 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: a2a.proto
+
+// The gRPC binding of the A2A operations served by micro-a2a Agents. The messages
+// mirror the objects of the JSON-RPC binding field for field, under the same JSON
+// names, so both bindings carry the same tasks.
+//
+// Regenerate the Go code with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	       --go-grpc_out=. --go-grpc_opt=paths=source_relative a2a.proto
+
+package a2apb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	A2AService_SendTask_FullMethodName                = "/a2a.v1.A2AService/SendTask"
+	A2AService_SendTaskSubscribe_FullMethodName       = "/a2a.v1.A2AService/SendTaskSubscribe"
+	A2AService_GetTask_FullMethodName                 = "/a2a.v1.A2AService/GetTask"
+	A2AService_CancelTask_FullMethodName              = "/a2a.v1.A2AService/CancelTask"
+	A2AService_ResubscribeTask_FullMethodName         = "/a2a.v1.A2AService/ResubscribeTask"
+	A2AService_SetTaskPushNotification_FullMethodName = "/a2a.v1.A2AService/SetTaskPushNotification"
+	A2AService_GetTaskPushNotification_FullMethodName = "/a2a.v1.A2AService/GetTaskPushNotification"
+	A2AService_ListTasks_FullMethodName               = "/a2a.v1.A2AService/ListTasks"
+	A2AService_GetAgentCard_FullMethodName            = "/a2a.v1.A2AService/GetAgentCard"
+)
+
+// A2AServiceClient is the client API for A2AService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// A2AService serves the A2A operations of an Agent. A failed call returns the
+// status matching the A2A error, its trailer carries the A2A error code under
+// a2a-error-code and its data, JSON encoded, under a2a-error-data.
+type A2AServiceClient interface {
+	// tasks/send
+	SendTask(ctx context.Context, in *SendTaskRequest, opts ...grpc.CallOption) (*Task, error)
+	// tasks/sendSubscribe, the stream ends after the final event
+	SendTaskSubscribe(ctx context.Context, in *SendTaskRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskEvent], error)
+	// tasks/get
+	GetTask(ctx context.Context, in *GetTaskRequest, opts ...grpc.CallOption) (*Task, error)
+	// tasks/cancel
+	CancelTask(ctx context.Context, in *CancelTaskRequest, opts ...grpc.CallOption) (*Task, error)
+	// tasks/resubscribe, the stream ends after the final event
+	ResubscribeTask(ctx context.Context, in *ResubscribeTaskRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskEvent], error)
+	// tasks/pushNotification/set
+	SetTaskPushNotification(ctx context.Context, in *TaskPushNotificationConfig, opts ...grpc.CallOption) (*TaskPushNotificationConfig, error)
+	// tasks/pushNotification/get
+	GetTaskPushNotification(ctx context.Context, in *GetTaskPushNotificationRequest, opts ...grpc.CallOption) (*TaskPushNotificationConfig, error)
+	// tasks/list, an extension method
+	ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*TaskList, error)
+	// the AgentCard, as its JSON object
+	GetAgentCard(ctx context.Context, in *GetAgentCardRequest, opts ...grpc.CallOption) (*structpb.Struct, error)
+}
+
+type a2AServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewA2AServiceClient(cc grpc.ClientConnInterface) A2AServiceClient {
+	return &a2AServiceClient{cc}
+}
+
+func (c *a2AServiceClient) SendTask(ctx context.Context, in *SendTaskRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, A2AService_SendTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *a2AServiceClient) SendTaskSubscribe(ctx context.Context, in *SendTaskRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &A2AService_ServiceDesc.Streams[0], A2AService_SendTaskSubscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SendTaskRequest, TaskEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type A2AService_SendTaskSubscribeClient = grpc.ServerStreamingClient[TaskEvent]
+
+func (c *a2AServiceClient) GetTask(ctx context.Context, in *GetTaskRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, A2AService_GetTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *a2AServiceClient) CancelTask(ctx context.Context, in *CancelTaskRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, A2AService_CancelTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *a2AServiceClient) ResubscribeTask(ctx context.Context, in *ResubscribeTaskRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &A2AService_ServiceDesc.Streams[1], A2AService_ResubscribeTask_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ResubscribeTaskRequest, TaskEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type A2AService_ResubscribeTaskClient = grpc.ServerStreamingClient[TaskEvent]
+
+func (c *a2AServiceClient) SetTaskPushNotification(ctx context.Context, in *TaskPushNotificationConfig, opts ...grpc.CallOption) (*TaskPushNotificationConfig, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskPushNotificationConfig)
+	err := c.cc.Invoke(ctx, A2AService_SetTaskPushNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *a2AServiceClient) GetTaskPushNotification(ctx context.Context, in *GetTaskPushNotificationRequest, opts ...grpc.CallOption) (*TaskPushNotificationConfig, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskPushNotificationConfig)
+	err := c.cc.Invoke(ctx, A2AService_GetTaskPushNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *a2AServiceClient) ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*TaskList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskList)
+	err := c.cc.Invoke(ctx, A2AService_ListTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *a2AServiceClient) GetAgentCard(ctx context.Context, in *GetAgentCardRequest, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(structpb.Struct)
+	err := c.cc.Invoke(ctx, A2AService_GetAgentCard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// A2AServiceServer is the server API for A2AService service.
+// All implementations must embed UnimplementedA2AServiceServer
+// for forward compatibility.
+//
+// A2AService serves the A2A operations of an Agent. A failed call returns the
+// status matching the A2A error, its trailer carries the A2A error code under
+// a2a-error-code and its data, JSON encoded, under a2a-error-data.
+type A2AServiceServer interface {
+	// tasks/send
+	SendTask(context.Context, *SendTaskRequest) (*Task, error)
+	// tasks/sendSubscribe, the stream ends after the final event
+	SendTaskSubscribe(*SendTaskRequest, grpc.ServerStreamingServer[TaskEvent]) error
+	// tasks/get
+	GetTask(context.Context, *GetTaskRequest) (*Task, error)
+	// tasks/cancel
+	CancelTask(context.Context, *CancelTaskRequest) (*Task, error)
+	// tasks/resubscribe, the stream ends after the final event
+	ResubscribeTask(*ResubscribeTaskRequest, grpc.ServerStreamingServer[TaskEvent]) error
+	// tasks/pushNotification/set
+	SetTaskPushNotification(context.Context, *TaskPushNotificationConfig) (*TaskPushNotificationConfig, error)
+	// tasks/pushNotification/get
+	GetTaskPushNotification(context.Context, *GetTaskPushNotificationRequest) (*TaskPushNotificationConfig, error)
+	// tasks/list, an extension method
+	ListTasks(context.Context, *ListTasksRequest) (*TaskList, error)
+	// the AgentCard, as its JSON object
+	GetAgentCard(context.Context, *GetAgentCardRequest) (*structpb.Struct, error)
+	mustEmbedUnimplementedA2AServiceServer()
+}
+
+// UnimplementedA2AServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedA2AServiceServer struct{}
+
+func (UnimplementedA2AServiceServer) SendTask(context.Context, *SendTaskRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendTask not implemented")
+}
+func (UnimplementedA2AServiceServer) SendTaskSubscribe(*SendTaskRequest, grpc.ServerStreamingServer[TaskEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method SendTaskSubscribe not implemented")
+}
+func (UnimplementedA2AServiceServer) GetTask(context.Context, *GetTaskRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTask not implemented")
+}
+func (UnimplementedA2AServiceServer) CancelTask(context.Context, *CancelTaskRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelTask not implemented")
+}
+func (UnimplementedA2AServiceServer) ResubscribeTask(*ResubscribeTaskRequest, grpc.ServerStreamingServer[TaskEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method ResubscribeTask not implemented")
+}
+func (UnimplementedA2AServiceServer) SetTaskPushNotification(context.Context, *TaskPushNotificationConfig) (*TaskPushNotificationConfig, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTaskPushNotification not implemented")
+}
+func (UnimplementedA2AServiceServer) GetTaskPushNotification(context.Context, *GetTaskPushNotificationRequest) (*TaskPushNotificationConfig, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTaskPushNotification not implemented")
+}
+func (UnimplementedA2AServiceServer) ListTasks(context.Context, *ListTasksRequest) (*TaskList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTasks not implemented")
+}
+func (UnimplementedA2AServiceServer) GetAgentCard(context.Context, *GetAgentCardRequest) (*structpb.Struct, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAgentCard not implemented")
+}
+func (UnimplementedA2AServiceServer) mustEmbedUnimplementedA2AServiceServer() {}
+func (UnimplementedA2AServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeA2AServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to A2AServiceServer will
+// result in compilation errors.
+type UnsafeA2AServiceServer interface {
+	mustEmbedUnimplementedA2AServiceServer()
+}
+
+func RegisterA2AServiceServer(s grpc.ServiceRegistrar, srv A2AServiceServer) {
+	// If the following call pancis, it indicates UnimplementedA2AServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&A2AService_ServiceDesc, srv)
+}
+
+func _A2AService_SendTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(A2AServiceServer).SendTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: A2AService_SendTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(A2AServiceServer).SendTask(ctx, req.(*SendTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _A2AService_SendTaskSubscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SendTaskRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(A2AServiceServer).SendTaskSubscribe(m, &grpc.GenericServerStream[SendTaskRequest, TaskEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type A2AService_SendTaskSubscribeServer = grpc.ServerStreamingServer[TaskEvent]
+
+func _A2AService_GetTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(A2AServiceServer).GetTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: A2AService_GetTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(A2AServiceServer).GetTask(ctx, req.(*GetTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _A2AService_CancelTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(A2AServiceServer).CancelTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: A2AService_CancelTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(A2AServiceServer).CancelTask(ctx, req.(*CancelTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _A2AService_ResubscribeTask_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ResubscribeTaskRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(A2AServiceServer).ResubscribeTask(m, &grpc.GenericServerStream[ResubscribeTaskRequest, TaskEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type A2AService_ResubscribeTaskServer = grpc.ServerStreamingServer[TaskEvent]
+
+func _A2AService_SetTaskPushNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskPushNotificationConfig)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(A2AServiceServer).SetTaskPushNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: A2AService_SetTaskPushNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(A2AServiceServer).SetTaskPushNotification(ctx, req.(*TaskPushNotificationConfig))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _A2AService_GetTaskPushNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaskPushNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(A2AServiceServer).GetTaskPushNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: A2AService_GetTaskPushNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(A2AServiceServer).GetTaskPushNotification(ctx, req.(*GetTaskPushNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _A2AService_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(A2AServiceServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: A2AService_ListTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(A2AServiceServer).ListTasks(ctx, req.(*ListTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _A2AService_GetAgentCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAgentCardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(A2AServiceServer).GetAgentCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: A2AService_GetAgentCard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(A2AServiceServer).GetAgentCard(ctx, req.(*GetAgentCardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// A2AService_ServiceDesc is the grpc.ServiceDesc for A2AService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var A2AService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "a2a.v1.A2AService",
+	HandlerType: (*A2AServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendTask",
+			Handler:    _A2AService_SendTask_Handler,
+		},
+		{
+			MethodName: "GetTask",
+			Handler:    _A2AService_GetTask_Handler,
+		},
+		{
+			MethodName: "CancelTask",
+			Handler:    _A2AService_CancelTask_Handler,
+		},
+		{
+			MethodName: "SetTaskPushNotification",
+			Handler:    _A2AService_SetTaskPushNotification_Handler,
+		},
+		{
+			MethodName: "GetTaskPushNotification",
+			Handler:    _A2AService_GetTaskPushNotification_Handler,
+		},
+		{
+			MethodName: "ListTasks",
+			Handler:    _A2AService_ListTasks_Handler,
+		},
+		{
+			MethodName: "GetAgentCard",
+			Handler:    _A2AService_GetAgentCard_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SendTaskSubscribe",
+			Handler:       _A2AService_SendTaskSubscribe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ResubscribeTask",
+			Handler:       _A2AService_ResubscribeTask_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "a2a.proto",
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 )
 
+// AgentCardPath is the well-known path agents publish their AgentCard at
+const AgentCardPath = "/.well-known/agent.json"
+
 // AgentCard conveys key information about an agent
 type AgentCard struct {
 	// Human readable name of the agent
@@ -16,6 +19,12 @@ type AgentCard struct {
 	// URL to the address the agent is hosted at
 	URL string `json:"url"`
 
+	// Transport the URL is served with, TransportJSONRPC when empty
+	PreferredTransport string `json:"preferredTransport,omitempty"`
+
+	// Other addresses the agent is served at, with their transport
+	AdditionalInterfaces []AgentInterface `json:"additionalInterfaces,omitempty"`
+
 	// The service provider of the agent
 	Provider *AgentProvider `json:"provider,omitempty"`
 
@@ -42,6 +51,66 @@ type AgentCard struct {
 
 	// Skills are a unit of capability that an agent can perform
 	Skills []AgentSkill `json:"skills"`
+
+	// Extension metadata, Agents list the methods they serve on top of the A2A
+	// specification under CardExtensionMethodsKey
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// CardExtensionMethodsKey is the AgentCard metadata entry listing the extension
+// methods an agent serves
+const CardExtensionMethodsKey = "extensionMethods"
+
+// withExtensionMethods returns the card advertising the extension methods, the
+// metadata of the card it was given is left untouched
+func withExtensionMethods(card AgentCard) AgentCard {
+	md := make(map[string]any, len(card.Metadata)+1)
+	for k, v := range card.Metadata {
+		md[k] = v
+	}
+
+	methods := make([]string, 0, len(ExtensionMethods))
+	for _, m := range ExtensionMethods {
+		methods = append(methods, string(m))
+	}
+	md[CardExtensionMethodsKey] = methods
+
+	card.Metadata = md
+	return card
+}
+
+// SupportsMethod reports whether the agent serves the extension method according
+// to its card, the methods of the A2A specification are always served
+func (ac AgentCard) SupportsMethod(method Method) bool {
+	if _, ok := MethodToParamsType[method]; ok && !isExtensionMethod(method) {
+		return true
+	}
+
+	switch methods := ac.Metadata[CardExtensionMethodsKey].(type) {
+	case []string:
+		for _, m := range methods {
+			if Method(m) == method {
+				return true
+			}
+		}
+	case []any:
+		for _, m := range methods {
+			if s, ok := m.(string); ok && Method(s) == method {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func isExtensionMethod(method Method) bool {
+	for _, m := range ExtensionMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface for AgentCard
@@ -132,6 +201,18 @@ type AgentProvider struct {
 	URL          string `json:"url"`
 }
 
+// Transports an agent can be served with
+const (
+	TransportJSONRPC = "JSONRPC" // JSON-RPC over HTTP, with SSE streams
+	TransportGRPC    = "GRPC"    // the A2AService of the a2apb package
+)
+
+// AgentInterface is an address the agent is served at and its transport
+type AgentInterface struct {
+	URL       string `json:"url"`
+	Transport string `json:"transport"`
+}
+
 // AgentCapabilities describes optional capabilities supported by the agent
 type AgentCapabilities struct {
 	// True if the agent supports SSE
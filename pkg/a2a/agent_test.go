@@ -0,0 +1,133 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	go_sse "github.com/tmaxmax/go-sse"
+	"go-micro.dev/v5/logger"
+)
+
+// serveAgent serves the Agent on a test server until the test ends, it returns the
+// server and the routes of the Agent
+func serveAgent(t *testing.T, a *Agent) (*httptest.Server, agentPaths) {
+	t.Helper()
+
+	router := newRouter(logger.NewLogger(logger.WithLevel(logger.ErrorLevel)))
+	paths := a.mount(router, AgentCardPath)
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = a.Shutdown(ctx)
+		srv.Close()
+	})
+
+	return srv, paths
+}
+
+// textMessage is a user message with a single text part
+func textMessage(id, text string) Message {
+	return Message{
+		Kind:      MessageKind,
+		MessageId: id,
+		Role:      MessageRoleUser,
+		Parts:     []Part{TextPart{Kind: PartTypeText, Text: text}},
+	}
+}
+
+// completeHandler completes every task it is sent
+type completeHandler struct{}
+
+func (completeHandler) TaskHandler(req JSONRPCRequest) JSONRPCResponse {
+	task, _ := TaskFromContext(req.Context())
+	return JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: Task{ID: task.ID, Status: TaskStatus{State: TaskStateCompleted}}}
+}
+
+// streamHandler reports the task working and completes it once it received a
+// follow-up message, or right away when the request asks for no input
+type streamHandler struct{}
+
+func (streamHandler) StreamHandler(req JSONRPCRequest, out chan JSONRPCResponse) {
+	defer close(out)
+
+	params := req.Params.(TaskSendParams)
+	out <- JSONRPCResponse{Result: &TaskStatusUpdateEvent{ID: params.ID, Status: TaskStatus{State: TaskStateWorking}}}
+
+	answer := "done"
+	if params.Metadata["ask"] == true {
+		reply, err := RequestInput(req.Context(), Message{Parts: []Part{TextPart{Kind: PartTypeText, Text: "your name?"}}})
+		if err != nil {
+			return
+		}
+		if t, ok := reply.Parts[0].(TextPart); ok {
+			answer = "hello " + t.Text
+		}
+	}
+
+	out <- JSONRPCResponse{Result: &TaskArtifactUpdateEvent{ID: params.ID, Artifact: Artifact{ArtifactID: "a1", Parts: []Part{TextPart{Kind: PartTypeText, Text: answer}}}}}
+	out <- JSONRPCResponse{Result: &TaskStatusUpdateEvent{ID: params.ID, Status: TaskStatus{State: TaskStateCompleted}, Final: true}}
+}
+
+// streamed is what a test reads off a stream event
+type streamed struct {
+	state TaskState
+	text  string
+}
+
+// nextEvent waits for the next stream event matching want
+func nextEvent(t *testing.T, events chan go_sse.Event, want func(streamed) bool) streamed {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatal("the stream ended early")
+			}
+
+			var res struct {
+				Result struct {
+					Status   *TaskStatus `json:"status"`
+					Artifact *struct {
+						Parts []struct {
+							Text string `json:"text"`
+						} `json:"parts"`
+					} `json:"artifact"`
+				} `json:"result"`
+			}
+			if err := json.Unmarshal([]byte(e.Data), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			var s streamed
+			if res.Result.Status != nil {
+				s.state = res.Result.Status.State
+			}
+			if res.Result.Artifact != nil && len(res.Result.Artifact.Parts) > 0 {
+				s.text = res.Result.Artifact.Parts[0].Text
+			}
+			if want(s) {
+				return s
+			}
+
+		case <-timeout:
+			t.Fatal("no matching event")
+		}
+	}
+}
+
+func inState(state TaskState) func(streamed) bool {
+	return func(s streamed) bool { return s.state == state }
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
@@ -0,0 +1,213 @@
+package a2a
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go-micro.dev/v5/broker"
+	"go-micro.dev/v5/logger"
+)
+
+// TopicScope decides the broker topics an Agent publishes the task events to
+type TopicScope int
+
+const (
+	// TopicPerAgent publishes the events of every task to AgentTopic
+	TopicPerAgent TopicScope = iota
+	// TopicPerTask publishes the events of every task to its own TaskTopic
+	TopicPerTask
+)
+
+// AgentTopic is the broker topic the Agent with the AgentCard name publishes the
+// events of its tasks to with TopicPerAgent
+func AgentTopic(agentName string) string {
+	return "a2a." + serviceName(agentName) + ".events"
+}
+
+// TaskTopic is the broker topic the Agent with the AgentCard name publishes the
+// events of the task to with TopicPerTask
+func TaskTopic(agentName, taskID string) string {
+	return "a2a." + serviceName(agentName) + ".tasks." + taskID
+}
+
+// EnvelopeVersion is the version of the EventEnvelope published by this package, it
+// changes only when the envelope changes in a way existing subscribers can't read
+const EnvelopeVersion = 1
+
+// Kinds of the events carried by an EventEnvelope
+const (
+	StatusUpdateKind   = "status-update"   // the event is a TaskStatusUpdateEvent
+	ArtifactUpdateKind = "artifact-update" // the event is a TaskArtifactUpdateEvent
+)
+
+// Headers of the broker messages, they repeat the envelope fields subscribers may
+// want to route on without decoding the body
+const (
+	HeaderEventKind = "A2A-Event-Kind"
+	HeaderTaskID    = "A2A-Task-Id"
+	HeaderAgent     = "A2A-Agent"
+)
+
+// EventEnvelope is the JSON body of the broker messages an Agent publishes. Seq is
+// the position of the event in the log of the task, see Agent.TaskEvents, Index the
+// one of the update among the updates the event carries.
+type EventEnvelope struct {
+	Version   int             `json:"version"`
+	ID        string          `json:"id"`
+	Agent     string          `json:"agent"`
+	Kind      string          `json:"kind"`
+	TaskID    string          `json:"taskId"`
+	ContextID string          `json:"contextId,omitempty"`
+	Seq       int64           `json:"seq"`
+	Index     int             `json:"index,omitempty"`
+	Timestamp string          `json:"timestamp"`
+	Event     json.RawMessage `json:"event"`
+}
+
+// topic is where the Agent publishes the events of the task
+func (a *Agent) topic(taskID string) string {
+	if a.options.BrokerTopics == TopicPerTask {
+		return TaskTopic(a.options.AgentCard.Name, taskID)
+	}
+	return AgentTopic(a.options.AgentCard.Name)
+}
+
+// publishEvents publishes the status and artifact events of the log to the broker,
+// the status goes after the artifacts saved along with it so subscribers stopping
+// at the final status get them all. A failure is logged and doesn't fail the task.
+func (a *Agent) publishEvents(t *Task, events []TaskEvent) {
+	if a.options.Broker == nil {
+		return
+	}
+
+	type update struct {
+		event  TaskEvent
+		index  int
+		result Result
+	}
+
+	var artifacts, statuses []update
+	for _, e := range events {
+		for i, result := range eventUpdates(t.ID, e) {
+			if _, ok := result.(TaskStatusUpdateEvent); ok {
+				statuses = append(statuses, update{e, i, result})
+			} else {
+				artifacts = append(artifacts, update{e, i, result})
+			}
+		}
+	}
+
+	for _, u := range append(artifacts, statuses...) {
+		if err := a.publish(t, u.event, u.index, u.result); err != nil {
+			a.options.Logger.Log(logger.ErrorLevel, "event "+fmt.Sprint(u.event.Seq)+" of task "+t.ID+" not published: "+err.Error())
+		}
+	}
+}
+
+// eventUpdates returns the status and artifact updates a client streaming the task
+// gets for the logged event
+func eventUpdates(taskID string, e TaskEvent) []Result {
+	switch e.Kind {
+	case EventStatus:
+		return []Result{TaskStatusUpdateEvent{ID: taskID, Status: *e.Status, Final: isTerminal(e.Status.State)}}
+	case EventArtifact:
+		return []Result{TaskArtifactUpdateEvent{ID: taskID, Artifact: *e.Artifact}}
+	case EventArtifacts:
+		var updates []Result
+		for _, artifact := range e.Artifacts {
+			updates = append(updates, TaskArtifactUpdateEvent{ID: taskID, Artifact: artifact})
+		}
+		return updates
+	}
+	return nil
+}
+
+func (a *Agent) publish(t *Task, e TaskEvent, index int, update Result) error {
+	kind := StatusUpdateKind
+	if _, ok := update.(TaskArtifactUpdateEvent); ok {
+		kind = ArtifactUpdateKind
+	}
+
+	raw, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(EventEnvelope{
+		Version:   EnvelopeVersion,
+		ID:        uuid.NewString(),
+		Agent:     a.name(),
+		Kind:      kind,
+		TaskID:    t.ID,
+		ContextID: t.ContextID,
+		Seq:       e.Seq,
+		Index:     index,
+		Timestamp: e.Timestamp,
+		Event:     raw,
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.options.Broker.Publish(a.topic(t.ID), &broker.Message{
+		Header: map[string]string{
+			"Content-Type":  "application/json",
+			HeaderEventKind: kind,
+			HeaderTaskID:    t.ID,
+			HeaderAgent:     a.name(),
+		},
+		Body: body,
+	})
+}
+
+// DecodeEvent reads a message published by an Agent, the event is either a
+// TaskStatusUpdateEvent or a TaskArtifactUpdateEvent depending on the envelope kind
+func DecodeEvent(msg *broker.Message) (EventEnvelope, Result, error) {
+	var env EventEnvelope
+	if msg == nil {
+		return env, nil, fmt.Errorf("empty broker message")
+	}
+
+	if err := json.Unmarshal(msg.Body, &env); err != nil {
+		return env, nil, err
+	}
+
+	if env.Version > EnvelopeVersion {
+		return env, nil, fmt.Errorf("unsupported event envelope version %d", env.Version)
+	}
+
+	switch env.Kind {
+	case StatusUpdateKind:
+		var event TaskStatusUpdateEvent
+		if err := json.Unmarshal(env.Event, &event); err != nil {
+			return env, nil, err
+		}
+		return env, event, nil
+
+	case ArtifactUpdateKind:
+		var event TaskArtifactUpdateEvent
+		if err := json.Unmarshal(env.Event, &event); err != nil {
+			return env, nil, err
+		}
+		return env, event, nil
+	}
+
+	return env, nil, fmt.Errorf("unknown event kind: %s", env.Kind)
+}
+
+// EventHandler receives the events decoded by SubscribeEvents
+type EventHandler func(env EventEnvelope, event Result) error
+
+// SubscribeEvents subscribes to the task events published on the topic, see
+// AgentTopic and TaskTopic, and hands them to the handler decoded. Messages that
+// can't be decoded are returned as errors to the broker.
+func SubscribeEvents(b broker.Broker, topic string, handler EventHandler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	return b.Subscribe(topic, func(e broker.Event) error {
+		env, event, err := DecodeEvent(e.Message())
+		if err != nil {
+			return err
+		}
+		return handler(env, event)
+	}, opts...)
+}
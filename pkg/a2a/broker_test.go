@@ -0,0 +1,84 @@
+package a2a
+
+import (
+	"testing"
+
+	"go-micro.dev/v5/broker"
+)
+
+func TestBrokerEventsRoundTrip(t *testing.T) {
+	b := broker.NewMemoryBroker()
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	a := NewAgent(AgentCard{Name: "Publisher"}, WithBroker(b), WithBrokerTopics(TopicPerTask))
+
+	type received struct {
+		env   EventEnvelope
+		event Result
+	}
+	var events []received
+	sub, err := SubscribeEvents(b, TaskTopic("Publisher", "t1"), func(env EventEnvelope, event Result) error {
+		events = append(events, received{env, event})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	task := &Task{ID: "t1", ContextID: "c1", Status: TaskStatus{State: TaskStateWorking}}
+	if err := a.createTask(task, "caller"); err != nil {
+		t.Fatal(err)
+	}
+
+	task.Artifacts = []Artifact{{ArtifactID: "a1", Parts: []Part{TextPart{Kind: PartTypeText, Text: "result"}}}}
+	task.Status = TaskStatus{State: TaskStateCompleted}
+	if err := a.saveTask(task); err != nil {
+		t.Fatal(err)
+	}
+
+	// the working status, then the artifact ahead of the status saved along with it
+	if len(events) != 3 {
+		t.Fatalf("received %d events, want 3: %+v", len(events), events)
+	}
+
+	// the seqs are those of the log, the artifact goes ahead of the status logged
+	// before it
+	seqs := map[int64]bool{}
+	for _, e := range events {
+		env := e.env
+		if env.Version != EnvelopeVersion || env.ID == "" || env.Agent != a.name() || env.TaskID != "t1" || env.ContextID != "c1" || env.Timestamp == "" {
+			t.Fatalf("unexpected envelope %+v", env)
+		}
+		if env.Seq <= 0 || seqs[env.Seq] {
+			t.Fatalf("unexpected seq %d", env.Seq)
+		}
+		seqs[env.Seq] = true
+	}
+
+	if s, ok := events[0].event.(TaskStatusUpdateEvent); !ok || events[0].env.Kind != StatusUpdateKind || s.Status.State != TaskStateWorking || s.Final {
+		t.Fatalf("first event = %s %+v, want the working status", events[0].env.Kind, events[0].event)
+	}
+
+	artifact, ok := events[1].event.(TaskArtifactUpdateEvent)
+	if !ok || events[1].env.Kind != ArtifactUpdateKind || artifact.ID != "t1" || artifact.Artifact.ArtifactID != "a1" {
+		t.Fatalf("second event = %s %+v, want the artifact", events[1].env.Kind, events[1].event)
+	}
+	if text, ok := artifact.Artifact.Parts[0].(TextPart); !ok || text.Text != "result" {
+		t.Fatalf("artifact parts = %+v, want the result text", artifact.Artifact.Parts)
+	}
+
+	if s, ok := events[2].event.(TaskStatusUpdateEvent); !ok || s.Status.State != TaskStateCompleted || !s.Final {
+		t.Fatalf("third event = %+v, want the final completed status", events[2].event)
+	}
+}
+
+func TestDecodeEventRejectsNewerEnvelopes(t *testing.T) {
+	msg := &broker.Message{Body: []byte(`{"version":99,"kind":"status-update","event":{}}`)}
+	if _, _, err := DecodeEvent(msg); err == nil {
+		t.Fatal("an envelope of a newer version was decoded")
+	}
+}
@@ -0,0 +1,107 @@
+package a2a
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/logger"
+	"go-micro.dev/v5/store"
+)
+
+// cancelTask handles tasks/cancel, the handler of the task is stopped on whichever
+// replica runs it and the task is left canceled. Tasks in a final state can't be
+// canceled.
+func (a *Agent) cancelTask(c *gin.Context, r JSONRPCRequest) {
+	params, ok := (r.Params).(TaskIDParams)
+	if !ok {
+		e := NewError(ErrorInvalidRequest, "request should include a TaskIDParams as params", nil)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+	annotate(c, AttributeTaskID.String(params.ID))
+
+	task, meta, err := a.loadTaskRecord(params.ID)
+	if err == store.ErrNotFound {
+		e := NewError(ErrorTaskNotFound, "task not found", map[string]any{"id": params.ID})
+		c.JSON(http.StatusNotFound, e)
+		return
+	}
+	if err != nil {
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if isTerminal(task.Status.State) {
+		e := NewError(ErrorTaskCantCancel, "the task can't be canceled in its current state", map[string]any{
+			"id":    task.ID,
+			"state": task.Status.State,
+		})
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	if run, ok := a.liveRun(task.ID); ok {
+		a.cancelRun(run)
+	} else if _, ok := a.remoteOwner(task.ID); ok {
+		if !a.cancelRemote(c.Request.Context(), task.ID, meta.seq) {
+			e := NewError(ErrorTimeout, "the replica running the task didn't cancel it in time", map[string]any{"id": task.ID})
+			a.respond(c, JSONRPCResponse{JSONRPC: "2.0", ID: r.ID, Error: &e})
+			return
+		}
+	} else {
+		// nothing runs the task, e.g. it waits for its stream or for a follow-up
+		task.Status = *canceledStatus(task)
+		if err := a.saveTask(task); err != nil {
+			e := NewError(ErrorInternal, err.Error(), nil)
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+	}
+
+	task, err = a.loadTask(params.ID)
+	if err != nil {
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, JSONRPCResponse{JSONRPC: "2.0", ID: r.ID, Result: task})
+}
+
+// cancelRun stops the run and leaves its task canceled, the attached streams get
+// the canceled status as their final event
+func (a *Agent) cancelRun(run *taskRun) {
+	a.interrupt(run, canceledStatus(nil), nil)
+}
+
+// cancelRemote asks the replica running the task to cancel it and waits for the
+// task to reach a final state after the sequence number, for at most a lease
+func (a *Agent) cancelRemote(ctx context.Context, taskID string, seq int64) bool {
+	ctx, cancel := context.WithTimeout(ctx, a.replicaLease())
+	defer cancel()
+
+	w, err := a.watchTask(ctx, taskID)
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		return false
+	}
+
+	if err := a.forward(controlMessage{Action: controlCancel, TaskID: taskID}); err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		return false
+	}
+
+	return a.awaitStatus(ctx, taskID, w, seq, isTerminal)
+}
+
+// canceledStatus is the status of a canceled task, t sets the IDs of its message
+func canceledStatus(t *Task) *TaskStatus {
+	status := agentStatus(TaskStateCanceled, "the task was canceled")
+	if t != nil {
+		status.Message.TaskId = t.ID
+		status.Message.ContextId = t.ContextID
+	}
+	return status
+}
@@ -6,15 +6,40 @@ import (
 	// https://github.com/tmaxmax/go-sse
 
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	go_sse "github.com/tmaxmax/go-sse"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 	"resty.dev/v3"
 )
 
+// Client sends A2A requests to agents, A2AClient speaks JSON-RPC over HTTP and
+// GRPCClient the gRPC binding
+type Client interface {
+	// FetchAgentCard fetches the AgentCard of the agent at addr
+	FetchAgentCard(ctx context.Context, addr string) (AgentCard, error)
+	// SendReq sends a request to the agent at addr and returns its response
+	SendReq(ctx context.Context, method Method, params Params, addr string) (JSONRPCResponse, error)
+	// SendReqStream sends a streaming request to the agent at addr and returns the
+	// events of the task, the channel is closed after the final event
+	SendReqStream(ctx context.Context, method Method, params Params, addr string) (chan go_sse.Event, error)
+}
+
+var _ Client = (*A2AClient)(nil)
+
 // A2AClient is a client for interacting with A2A-compatible agents.
 // It provides methods for sending requests and establishing streaming connections.
 type A2AClient struct {
@@ -22,19 +47,124 @@ type A2AClient struct {
 	Client resty.Client
 
 	// EventSource is used for Server-Sent Events (SSE) connections
+	//
+	// Deprecated: SendReqStream reads the streams with go-sse, which resumes them
+	// once the connection drops. EventSource is no longer used.
 	EventSource resty.EventSource
+
+	options ClientOptions
+	metrics *clientMetrics
+}
+
+// ClientOptions configures an A2AClient
+type ClientOptions struct {
+	// TracerProvider provides the tracer of the client spans, it defaults to the
+	// global provider
+	TracerProvider trace.TracerProvider
+	// Propagator writes the trace context to the request headers, it defaults to
+	// the W3C trace context and baggage
+	Propagator propagation.TextMapPropagator
+	// Metrics is the Prometheus registry the client metrics are registered with,
+	// nil disables them
+	Metrics prometheus.Registerer
+	// StreamReconnects is how many times in a row a dropped stream is resumed
+	// without receiving an event, it defaults to DefaultStreamReconnects and a
+	// negative value disables the reconnections
+	StreamReconnects int
+	// RateLimitRetries is how many times SendReq sends a request again once the
+	// agent rejected it with ErrorRateLimitExceeded, after the Retry-After delay.
+	// It defaults to DefaultRateLimitRetries and a negative value disables them.
+	RateLimitRetries int
+	// GRPCDialOptions are the options GRPCClient dials agents with, the connections
+	// are insecure when they don't set transport credentials
+	GRPCDialOptions []grpc.DialOption
+}
+
+// ClientOption is a function that configures ClientOptions
+type ClientOption func(co *ClientOptions)
+
+// WithClientTracerProvider sets the OpenTelemetry provider of the client spans
+func WithClientTracerProvider(tp trace.TracerProvider) ClientOption {
+	return func(co *ClientOptions) {
+		co.TracerProvider = tp
+	}
+}
+
+// WithClientPropagator sets how the trace context is written to the request headers
+func WithClientPropagator(p propagation.TextMapPropagator) ClientOption {
+	return func(co *ClientOptions) {
+		co.Propagator = p
+	}
+}
+
+// WithStreamReconnects sets how many times in a row a dropped stream is resumed
+// without receiving an event, a negative value disables the reconnections
+func WithStreamReconnects(n int) ClientOption {
+	return func(co *ClientOptions) {
+		co.StreamReconnects = n
+	}
+}
+
+// WithRateLimitRetries sets how many times SendReq sends a request again once the
+// agent rejected it with ErrorRateLimitExceeded, a negative value disables them
+func WithRateLimitRetries(n int) ClientOption {
+	return func(co *ClientOptions) {
+		co.RateLimitRetries = n
+	}
+}
+
+// WithGRPCDialOptions sets the options GRPCClient dials agents with, e.g. their
+// transport credentials
+func WithGRPCDialOptions(opts ...grpc.DialOption) ClientOption {
+	return func(co *ClientOptions) {
+		co.GRPCDialOptions = append(co.GRPCDialOptions, opts...)
+	}
+}
+
+// WithClientMetrics registers the client metrics with the Prometheus registry,
+// clients sharing a registry share their metrics
+func WithClientMetrics(reg prometheus.Registerer) ClientOption {
+	return func(co *ClientOptions) {
+		co.Metrics = reg
+	}
 }
 
 // NewA2AClient creates a new A2A client with default configuration.
 // It initializes both the HTTP client and the EventSource for SSE connections.
 //
+// Parameters:
+//   - opts: Optional ClientOption values, e.g. WithClientTracerProvider
+//
 // Returns:
 //   - A pointer to a new A2AClient instance ready for use
-func NewA2AClient() *A2AClient {
-	return &A2AClient{
+func NewA2AClient(opts ...ClientOption) *A2AClient {
+	c := &A2AClient{
 		Client:      *resty.New(),
 		EventSource: *resty.NewEventSource(),
 	}
+
+	for _, o := range opts {
+		o(&c.options)
+	}
+
+	if c.options.Metrics != nil {
+		c.metrics = newClientMetrics(c.options.Metrics)
+	}
+
+	return c
+}
+
+// headers returns the headers carrying the trace context and the deadline of ctx
+// to the agent, see DeadlineHeader
+func (o ClientOptions) headers(ctx context.Context) map[string]string {
+	headers := make(map[string]string)
+	o.injectTrace(ctx, headers)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		headers[DeadlineHeader] = deadline.UTC().Format(time.RFC3339Nano)
+	}
+
+	return headers
 }
 
 // validateMethodParams checks if the combination of method and params is valid
@@ -74,6 +204,39 @@ func validateMethodParams(method Method, params Params) error {
 	return nil
 }
 
+// FetchAgentCard retrieves the AgentCard an agent publishes at the well-known path.
+// The card can then be used to check locally that a message fits the agent before
+// sending it, see AgentCard.CheckMessage.
+//
+// Parameters:
+//   - ctx: Context for the request, which can be used for cancellation
+//   - baseURL: The base URL of the agent, e.g. http://localhost:8081
+//
+// Returns:
+//   - AgentCard: The card published by the agent
+//   - error: An error if the request failed, or nil if successful
+func (c *A2AClient) FetchAgentCard(ctx context.Context, baseURL string) (AgentCard, error) {
+	card := AgentCard{}
+
+	addr, err := url.JoinPath(baseURL, AgentCardPath)
+	if err != nil {
+		return card, NewError(ErrorInvalidRequest, err.Error(), nil)
+	}
+
+	res, err := c.Client.R().SetContext(ctx).SetResult(&card).Get(addr)
+	if err != nil {
+		return card, NewError(ErrorInternal, fmt.Sprintf("failed to fetch agent card: %v", err), nil)
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return card, NewError(ErrorInternal, fmt.Sprintf("server returned: %v", res.StatusCode()), nil)
+	}
+
+	return card, nil
+}
+
 // SendReq sends a JSON-RPC request to an A2A-compatible agent and returns the response.
 //
 // Parameters:
@@ -89,8 +252,13 @@ func validateMethodParams(method Method, params Params) error {
 // The method performs the following steps:
 //  1. Validates that the method and params combination is valid
 //  2. Creates a JSON-RPC request with a new UUID
-//  3. Sends the request to the specified URL
-//  4. Returns the response or an error
+//  3. Sends the request to the specified URL, carrying the trace context and the
+//     deadline of ctx
+//  4. Sends it again once the Retry-After delay is over when the agent rate limited
+//     it, see WithRateLimitRetries
+//  5. Returns the response or an error
+//
+// The request is traced as a client span named after the method.
 func (c *A2AClient) SendReq(ctx context.Context, method Method, params Params, url string) (JSONRPCResponse, error) {
 	// Validate method and params combination
 	if err := validateMethodParams(method, params); err != nil {
@@ -98,6 +266,7 @@ func (c *A2AClient) SendReq(ctx context.Context, method Method, params Params, u
 	}
 
 	rpcRes := JSONRPCResponse{}
+	rpcErr := JSONRPCError{}
 
 	newID := uuid.NewString()
 
@@ -108,13 +277,51 @@ func (c *A2AClient) SendReq(ctx context.Context, method Method, params Params, u
 		Params:  params,
 	}
 
-	res, err := c.Client.R().SetResult(&rpcRes).SetBody(req).Post(url)
-	if err != nil {
-		return JSONRPCResponse{}, NewError(ErrorInternal, fmt.Sprintf("failed to send request: %v", err), nil)
+	ctx, span := c.options.startSpan(ctx, req)
+	defer span.End()
+
+	headers := c.options.headers(ctx)
+
+	start := time.Now()
+	code := codeOK
+	defer func() { c.metrics.observeRequest(method, code, start) }()
+
+	// a request rejected by a rate limit is sent again once the agent says so
+	var res *resty.Response
+	for retries := c.options.rateLimitRetries(); ; retries-- {
+		var err error
+		res, err = c.Client.R().SetContext(ctx).SetHeaders(headers).SetResult(&rpcRes).SetError(&rpcErr).SetBody(req).Post(url)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			code = "error"
+			return JSONRPCResponse{}, NewError(ErrorInternal, fmt.Sprintf("failed to send request: %v", err), nil)
+		}
+
+		wait, limited := retryAfter(res, rpcErr)
+		if !limited || retries <= 0 || !sleepCtx(ctx, wait) {
+			break
+		}
+		res.Body.Close()
+
+		span.AddEvent("a2a.retry", trace.WithAttributes(semconv.HTTPRequestResendCount(c.options.rateLimitRetries()-retries+1)))
+		rpcRes, rpcErr = JSONRPCResponse{}, JSONRPCError{}
 	}
 
 	defer res.Body.Close()
 
+	switch {
+	case rpcRes.Error != nil:
+		recordError(span, *rpcRes.Error)
+		code = errorCode(*rpcRes.Error)
+	case res.IsError() && rpcErr.Code != 0:
+		recordError(span, rpcErr)
+		code = errorCode(rpcErr)
+	case res.IsError():
+		span.SetStatus(codes.Error, res.Status())
+		code = strconv.Itoa(res.StatusCode())
+	}
+
 	return rpcRes, nil
 }
 
@@ -135,11 +342,12 @@ func (c *A2AClient) SendReq(ctx context.Context, method Method, params Params, u
 //  1. Validates that the method and params combination is valid
 //  2. Creates a JSON-RPC request with a new UUID
 //  3. Sends the initial request to establish the streaming connection
-//  4. Sets up an SSE connection to receive streaming updates
-//  5. Returns a channel for receiving events
+//  4. Sets up an SSE connection to receive streaming updates, resumed from the
+//     last event received when it drops, see WithStreamReconnects
+//  5. Returns a channel for receiving events, closed after the final event
 //
-// Note: Currently only TasksSendSubscribe is fully implemented for streaming.
-// Other methods (TasksGet, TasksCancel, TasksResubscribe) are placeholders.
+// Note: Currently only TasksSendSubscribe and TasksResubscribe are implemented for
+// streaming. Other methods (TasksGet, TasksCancel) are placeholders.
 func (c *A2AClient) SendReqStream(ctx context.Context, method Method, params Params, addr string) (chan go_sse.Event, error) {
 	// Validate method and params combination
 	if err := validateMethodParams(method, params); err != nil {
@@ -156,44 +364,52 @@ func (c *A2AClient) SendReqStream(ctx context.Context, method Method, params Par
 		Params:  params,
 	}
 
+	ctx, span := c.options.startSpan(ctx, req)
+	defer span.End()
+
+	headers := c.options.headers(ctx)
+
 	switch method {
-	// Initiation
-	case TasksSendSubscribe:
+	// Initiation, resubscribing opens the stream of an existing task the same way
+	case TasksSendSubscribe, TasksResubscribe:
 		// first, sent initial request
-		res, err := c.Client.R().SetBody(req).Post(addr)
+		start := time.Now()
+		rpcErr := JSONRPCError{}
+		res, err := c.Client.R().SetContext(ctx).SetHeaders(headers).SetError(&rpcErr).SetBody(req).Post(addr)
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			c.metrics.observeRequest(method, "error", start)
 			return resChan, NewError(ErrorInternal, fmt.Sprintf("failed to send request: %v", err), nil)
 		}
 
 		defer res.Body.Close()
 
 		if res.IsError() {
+			if rpcErr.Code != 0 {
+				recordError(span, rpcErr)
+				c.metrics.observeRequest(method, errorCode(rpcErr), start)
+			} else {
+				span.SetStatus(codes.Error, res.Status())
+				c.metrics.observeRequest(method, strconv.Itoa(res.StatusCode()), start)
+			}
 			return resChan, NewError(ErrorInternal, fmt.Sprintf("server returned: %v", res.StatusCode()), nil)
 		}
 
-		// second, subscribe to events
+		c.metrics.observeRequest(method, codeOK, start)
+
+		// second, subscribe to events, the stream joins the trace as well
 		newAddr := fmt.Sprintf("%v?id=%v", addr, id)
 
-		c.EventSource.SetURL(newAddr).
-			OnOpen(func(url string) {
-				log.Printf("connection with [%v] established", url)
-			}).
-			OnError(func(err error) {
-				log.Println(err)
-			}).
-			OnMessage(func(e any) {
-				e = e.(*resty.Event)
-				fmt.Println(e)
-			}, nil)
-
-		err = c.EventSource.Get()
+		err = c.stream(ctx, newAddr, headers, resChan)
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 			return resChan, NewError(ErrorInternal, fmt.Sprintf("failed to establish a connection with [%v]: %v", newAddr, err), nil)
 		}
 
 	case TasksGet:
 	case TasksCancel:
-	case TasksResubscribe:
 	}
 
 	return resChan, nil
@@ -205,3 +421,182 @@ func (c *A2AClient) SendReqStream(ctx context.Context, method Method, params Par
 // case *TaskArtifactUpdateEvent:
 // 	st.StreamArtifact = append(st.StreamArtifact, (response.Data.Result).(TaskArtifactUpdateEvent))
 // }
+
+// DefaultRateLimitRetries is how many times SendReq sends a request again once the
+// agent rejected it with ErrorRateLimitExceeded when the WithRateLimitRetries option
+// is not provided
+const DefaultRateLimitRetries = 2
+
+// maxRetryAfter is the longest SendReq waits before sending a request again, the
+// requests rejected for longer, e.g. by a daily quota, are replied as is
+const maxRetryAfter = time.Minute
+
+func (o ClientOptions) rateLimitRetries() int {
+	if o.RateLimitRetries == 0 {
+		return DefaultRateLimitRetries
+	}
+	return o.RateLimitRetries
+}
+
+// retryAfter returns how long the agent asked the client to wait before sending
+// a request it rejected with ErrorRateLimitExceeded again, from the Retry-After
+// header or else the retryAfter of the error data. It returns false when the
+// request wasn't rate limited or the wait is longer than maxRetryAfter.
+func retryAfter(res *resty.Response, e JSONRPCError) (time.Duration, bool) {
+	if res.StatusCode() != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	var wait time.Duration
+	if h := res.Header().Get("Retry-After"); h != "" {
+		if seconds, err := strconv.Atoi(h); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		} else if at, err := http.ParseTime(h); err == nil {
+			wait = time.Until(at)
+		}
+	} else if seconds, ok := e.Data["retryAfter"].(float64); ok {
+		wait = time.Duration(seconds * float64(time.Second))
+	}
+
+	return max(wait, 0), wait <= maxRetryAfter
+}
+
+// sleepCtx waits for d, it returns false when ctx is done first or its deadline
+// comes before the wait is over
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// DefaultStreamReconnects is how many times in a row a stream is reconnected without
+// receiving an event when the WithStreamReconnects option is not provided
+const DefaultStreamReconnects = 5
+
+// stream reads the events of the stream at addr into events until the task reached
+// its final event or ctx is done, it returns once the stream is open. A dropped
+// connection is resumed after the last event received, sent as the Last-Event-ID,
+// and the events received already are skipped.
+func (c *A2AClient) stream(ctx context.Context, addr string, headers map[string]string, events chan go_sse.Event) error {
+	ctx, stop := context.WithCancel(ctx)
+
+	get, err := http.NewRequestWithContext(ctx, http.MethodGet, addr, nil)
+	if err != nil {
+		stop()
+		return err
+	}
+	for k, v := range headers {
+		get.Header.Set(k, v)
+	}
+
+	reconnects := c.options.StreamReconnects
+	if reconnects == 0 {
+		reconnects = DefaultStreamReconnects
+	}
+
+	// opened gets the outcome of the first connection
+	opened := make(chan error, 1)
+	var idle int
+
+	client := &go_sse.Client{
+		ResponseValidator: func(res *http.Response) error {
+			err := go_sse.DefaultValidator(res)
+			select {
+			case opened <- err:
+			default:
+			}
+			return err
+		},
+		OnRetry: func(err error, _ time.Duration) {
+			idle++
+			if reconnects < 0 || idle > reconnects {
+				stop()
+			}
+		},
+	}
+
+	conn := client.NewConnection(get)
+
+	var last go_sse.Event
+	conn.SubscribeToAll(func(e go_sse.Event) {
+		idle = 0
+		if duplicateEvent(last, e) {
+			return
+		}
+		last = e
+
+		select {
+		case events <- e:
+		case <-ctx.Done():
+			return
+		}
+
+		// the connection would be resumed otherwise
+		if finalEvent(e.Data) {
+			stop()
+		}
+	})
+
+	go func() {
+		defer close(events)
+		defer stop()
+
+		err := conn.Connect()
+		if ctx.Err() == nil {
+			log.Println(err)
+		}
+		select {
+		case opened <- err:
+		default:
+		}
+	}()
+
+	return <-opened
+}
+
+// duplicateEvent reports whether the event was received before the stream was
+// resumed, the agent numbers the updates of a task in order, see eventPos
+func duplicateEvent(last, e go_sse.Event) bool {
+	prev, ok := parseEventID(last.LastEventID)
+	if !ok {
+		return false
+	}
+	next, ok := parseEventID(e.LastEventID)
+	if !ok {
+		return false
+	}
+
+	return next.before(prev) || (next == prev && e.Data == last.Data)
+}
+
+// finalEvent reports whether the event data ends the stream, that is an error or
+// the last status of the task
+func finalEvent(data string) bool {
+	var res struct {
+		Result struct {
+			Final  bool `json:"final"`
+			Status *struct {
+				State TaskState `json:"state"`
+			} `json:"status"`
+		} `json:"result"`
+		Error *JSONRPCError `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(data), &res); err != nil {
+		return false
+	}
+
+	if res.Error != nil || res.Result.Final {
+		return true
+	}
+	return res.Result.Status != nil && isTerminal(res.Result.Status.State)
+}
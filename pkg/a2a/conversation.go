@@ -0,0 +1,162 @@
+package a2a
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"go-micro.dev/v5/store"
+)
+
+type taskContextKey struct{}
+
+// TaskFromContext returns the task a handler is processing, including the full
+// message history of the task and the message that triggered the current call
+func TaskFromContext(ctx context.Context) (*Task, bool) {
+	t, ok := ctx.Value(taskContextKey{}).(*Task)
+	return t, ok
+}
+
+type conversationContextKey struct{}
+
+// ConversationFromContext returns the conversation the task being processed
+// belongs to
+func ConversationFromContext(ctx context.Context) (*Conversation, bool) {
+	c, ok := ctx.Value(conversationContextKey{}).(*Conversation)
+	return c, ok
+}
+
+// Conversation groups the tasks sharing a ContextID
+type Conversation struct {
+	ContextID string
+
+	agent *Agent
+}
+
+// Tasks returns the tasks of the conversation in the order they were created
+func (c *Conversation) Tasks() ([]*Task, error) {
+	keys, err := c.agent.options.Store.List(store.ListPrefix(contextIndexPrefix(c.ContextID)))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+
+	tasks := make([]*Task, 0, len(keys))
+	for _, k := range keys {
+		taskID := k[strings.LastIndex(k, "/")+1:]
+
+		t, err := c.agent.loadTask(taskID)
+		if err == store.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, nil
+}
+
+// History returns the messages of every task of the conversation, oldest first
+func (c *Conversation) History() ([]Message, error) {
+	tasks, err := c.Tasks()
+	if err != nil {
+		return nil, err
+	}
+
+	var history []Message
+	for _, t := range tasks {
+		history = append(history, t.History...)
+	}
+
+	return history, nil
+}
+
+// withTask returns a request carrying the task and its conversation in its context
+func (a *Agent) withTask(r JSONRPCRequest, t *Task) JSONRPCRequest {
+	ctx := context.WithValue(r.Context(), taskContextKey{}, t)
+	ctx = context.WithValue(ctx, conversationContextKey{}, &Conversation{ContextID: t.ContextID, agent: a})
+	return r.WithContext(ctx)
+}
+
+// prepareTask resolves the task a TaskSendParams refers to. A send for a known task
+// ID continues that task when it is paused waiting for input or auth: the message
+// is appended to its history and the task goes back to submitted. A task being
+// worked on or in a terminal state can't be continued, a new task in the same
+// context can. Otherwise a new task is created in the requested context, or
+// in a new one, on behalf of the caller, its ID can't contain a slash. The task
+// remembers the skill it was routed to and the push notification config of the
+// params. The returned params always carry the task ID.
+func (a *Agent) prepareTask(params TaskSendParams, skillID, caller string) (*Task, TaskSendParams, error) {
+	if params.ID == "" {
+		params.ID = params.Message.TaskId
+	}
+
+	// the records of a task are found by the prefix of their keys, a slash in its
+	// ID would match the records of another task
+	if strings.Contains(params.ID, "/") {
+		return nil, params, NewError(ErrorInvalidParams, "the ID of a task can't contain a slash", map[string]any{"id": params.ID})
+	}
+
+	if params.ID != "" {
+		// a paused task takes a single follow-up, the next one finds it submitted
+		a.continueMu.Lock()
+		defer a.continueMu.Unlock()
+
+		t, err := a.loadTask(params.ID)
+		if err == nil {
+			if !isPaused(t.Status.State) {
+				return nil, params, NewError(ErrorInvalidTaskState, "only a task waiting for input or auth can be continued", map[string]any{"id": t.ID, "state": t.Status.State})
+			}
+
+			params.Message.TaskId = t.ID
+			params.Message.ContextId = t.ContextID
+
+			// the question of a paused task is part of the conversation
+			if t.Status.Message != nil {
+				t.History = append(t.History, *t.Status.Message)
+			}
+			t.History = append(t.History, params.Message)
+			t.Status = TaskStatus{State: TaskStateSubmitted}
+			if skillID != "" {
+				setMetadata(t, "skillId", skillID)
+			}
+
+			if err := a.keepPushConfig(params); err != nil {
+				return nil, params, err
+			}
+
+			return t, params, a.saveTask(t)
+		}
+		if err != store.ErrNotFound {
+			return nil, params, err
+		}
+	} else {
+		params.ID = uuid.NewString()
+	}
+
+	t := newSubmittedTask(params)
+	if t.ContextID == "" {
+		t.ContextID = uuid.NewString()
+	}
+
+	params.Message.TaskId = t.ID
+	params.Message.ContextId = t.ContextID
+	t.History = []Message{params.Message}
+	if skillID != "" {
+		setMetadata(t, "skillId", skillID)
+	}
+
+	if err := a.keepPushConfig(params); err != nil {
+		return nil, params, err
+	}
+
+	if err := a.createTask(t, caller); err != nil {
+		return nil, params, err
+	}
+
+	return t, params, nil
+}
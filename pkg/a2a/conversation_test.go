@@ -0,0 +1,42 @@
+package a2a
+
+import (
+	"testing"
+)
+
+func TestPrepareTaskContinuesOnlyPausedTasks(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Test"})
+
+	msg := Message{Kind: MessageKind, MessageId: "m1", Role: MessageRoleUser, Parts: []Part{TextPart{Kind: PartTypeText, Text: "hi"}}}
+	task, params, err := a.prepareTask(TaskSendParams{Message: msg}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, state := range []TaskState{TaskStateSubmitted, TaskStateWorking, TaskStateCompleted, TaskStateFailed, TaskStateCanceled} {
+		task.Status = TaskStatus{State: state}
+		if err := a.saveTask(task); err != nil {
+			t.Fatal(err)
+		}
+
+		msg.MessageId = "m-" + string(state)
+		_, _, err := a.prepareTask(TaskSendParams{ID: params.ID, Message: msg}, "", "")
+		if e, ok := err.(JSONRPCError); !ok || e.Code != ErrorInvalidTaskState {
+			t.Fatalf("continuing a %s task: err = %v, want ErrorInvalidTaskState", state, err)
+		}
+	}
+
+	task.Status = TaskStatus{State: TaskStateInputRequired}
+	if err := a.saveTask(task); err != nil {
+		t.Fatal(err)
+	}
+
+	msg.MessageId = "m2"
+	continued, _, err := a.prepareTask(TaskSendParams{ID: params.ID, Message: msg}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if continued.Status.State != TaskStateSubmitted || len(continued.History) != 2 {
+		t.Fatalf("continued task is %s with %d messages, want submitted with 2", continued.Status.State, len(continued.History))
+	}
+}
@@ -0,0 +1,174 @@
+package a2a
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// DeadlineHeader carries the time by which the client needs the task done, as an
+// RFC 3339 timestamp. A2AClient sets it from the deadline of the request context.
+const DeadlineHeader = "A2A-Deadline"
+
+// DeadlineMetadataKey is the TaskSendParams metadata entry clients can use instead
+// of DeadlineHeader
+const DeadlineMetadataKey = "deadline"
+
+// requestDeadline returns the earliest deadline the client asked for, either in
+// the header or in the metadata, the zero time when it asked for none
+func requestDeadline(h http.Header, params TaskSendParams) time.Time {
+	var deadline time.Time
+
+	candidates := []string{h.Get(DeadlineHeader)}
+	if v, ok := params.Metadata[DeadlineMetadataKey].(string); ok {
+		candidates = append(candidates, v)
+	}
+
+	for _, v := range candidates {
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			continue
+		}
+		deadline = earliest(deadline, t)
+	}
+
+	return deadline
+}
+
+// earliest returns the earliest of two deadlines, the zero time meaning none
+func earliest(a, b time.Time) time.Time {
+	switch {
+	case a.IsZero():
+		return b
+	case b.IsZero() || a.Before(b):
+		return a
+	}
+	return b
+}
+
+// taskTimeout is the maximum execution time of the handlers of the skill
+func (a *Agent) taskTimeout(skillID string) time.Duration {
+	if d, ok := a.options.SkillTimeouts[skillID]; ok {
+		return d
+	}
+	return a.options.TaskTimeout
+}
+
+// runLimit is when the run reaches the maximum execution time of the skill counted
+// from now, the zero time when the skill has none
+func (a *Agent) runLimit(skillID string) time.Time {
+	if d := a.taskTimeout(skillID); d > 0 {
+		return time.Now().Add(d)
+	}
+	return time.Time{}
+}
+
+// startClock is called once the handler starts, the run times out at the earliest
+// of the client deadline and the maximum execution time from then on
+func (run *taskRun) startClock() {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	run.limit = run.agent.runLimit(run.skillID)
+	run.setDeadline(earliest(run.requested, run.limit))
+}
+
+// pauseClock stops counting the execution time while the handler waits for the
+// client, the deadline the client asked for keeps running
+func (run *taskRun) pauseClock() {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	if !run.limit.IsZero() {
+		run.remaining = time.Until(run.limit)
+		run.limit = time.Time{}
+	}
+	run.setDeadline(run.requested)
+}
+
+// resumeClock counts the execution time again once the client answered, the run
+// gets the execution time it had left when it paused
+func (run *taskRun) resumeClock() {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	if run.remaining != 0 {
+		run.limit = time.Now().Add(run.remaining)
+		run.remaining = 0
+	}
+	run.setDeadline(earliest(run.requested, run.limit))
+}
+
+// setDeadline moves the deadline of the run and the timer failing it, it must be
+// called with run.mu held
+func (run *taskRun) setDeadline(deadline time.Time) {
+	if run.closed {
+		return
+	}
+
+	if run.timer != nil {
+		run.timer.Stop()
+		run.timer = nil
+	}
+
+	run.deadline = deadline
+	if !deadline.IsZero() {
+		run.timer = time.AfterFunc(time.Until(deadline), func() { run.agent.expire(run) })
+	}
+}
+
+// expire fails a run that overran its deadline, the client receives ErrorTimeout
+func (a *Agent) expire(run *taskRun) {
+	run.mu.Lock()
+	deadline := run.deadline
+	run.mu.Unlock()
+
+	e := NewError(ErrorTimeout, "the task exceeded its deadline", map[string]any{
+		"id":       run.taskID,
+		"deadline": deadline.UTC().Format(time.RFC3339Nano),
+	})
+
+	a.failRun(run, e, "the task exceeded its deadline")
+}
+
+// overran reports whether the run is past its deadline
+func (run *taskRun) overran() bool {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	return !run.deadline.IsZero() && !time.Now().Before(run.deadline)
+}
+
+// deadlineContext reports the deadline of the run, which moves while the handler
+// is paused, so the calls the handler makes, including to other agents, are
+// bounded as well. The run cancels it when it expires, its error is then
+// context.DeadlineExceeded.
+type deadlineContext struct {
+	context.Context
+	run *taskRun
+}
+
+func (c deadlineContext) Deadline() (time.Time, bool) {
+	c.run.mu.Lock()
+	defer c.run.mu.Unlock()
+
+	return c.run.deadline, !c.run.deadline.IsZero()
+}
+
+func (c deadlineContext) Err() error {
+	err := c.Context.Err()
+	if err == nil {
+		return nil
+	}
+
+	c.run.mu.Lock()
+	defer c.run.mu.Unlock()
+
+	if c.run.failure != nil && c.run.failure.Code == ErrorTimeout {
+		return context.DeadlineExceeded
+	}
+	return err
+}
@@ -0,0 +1,206 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// waitHandler blocks until its context is done and reports the error of the
+// context and its deadline
+type waitHandler struct {
+	errs      chan error
+	deadlines chan time.Time
+}
+
+func newWaitHandler() waitHandler {
+	return waitHandler{errs: make(chan error, 1), deadlines: make(chan time.Time, 1)}
+}
+
+func (h waitHandler) TaskHandler(req JSONRPCRequest) JSONRPCResponse {
+	ctx := req.Context()
+
+	deadline, _ := ctx.Deadline()
+	h.deadlines <- deadline
+
+	<-ctx.Done()
+	h.errs <- ctx.Err()
+
+	return JSONRPCResponse{JSONRPC: "2.0", ID: req.ID}
+}
+
+// askHandler asks for the name of the client and completes the task with it
+type askHandler struct{}
+
+func (askHandler) TaskHandler(req JSONRPCRequest) JSONRPCResponse {
+	task, _ := TaskFromContext(req.Context())
+
+	reply, err := RequestInput(req.Context(), Message{MessageId: "ask", Role: MessageRoleAgent, Parts: []Part{TextPart{Kind: PartTypeText, Text: "your name?"}}})
+	if err != nil {
+		return JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Error: &JSONRPCError{Code: ErrorInternal, Message: err.Error()}}
+	}
+
+	answer := Message{MessageId: "answer", Role: MessageRoleAgent, Parts: reply.Parts}
+	return JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: Task{ID: task.ID, Status: TaskStatus{State: TaskStateCompleted, Message: &answer}}}
+}
+
+// postWithDeadline sends a JSON-RPC request with the deadline in the DeadlineHeader
+// and decodes the reply
+func postWithDeadline(t *testing.T, url string, deadline time.Time, method Method, params any) (int, map[string]any) {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeadlineHeader, deadline.UTC().Format(time.RFC3339Nano))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var reply map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, reply
+}
+
+// timedOut checks that the reply is ErrorTimeout, the task failed and the handler
+// saw its context expire
+func timedOut(t *testing.T, a *Agent, h waitHandler, taskID string, status int, reply map[string]any) {
+	t.Helper()
+
+	if status != http.StatusGatewayTimeout || reply["code"] != float64(ErrorTimeout) {
+		t.Fatalf("reply = %d %v, want 504 with ErrorTimeout", status, reply)
+	}
+
+	select {
+	case err := <-h.errs:
+		if err != context.DeadlineExceeded {
+			t.Fatalf("the handler context ended with %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the handler context was never done")
+	}
+
+	task, err := a.loadTask(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status.State != TaskStateFailed {
+		t.Fatalf("task is %s, want failed", task.Status.State)
+	}
+}
+
+func TestHeaderDeadline(t *testing.T) {
+	h := newWaitHandler()
+	a := NewAgent(AgentCard{Name: "Slow"}, WithAgentHandler(h))
+	srv, paths := serveAgent(t, a)
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	status, reply := postWithDeadline(t, srv.URL+paths.RPC, deadline, TasksSend, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")})
+
+	if got := <-h.deadlines; !got.Equal(deadline) {
+		t.Fatalf("the handler context deadline is %v, want %v", got, deadline)
+	}
+	timedOut(t, a, h, "t1", status, reply)
+}
+
+func TestMetadataDeadline(t *testing.T) {
+	h := newWaitHandler()
+	a := NewAgent(AgentCard{Name: "Slow"}, WithAgentHandler(h))
+	srv, paths := serveAgent(t, a)
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	params := TaskSendParams{ID: "t1", Message: textMessage("m1", "hi"), Metadata: map[string]any{DeadlineMetadataKey: deadline.UTC().Format(time.RFC3339Nano)}}
+	status, reply := postAs(t, srv.URL+paths.RPC, "", TasksSend, params)
+
+	if got := <-h.deadlines; !got.Equal(deadline) {
+		t.Fatalf("the handler context deadline is %v, want %v", got, deadline)
+	}
+	timedOut(t, a, h, "t1", status, reply)
+}
+
+func TestTaskTimeoutBeforeTheClientDeadline(t *testing.T) {
+	h := newWaitHandler()
+	a := NewAgent(AgentCard{Name: "Slow"}, WithAgentHandler(h), WithTaskTimeout(200*time.Millisecond))
+	srv, paths := serveAgent(t, a)
+
+	deadline := time.Now().Add(time.Hour)
+	status, reply := postWithDeadline(t, srv.URL+paths.RPC, deadline, TasksSend, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")})
+
+	if got := <-h.deadlines; !got.Before(deadline) {
+		t.Fatalf("the handler context deadline is %v, want the task timeout", got)
+	}
+	timedOut(t, a, h, "t1", status, reply)
+}
+
+func TestRequestDeadline(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	early, late := now.Add(time.Minute), now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		header   string
+		metadata any
+		want     time.Time
+	}{
+		{name: "none"},
+		{name: "header", header: early.Format(time.RFC3339Nano), want: early},
+		{name: "metadata", metadata: early.Format(time.RFC3339Nano), want: early},
+		{name: "earlier header", header: early.Format(time.RFC3339Nano), metadata: late.Format(time.RFC3339Nano), want: early},
+		{name: "earlier metadata", header: late.Format(time.RFC3339Nano), metadata: early.Format(time.RFC3339Nano), want: early},
+		{name: "invalid header", header: "tomorrow", metadata: late.Format(time.RFC3339Nano), want: late},
+		{name: "metadata not a string", header: early.Format(time.RFC3339Nano), metadata: 42, want: early},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := make(http.Header)
+			if tt.header != "" {
+				h.Set(DeadlineHeader, tt.header)
+			}
+			params := TaskSendParams{ID: "t1"}
+			if tt.metadata != nil {
+				params.Metadata = map[string]any{DeadlineMetadataKey: tt.metadata}
+			}
+
+			if got := requestDeadline(h, params); !got.Equal(tt.want) {
+				t.Fatalf("requestDeadline = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPausedTasksDontUseTheirExecutionTime(t *testing.T) {
+	timeout := 300 * time.Millisecond
+	a := NewAgent(AgentCard{Name: "Asking"}, WithAgentHandler(askHandler{}), WithTaskTimeout(timeout))
+	srv, paths := serveAgent(t, a)
+	url := srv.URL + paths.RPC
+
+	status, reply := postAs(t, url, "", TasksSend, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")})
+	if status != http.StatusOK {
+		t.Fatalf("tasks/send: %d %v", status, reply)
+	}
+
+	// the client takes longer than the timeout of the task to answer
+	time.Sleep(2 * timeout)
+
+	status, reply = postAs(t, url, "", TasksSend, TaskSendParams{ID: "t1", Message: textMessage("m2", "bob")})
+	if status != http.StatusOK {
+		t.Fatalf("follow-up: %d %v", status, reply)
+	}
+
+	task, err := a.loadTask("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status.State != TaskStateCompleted {
+		t.Fatalf("task is %s, want completed", task.Status.State)
+	}
+}
@@ -0,0 +1,251 @@
+package a2a
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"go-micro.dev/v5/registry"
+)
+
+// Registry metadata keys describing an Agent, they are set on the registry node of
+// a single Agent and on the endpoint of every Agent, including the ones mounted on
+// an AgentHost
+const (
+	MetadataCard        = "a2a.card"        // JSON encoded AgentCard
+	MetadataName        = "a2a.name"        // AgentCard.Name
+	MetadataVersion     = "a2a.version"     // AgentCard.Version
+	MetadataSkills      = "a2a.skills"      // comma separated AgentSkill.ID
+	MetadataTags        = "a2a.tags"        // comma separated tags of all the skills
+	MetadataInputModes  = "a2a.inputModes"  // comma separated DefaultInputModes
+	MetadataOutputModes = "a2a.outputModes" // comma separated DefaultOutputModes
+	MetadataStreaming   = "a2a.streaming"   // AgentCapabilities.Streaming
+	MetadataPush        = "a2a.pushNotifications"
+	MetadataPath        = "a2a.path"   // path of the JSON-RPC endpoint
+	MetadataStreamPath  = "a2a.stream" // path of the streaming endpoint
+	MetadataWSPath      = "a2a.ws"     // path of the WebSocket endpoint
+	MetadataCardPath    = "a2a.card.path"
+)
+
+// cardMetadata describes the Agent served at paths for the registry
+func cardMetadata(card AgentCard, paths agentPaths) map[string]string {
+	md := map[string]string{
+		MetadataName:        card.Name,
+		MetadataVersion:     card.Version,
+		MetadataInputModes:  strings.Join(card.DefaultInputModes, ","),
+		MetadataOutputModes: strings.Join(card.DefaultOutputModes, ","),
+		MetadataPath:        paths.RPC,
+		MetadataCardPath:    paths.Card,
+	}
+
+	if paths.Stream != "" {
+		md[MetadataStreamPath] = paths.Stream
+	}
+	if paths.WebSocket != "" {
+		md[MetadataWSPath] = paths.WebSocket
+	}
+
+	if raw, err := json.Marshal(card); err == nil {
+		md[MetadataCard] = string(raw)
+	}
+
+	var skills, tags []string
+	for _, s := range card.Skills {
+		skills = append(skills, s.ID)
+		tags = append(tags, s.Tags...)
+	}
+	md[MetadataSkills] = strings.Join(skills, ",")
+	md[MetadataTags] = strings.Join(tags, ",")
+
+	if card.Capabilities != nil {
+		md[MetadataStreaming] = strconv.FormatBool(card.Capabilities.Streaming)
+		md[MetadataPush] = strconv.FormatBool(card.Capabilities.PushNotifications)
+	}
+
+	return md
+}
+
+// DiscoveredAgent is an Agent found in the registry
+type DiscoveredAgent struct {
+	// Service is the name of the registry service serving the Agent
+	Service string
+	// Card is the AgentCard the Agent published
+	Card AgentCard
+	// Nodes are the instances of the service
+	Nodes []*registry.Node
+	// Path, StreamPath and WebSocketPath are the JSON-RPC, streaming and WebSocket
+	// endpoint paths
+	Path          string
+	StreamPath    string
+	WebSocketPath string
+}
+
+// URL returns the JSON-RPC endpoint of the Agent on its first node
+func (d DiscoveredAgent) URL() string {
+	if len(d.Nodes) == 0 {
+		return ""
+	}
+	return "http://" + d.Nodes[0].Address + d.Path
+}
+
+// StreamURL returns the streaming endpoint of the Agent on its first node, empty
+// when the Agent doesn't stream
+func (d DiscoveredAgent) StreamURL() string {
+	if len(d.Nodes) == 0 || d.StreamPath == "" {
+		return ""
+	}
+	return "http://" + d.Nodes[0].Address + d.StreamPath
+}
+
+// WebSocketURL returns the WebSocket endpoint of the Agent on its first node, empty
+// when the Agent doesn't serve one, see A2AClient.DialWebSocket
+func (d DiscoveredAgent) WebSocketURL() string {
+	if len(d.Nodes) == 0 || d.WebSocketPath == "" {
+		return ""
+	}
+	return "ws://" + d.Nodes[0].Address + d.WebSocketPath
+}
+
+// AgentFilter selects discovered Agents by their AgentCard
+type AgentFilter func(card AgentCard) bool
+
+// HasSkill selects the Agents advertising the skill
+func HasSkill(id string) AgentFilter {
+	return func(card AgentCard) bool {
+		for _, s := range card.Skills {
+			if s.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasTag selects the Agents having a skill tagged with tag, ignoring case
+func HasTag(tag string) AgentFilter {
+	return func(card AgentCard) bool {
+		for _, s := range card.Skills {
+			for _, t := range s.Tags {
+				if strings.EqualFold(t, tag) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// AcceptsInputMode selects the Agents accepting the mime type, by default or for
+// at least one skill. Agents that don't declare input modes accept anything.
+func AcceptsInputMode(mode string) AgentFilter {
+	return func(card AgentCard) bool {
+		if acceptsMode(card.DefaultInputModes, mode) {
+			return true
+		}
+		for _, s := range card.Skills {
+			if len(s.InputModes) > 0 && acceptsMode(s.InputModes, mode) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ProducesOutputMode selects the Agents producing the mime type, by default or for
+// at least one skill. Agents that don't declare output modes may produce anything.
+func ProducesOutputMode(mode string) AgentFilter {
+	return func(card AgentCard) bool {
+		if len(card.DefaultOutputModes) == 0 {
+			return true
+		}
+		for _, m := range card.DefaultOutputModes {
+			if acceptsMode([]string{mode}, m) {
+				return true
+			}
+		}
+		for _, s := range card.Skills {
+			for _, m := range s.OutputModes {
+				if acceptsMode([]string{mode}, m) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// DiscoverAgents lists the Agents registered in the registry that match all the
+// filters. Services that aren't Agents are skipped.
+//
+// Parameters:
+//   - reg: The registry the Agents registered with
+//   - filters: Optional filters, e.g. HasSkill("translate") or HasTag("weather")
+//
+// Returns:
+//   - The Agents found, an Agent mounted on an AgentHost is returned on its own
+//   - error: An error if the registry couldn't be queried
+func DiscoverAgents(reg registry.Registry, filters ...AgentFilter) ([]DiscoveredAgent, error) {
+	services, err := reg.ListServices()
+	if err != nil {
+		return nil, err
+	}
+
+	var agents []DiscoveredAgent
+	seen := make(map[string]bool)
+
+	for _, listed := range services {
+		if seen[listed.Name] {
+			continue
+		}
+		seen[listed.Name] = true
+
+		versions, err := reg.GetService(listed.Name)
+		if err != nil {
+			continue
+		}
+
+		for _, svc := range versions {
+			for _, ep := range svc.Endpoints {
+				d, ok := discovered(svc, ep.Metadata)
+				if !ok || !matches(d.Card, filters) {
+					continue
+				}
+				agents = append(agents, d)
+			}
+		}
+	}
+
+	return agents, nil
+}
+
+// discovered builds a DiscoveredAgent from registry metadata, false when the
+// metadata doesn't describe an Agent
+func discovered(svc *registry.Service, md map[string]string) (DiscoveredAgent, bool) {
+	raw, ok := md[MetadataCard]
+	if !ok {
+		return DiscoveredAgent{}, false
+	}
+
+	var card AgentCard
+	if err := json.Unmarshal([]byte(raw), &card); err != nil {
+		return DiscoveredAgent{}, false
+	}
+
+	return DiscoveredAgent{
+		Service:       svc.Name,
+		Card:          card,
+		Nodes:         svc.Nodes,
+		Path:          md[MetadataPath],
+		StreamPath:    md[MetadataStreamPath],
+		WebSocketPath: md[MetadataWSPath],
+	}, true
+}
+
+func matches(card AgentCard, filters []AgentFilter) bool {
+	for _, f := range filters {
+		if !f(card) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package a2a
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"go-micro.dev/v5/registry"
+)
+
+// registerAgent registers the AgentCard the way SwitchOn does, served at paths
+func registerAgent(t *testing.T, reg registry.Registry, card AgentCard, paths agentPaths) {
+	t.Helper()
+
+	md := cardMetadata(card, paths)
+	svc := &registry.Service{
+		Name:      serviceName(card.Name),
+		Version:   "latest",
+		Metadata:  md,
+		Nodes:     []*registry.Node{{Id: card.Name + "-1", Address: "127.0.0.1:8080", Metadata: md}},
+		Endpoints: []*registry.Endpoint{{Name: serviceName(card.Name), Metadata: md}},
+	}
+	if err := reg.Register(svc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// discoveredNames returns the sorted names of the discovered Agents
+func discoveredNames(t *testing.T, reg registry.Registry, filters ...AgentFilter) []string {
+	t.Helper()
+
+	agents, err := DiscoverAgents(reg, filters...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, d := range agents {
+		names = append(names, d.Card.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestDiscoverAgentsRoundTrip(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+
+	card := AgentCard{
+		Name:               "Translator",
+		Version:            "1.2.0",
+		URL:                "http://127.0.0.1:8080",
+		DefaultInputModes:  []string{"text/plain"},
+		DefaultOutputModes: []string{"text/plain"},
+		Capabilities:       &AgentCapabilities{Streaming: true},
+		Skills:             []AgentSkill{{ID: "translate", Name: "Translate", Tags: []string{"language"}}},
+	}
+	registerAgent(t, reg, card, agentPaths{RPC: "/Translator", Stream: "/Translator/stream", WebSocket: "/Translator/ws", Card: AgentCardPath})
+
+	// services that aren't Agents are skipped
+	if err := reg.Register(&registry.Service{Name: "other", Nodes: []*registry.Node{{Id: "other-1", Address: "127.0.0.1:9090"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	agents, err := DiscoverAgents(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("discovered %d agents, want 1", len(agents))
+	}
+
+	d := agents[0]
+	if !reflect.DeepEqual(d.Card, card) {
+		t.Fatalf("card = %+v, want %+v", d.Card, card)
+	}
+	if d.Service != "Translator" {
+		t.Fatalf("service = %q", d.Service)
+	}
+	if got := d.URL(); got != "http://127.0.0.1:8080/Translator" {
+		t.Fatalf("URL = %q", got)
+	}
+	if got := d.StreamURL(); got != "http://127.0.0.1:8080/Translator/stream" {
+		t.Fatalf("StreamURL = %q", got)
+	}
+	if got := d.WebSocketURL(); got != "ws://127.0.0.1:8080/Translator/ws" {
+		t.Fatalf("WebSocketURL = %q", got)
+	}
+}
+
+func TestDiscoverAgentsFilters(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+
+	registerAgent(t, reg, AgentCard{
+		Name:               "Translator",
+		DefaultInputModes:  []string{"text/plain"},
+		DefaultOutputModes: []string{"text/plain"},
+		Skills:             []AgentSkill{{ID: "translate", Tags: []string{"Language"}}},
+	}, agentPaths{RPC: "/Translator"})
+
+	registerAgent(t, reg, AgentCard{
+		Name:               "Painter",
+		DefaultInputModes:  []string{"text/plain"},
+		DefaultOutputModes: []string{"text/plain"},
+		Skills: []AgentSkill{
+			{ID: "describe", Tags: []string{"vision"}, InputModes: []string{"image/*"}},
+			{ID: "paint", Tags: []string{"art"}, OutputModes: []string{"image/png"}},
+		},
+	}, agentPaths{RPC: "/Painter"})
+
+	registerAgent(t, reg, AgentCard{Name: "Anything"}, agentPaths{RPC: "/Anything"})
+
+	tests := []struct {
+		name    string
+		filters []AgentFilter
+		want    []string
+	}{
+		{name: "no filter", want: []string{"Anything", "Painter", "Translator"}},
+		{name: "skill", filters: []AgentFilter{HasSkill("paint")}, want: []string{"Painter"}},
+		{name: "unknown skill", filters: []AgentFilter{HasSkill("sing")}, want: []string{}},
+		{name: "tag ignoring case", filters: []AgentFilter{HasTag("language")}, want: []string{"Translator"}},
+		{name: "input mode of a skill", filters: []AgentFilter{AcceptsInputMode("image/jpeg")}, want: []string{"Anything", "Painter"}},
+		{name: "default input mode", filters: []AgentFilter{AcceptsInputMode("text/plain")}, want: []string{"Anything", "Painter", "Translator"}},
+		{name: "output mode of a skill", filters: []AgentFilter{ProducesOutputMode("image/*")}, want: []string{"Anything", "Painter"}},
+		{name: "output mode nobody produces", filters: []AgentFilter{ProducesOutputMode("audio/mpeg")}, want: []string{"Anything"}},
+		{name: "all filters", filters: []AgentFilter{HasTag("vision"), AcceptsInputMode("text/plain"), ProducesOutputMode("image/png")}, want: []string{"Painter"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := discoveredNames(t, reg, tt.filters...); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("discovered %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package a2a
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-micro.dev/v5/store"
+)
+
+// EventKind is what a TaskEvent records
+type EventKind string
+
+const (
+	EventCreated   EventKind = "created"   // the task was created in ContextID
+	EventContext   EventKind = "context"   // the task moved to ContextID
+	EventMessage   EventKind = "message"   // Message was appended to the history
+	EventStatus    EventKind = "status"    // the task moved to Status
+	EventArtifact  EventKind = "artifact"  // Artifact was added to the artifacts
+	EventHistory   EventKind = "history"   // the history was replaced with History
+	EventArtifacts EventKind = "artifacts" // the artifacts were replaced with Artifacts
+	EventMetadata  EventKind = "metadata"  // the metadata was replaced with Metadata
+)
+
+// TaskEvent is an entry of the append-only log the Agent keeps for every task, the
+// stored Task is the projection of its log. Seq starts at 1 and grows by one with
+// every event of the task.
+type TaskEvent struct {
+	TaskID    string    `json:"taskId"`
+	Seq       int64     `json:"seq"`
+	Timestamp string    `json:"timestamp"` // RFC 3339 time the event was recorded
+	Kind      EventKind `json:"kind"`
+
+	ContextID string         `json:"contextId,omitempty"`
+	Status    *TaskStatus    `json:"status,omitempty"`
+	Message   *Message       `json:"message,omitempty"`
+	Artifact  *Artifact      `json:"artifact,omitempty"`
+	History   []Message      `json:"history,omitempty"`
+	Artifacts []Artifact     `json:"artifacts,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// StateTransitionHistoryKey is the task metadata entry tasks/get returns the
+// statuses of the task under, when the AgentCard advertises stateTransitionHistory
+const StateTransitionHistoryKey = "stateTransitionHistory"
+
+// the events of a task sort by sequence number under its prefix
+const eventKeyPrefix = "events/"
+
+func eventPrefix(taskID string) string {
+	return eventKeyPrefix + taskID + "/"
+}
+
+func eventKey(taskID string, seq int64) string {
+	return fmt.Sprintf("%s%020d", eventPrefix(taskID), seq)
+}
+
+// TaskEvents returns the events of the task logged after the sequence number, in
+// the order they happened. It returns store.ErrNotFound for a task without events.
+//
+// The events are read by key from the sequence number up to the last one the task
+// record counts, the log of the task is never listed.
+func (a *Agent) TaskEvents(taskID string, after int64) ([]TaskEvent, error) {
+	record, err := a.readTaskRecord(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	last := parseTaskMeta(record.Metadata).seq
+	if last == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	var events []TaskEvent
+	for seq := max(after, 0) + 1; seq <= last; seq++ {
+		records, err := a.options.Store.Read(eventKey(taskID, seq))
+		if err == store.ErrNotFound || (err == nil && len(records) == 0) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var e TaskEvent
+		if err := json.Unmarshal(records[0].Value, &e); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// StatusHistory returns the statuses the task went through, oldest first
+func (a *Agent) StatusHistory(taskID string) ([]TaskStatus, error) {
+	events, err := a.TaskEvents(taskID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []TaskStatus
+	for _, e := range events {
+		if e.Kind == EventStatus && e.Status != nil {
+			history = append(history, *e.Status)
+		}
+	}
+
+	return history, nil
+}
+
+// appendEvents logs the events of the task after the sequence number, they are
+// numbered in order. It returns the sequence number of the last one.
+func (a *Agent) appendEvents(taskID string, seq int64, events []TaskEvent) (int64, error) {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+
+	for i := range events {
+		seq++
+		events[i].TaskID = taskID
+		events[i].Seq = seq
+		events[i].Timestamp = now
+
+		raw, err := json.Marshal(events[i])
+		if err != nil {
+			return seq - 1, err
+		}
+
+		if err := a.options.Store.Write(&store.Record{Key: eventKey(taskID, seq), Value: raw}); err != nil {
+			return seq - 1, err
+		}
+	}
+
+	return seq, nil
+}
+
+// diffTask returns the events taking the task from prev to next, prev is nil for a
+// new task. Messages and artifacts appended show up one by one, a list that was
+// trimmed or replaced is logged as a whole, see appendedTo.
+func diffTask(prev, next *Task) []TaskEvent {
+	var events []TaskEvent
+
+	if prev == nil {
+		prev = &Task{ContextID: next.ContextID}
+		events = append(events, TaskEvent{Kind: EventCreated, ContextID: next.ContextID})
+	}
+
+	if prev.ContextID != next.ContextID {
+		events = append(events, TaskEvent{Kind: EventContext, ContextID: next.ContextID})
+	}
+
+	if appended, ok := appendedTo(prev.History, next.History); ok {
+		for i := range appended {
+			events = append(events, TaskEvent{Kind: EventMessage, Message: &appended[i]})
+		}
+	} else {
+		events = append(events, TaskEvent{Kind: EventHistory, History: next.History})
+	}
+
+	if !sameJSON(prev.Status, next.Status) {
+		status := next.Status
+		events = append(events, TaskEvent{Kind: EventStatus, Status: &status})
+	}
+
+	if appended, ok := appendedTo(prev.Artifacts, next.Artifacts); ok {
+		for i := range appended {
+			events = append(events, TaskEvent{Kind: EventArtifact, Artifact: &appended[i]})
+		}
+	} else {
+		events = append(events, TaskEvent{Kind: EventArtifacts, Artifacts: next.Artifacts})
+	}
+
+	if !sameJSON(prev.Metadata, next.Metadata) {
+		events = append(events, TaskEvent{Kind: EventMetadata, Metadata: next.Metadata})
+	}
+
+	return events
+}
+
+// appendedTo returns the elements of next following the ones of prev, it returns
+// false when next doesn't start with every element of prev
+func appendedTo[T any](prev, next []T) ([]T, bool) {
+	if len(next) < len(prev) {
+		return nil, false
+	}
+
+	for i := range prev {
+		if !sameJSON(prev[i], next[i]) {
+			return nil, false
+		}
+	}
+
+	return next[len(prev):], true
+}
+
+// sameJSON compares values by their JSON encoding, a task read from the store and
+// one built by a handler hold their parts as different types
+func sameJSON(a, b any) bool {
+	ra, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	rb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ra, rb)
+}
+
+// projectTask applies the events to the task in order
+func projectTask(t *Task, events []TaskEvent) {
+	for _, e := range events {
+		switch e.Kind {
+		case EventCreated:
+			t.Kind = "task"
+			t.ID = e.TaskID
+			t.ContextID = e.ContextID
+		case EventContext:
+			t.ContextID = e.ContextID
+		case EventMessage:
+			t.History = append(t.History, *e.Message)
+		case EventStatus:
+			t.Status = *e.Status
+		case EventArtifact:
+			t.Artifacts = append(t.Artifacts, *e.Artifact)
+		case EventHistory:
+			t.History = e.History
+		case EventArtifacts:
+			t.Artifacts = e.Artifacts
+		case EventMetadata:
+			t.Metadata = e.Metadata
+		}
+	}
+}
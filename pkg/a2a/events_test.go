@@ -0,0 +1,190 @@
+package a2a
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffTaskOnlyLogsWhatWasAppended(t *testing.T) {
+	prev := &Task{ID: "t1", History: []Message{textMessage("m1", "one")}, Status: TaskStatus{State: TaskStateWorking}}
+	next := &Task{ID: "t1", History: []Message{textMessage("m1", "one"), textMessage("m2", "two")}, Status: TaskStatus{State: TaskStateWorking}}
+
+	events := diffTask(prev, next)
+	if len(events) != 1 || events[0].Kind != EventMessage || events[0].Message.MessageId != "m2" {
+		t.Fatalf("events = %+v, want the m2 message only", events)
+	}
+
+	// a history replaced as a whole is logged as such
+	next.History = []Message{textMessage("m3", "three"), textMessage("m4", "four")}
+	events = diffTask(prev, next)
+	if len(events) != 1 || events[0].Kind != EventHistory {
+		t.Fatalf("events = %+v, want the history replaced", events)
+	}
+}
+
+func TestDiffTask(t *testing.T) {
+	m1, m2, m3 := textMessage("m1", "one"), textMessage("m2", "two"), textMessage("m3", "three")
+	a1, a2 := Artifact{ArtifactID: "a1"}, Artifact{ArtifactID: "a2"}
+	working := TaskStatus{State: TaskStateWorking}
+
+	tests := []struct {
+		name string
+		prev *Task
+		next *Task
+		want []EventKind
+	}{
+		{
+			name: "new task",
+			next: &Task{ID: "t1", ContextID: "c1", History: []Message{m1}, Status: working},
+			want: []EventKind{EventCreated, EventMessage, EventStatus},
+		},
+		{
+			name: "unchanged",
+			prev: &Task{ID: "t1", ContextID: "c1", History: []Message{m1}, Status: working},
+			next: &Task{ID: "t1", ContextID: "c1", History: []Message{m1}, Status: working},
+		},
+		{
+			name: "context",
+			prev: &Task{ID: "t1", ContextID: "c1", Status: working},
+			next: &Task{ID: "t1", ContextID: "c2", Status: working},
+			want: []EventKind{EventContext},
+		},
+		{
+			name: "messages appended",
+			prev: &Task{ID: "t1", History: []Message{m1}},
+			next: &Task{ID: "t1", History: []Message{m1, m2, m3}},
+			want: []EventKind{EventMessage, EventMessage},
+		},
+		{
+			name: "history trimmed",
+			prev: &Task{ID: "t1", History: []Message{m1, m2}},
+			next: &Task{ID: "t1", History: []Message{m2}},
+			want: []EventKind{EventHistory},
+		},
+		{
+			name: "history changed before its last message",
+			prev: &Task{ID: "t1", History: []Message{m1, m2}},
+			next: &Task{ID: "t1", History: []Message{m3, m2, m1}},
+			want: []EventKind{EventHistory},
+		},
+		{
+			name: "status",
+			prev: &Task{ID: "t1", Status: working},
+			next: &Task{ID: "t1", Status: TaskStatus{State: TaskStateCompleted}},
+			want: []EventKind{EventStatus},
+		},
+		{
+			name: "artifact appended",
+			prev: &Task{ID: "t1", Artifacts: []Artifact{a1}},
+			next: &Task{ID: "t1", Artifacts: []Artifact{a1, a2}},
+			want: []EventKind{EventArtifact},
+		},
+		{
+			name: "artifacts replaced",
+			prev: &Task{ID: "t1", Artifacts: []Artifact{a1, a2}},
+			next: &Task{ID: "t1", Artifacts: []Artifact{a2, a2}},
+			want: []EventKind{EventArtifacts},
+		},
+		{
+			name: "metadata",
+			prev: &Task{ID: "t1"},
+			next: &Task{ID: "t1", Metadata: map[string]any{"skillId": "s1"}},
+			want: []EventKind{EventMetadata},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := diffTask(tt.prev, tt.next)
+
+			var kinds []EventKind
+			for _, e := range events {
+				kinds = append(kinds, e.Kind)
+			}
+			if !slices.Equal(kinds, tt.want) {
+				t.Fatalf("events = %v, want %v", kinds, tt.want)
+			}
+
+			// the events take prev to next
+			projected := &Task{}
+			if tt.prev != nil {
+				projected = &Task{Kind: "task", ID: tt.prev.ID, ContextID: tt.prev.ContextID, Status: tt.prev.Status, Metadata: tt.prev.Metadata}
+				projected.History = append(projected.History, tt.prev.History...)
+				projected.Artifacts = append(projected.Artifacts, tt.prev.Artifacts...)
+			}
+			for i := range events {
+				events[i].TaskID = tt.next.ID
+			}
+			projectTask(projected, events)
+
+			want := *tt.next
+			want.Kind = "task"
+			if !sameJSON(projected, &want) {
+				t.Fatalf("projected %+v, want %+v", projected, want)
+			}
+		})
+	}
+}
+
+func TestTaskMovesToItsNewContext(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Logged"})
+
+	task := &Task{ID: "t1", ContextID: "c1", Status: TaskStatus{State: TaskStateSubmitted}}
+	if err := a.createTask(task, "caller"); err != nil {
+		t.Fatal(err)
+	}
+
+	task.ContextID = "c2"
+	if err := a.saveTask(task); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := a.loadTask("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ContextID != "c2" {
+		t.Fatalf("context = %q, want c2", stored.ContextID)
+	}
+
+	for contextID, want := range map[string]int{"c1": 0, "c2": 1} {
+		tasks, err := (&Conversation{ContextID: contextID, agent: a}).Tasks()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tasks) != want {
+			t.Fatalf("conversation %s has %d tasks, want %d", contextID, len(tasks), want)
+		}
+	}
+}
+
+func TestTaskEventsAfterSequenceNumber(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Logged"})
+
+	task := &Task{ID: "t1", Status: TaskStatus{State: TaskStateSubmitted}}
+	if err := a.createTask(task, "caller"); err != nil {
+		t.Fatal(err)
+	}
+	for _, state := range []TaskState{TaskStateWorking, TaskStateCompleted} {
+		if _, err := a.setTaskState("t1", state); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events, err := a.TaskEvents("t1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) < 3 {
+		t.Fatalf("got %d events, want at least the creation and the two statuses", len(events))
+	}
+
+	last := events[len(events)-1]
+	tail, err := a.TaskEvents("t1", last.Seq-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tail) != 1 || tail[0].Seq != last.Seq || tail[0].Status.State != TaskStateCompleted {
+		t.Fatalf("events after %d = %+v, want only the completed status", last.Seq-1, tail)
+	}
+}
@@ -0,0 +1,257 @@
+package a2a
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// DefaultWorkers is the number of handlers an Agent runs concurrently when
+	// the WithWorkerPool option is not provided
+	DefaultWorkers = 10
+
+	// DefaultQueueSize is the number of tasks an Agent keeps waiting in the
+	// submitted state when the WithWorkerPool option is not provided
+	DefaultQueueSize = 100
+
+	// DefaultMaxPaused is the number of handlers paused waiting for input or auth
+	// that give their worker back when the WithMaxPaused option is not provided
+	DefaultMaxPaused = 100
+)
+
+// job is a unit of work queued on the executor, it wraps a single
+// TaskHandler or StreamHandler invocation for the run of a task
+type job struct {
+	run  *taskRun
+	fn   func()
+	done chan struct{}
+}
+
+// executor runs queued jobs on a bounded pool of workers. A job whose skill has a
+// concurrency limit waits in the queue of its skill until a slot for that skill is
+// free, only then it is handed to the pool, so a worker never waits for a skill.
+type executor struct {
+	// ready holds the jobs a worker can run right away
+	ready chan *job
+
+	mu sync.Mutex
+	// queued counts the jobs waiting, in ready or in the queue of their skill
+	queued int
+	size   int
+	skills map[string]*skillQueue
+
+	workers int
+	once    sync.Once
+
+	// parked counts the jobs paused waiting for input, a worker was started in the
+	// place of each of them, never more than maxParked
+	parked    int
+	maxParked int
+	// retire takes an idle worker out of the pool when a parked job carries on,
+	// surplus counts the workers to take out once they are done with their job
+	retire  chan struct{}
+	surplus int
+
+	// panicked is called with what a job panicked with, the worker carries on
+	panicked func(j *job, v any)
+}
+
+// skillQueue holds the jobs of a skill waiting for one of its slots
+type skillQueue struct {
+	limit   int
+	running int
+	backlog []*job
+}
+
+func newExecutor(workers, queueSize, maxParked int, skillLimits map[string]int) *executor {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	if maxParked <= 0 {
+		maxParked = DefaultMaxPaused
+	}
+
+	e := &executor{
+		// never more than queueSize jobs are waiting, sending to ready doesn't block
+		ready:     make(chan *job, queueSize),
+		size:      queueSize,
+		skills:    make(map[string]*skillQueue, len(skillLimits)),
+		workers:   workers,
+		maxParked: maxParked,
+		retire:    make(chan struct{}),
+	}
+
+	for skillID, limit := range skillLimits {
+		if limit > 0 {
+			e.skills[skillID] = &skillQueue{limit: limit}
+		}
+	}
+
+	return e
+}
+
+// start launches the workers, calling it more than once has no effect
+func (e *executor) start() {
+	e.once.Do(func() {
+		for i := 0; i < e.workers; i++ {
+			go e.work()
+		}
+	})
+}
+
+func (e *executor) work() {
+	for {
+		select {
+		case j := <-e.ready:
+			e.mu.Lock()
+			e.queued--
+			e.mu.Unlock()
+
+			e.execute(j)
+			if e.retired() {
+				return
+			}
+
+		case <-e.retire:
+			return
+		}
+	}
+}
+
+// retired reports whether the worker is one too many since a parked job carried on
+func (e *executor) retired() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.surplus == 0 {
+		return false
+	}
+	e.surplus--
+	return true
+}
+
+// park is called by a job pausing until the client answers, a new worker takes its
+// place in the pool. It returns false when maxParked jobs are parked already, the
+// job holds on to its worker then.
+func (e *executor) park() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.parked >= e.maxParked {
+		return false
+	}
+	e.parked++
+
+	go e.work()
+	return true
+}
+
+// unpark is called by a parked job that carries on, it waits for a worker to be
+// idle and takes it out of the pool so no more than the configured number of jobs
+// run at once. When ctx is done first, the next worker done with its job goes.
+func (e *executor) unpark(ctx context.Context) {
+	e.mu.Lock()
+	e.parked--
+	e.mu.Unlock()
+
+	select {
+	case e.retire <- struct{}{}:
+	case <-ctx.Done():
+		e.mu.Lock()
+		e.surplus++
+		e.mu.Unlock()
+	}
+}
+
+// execute runs the job, a panic anywhere in it is handed to panicked so the worker
+// and the slot of the skill survive it
+func (e *executor) execute(j *job) {
+	defer close(j.done)
+	defer e.release(j.run.skillID)
+	defer func() {
+		if v := recover(); v != nil && e.panicked != nil {
+			e.panicked(j, v)
+		}
+	}()
+
+	j.fn()
+}
+
+// release frees the slot of a job that ended, the next job of its skill takes it
+func (e *executor) release(skillID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	sq, ok := e.skills[skillID]
+	if !ok {
+		return
+	}
+
+	if len(sq.backlog) > 0 {
+		next := sq.backlog[0]
+		sq.backlog = sq.backlog[1:]
+		e.ready <- next
+		return
+	}
+	sq.running--
+}
+
+// submit queues fn for the run without blocking, the run is the one a panic in fn
+// fails. When the queue is full the job is rejected with ErrorServiceUnavailable so
+// callers can back off and retry.
+func (e *executor) submit(run *taskRun, fn func()) (*job, error) {
+	j := &job{
+		run:  run,
+		fn:   fn,
+		done: make(chan struct{}),
+	}
+	skillID := run.skillID
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.queued >= e.size {
+		return nil, NewError(ErrorServiceUnavailable, "the agent is busy, try again later", map[string]any{
+			"queueDepth": e.queued,
+			"queueSize":  e.size,
+		})
+	}
+	e.queued++
+
+	sq, ok := e.skills[skillID]
+	switch {
+	case !ok:
+		e.ready <- j
+	case sq.running < sq.limit:
+		sq.running++
+		e.ready <- j
+	default:
+		sq.backlog = append(sq.backlog, j)
+	}
+
+	return j, nil
+}
+
+// depth returns the number of jobs waiting for a worker or for a slot of their skill
+func (e *executor) depth() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.queued
+}
+
+// skillHint returns the skill a request asks for, clients pass it as "skillId" in
+// the message metadata or in the TaskSendParams metadata
+func skillHint(params TaskSendParams) string {
+	for _, md := range []map[string]any{params.Message.Metadata, params.Metadata} {
+		if id, ok := md["skillId"].(string); ok && id != "" {
+			return id
+		}
+	}
+	return ""
+}
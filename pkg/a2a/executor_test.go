@@ -0,0 +1,89 @@
+package a2a
+
+import (
+	"testing"
+	"time"
+)
+
+// skillRun is a run of the skill, the executor only reads its skill
+func skillRun(taskID, skillID string) *taskRun {
+	return &taskRun{taskID: taskID, skillID: skillID}
+}
+
+func TestExecutorSkillLimitDoesNotStarveOtherSkills(t *testing.T) {
+	e := newExecutor(2, 10, 0, map[string]int{"slow": 1})
+	e.start()
+
+	block := make(chan struct{})
+	defer close(block)
+
+	// the slow skill takes its only slot, its other jobs wait for it
+	for i := 0; i < 4; i++ {
+		if _, err := e.submit(skillRun("slow", "slow"), func() { <-block }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	j, err := e.submit(skillRun("fast", "fast"), func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-j.done:
+	case <-time.After(time.Second):
+		t.Fatal("the job of another skill waited for the saturated skill")
+	}
+
+	if d := e.depth(); d != 3 {
+		t.Fatalf("depth = %d, want the 3 slow jobs waiting for their slot", d)
+	}
+}
+
+func TestExecutorRejectsWhenQueueIsFull(t *testing.T) {
+	e := newExecutor(1, 2, 0, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := e.submit(skillRun("t", ""), func() {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := e.submit(skillRun("t", ""), func() {})
+	if e, ok := err.(JSONRPCError); !ok || e.Code != ErrorServiceUnavailable {
+		t.Fatalf("err = %v, want ErrorServiceUnavailable", err)
+	}
+}
+
+func TestExecutorSurvivesPanickingJobs(t *testing.T) {
+	e := newExecutor(1, 10, 0, map[string]int{"s": 1})
+
+	panicked := make(chan *job, 1)
+	e.panicked = func(j *job, v any) {
+		if v != "boom" {
+			t.Errorf("panicked with %v, want boom", v)
+		}
+		panicked <- j
+	}
+	e.start()
+
+	run := skillRun("t1", "s")
+	if _, err := e.submit(run, func() { panic("boom") }); err != nil {
+		t.Fatal(err)
+	}
+	if j := <-panicked; j.run != run {
+		t.Fatalf("the panic was reported for task %s, want t1", j.run.taskID)
+	}
+
+	// the only worker and the only slot of the skill are free again
+	j, err := e.submit(skillRun("t2", "s"), func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-j.done:
+	case <-time.After(time.Second):
+		t.Fatal("the job after the panic never ran")
+	}
+}
@@ -0,0 +1,420 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro-a2a/pkg/a2a/a2apb"
+	"go-micro.dev/v5/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The gRPC binding serves the A2AService of the a2apb package, its messages are the
+// JSON objects of the JSON-RPC binding, see a2a.proto. Every call is served as a
+// local JSON-RPC request, the metadata of the call are its headers, see local.go.
+
+// Trailer keys of the calls that failed with an A2A error
+const (
+	GRPCErrorCodeKey = "a2a-error-code"
+	GRPCErrorDataKey = "a2a-error-data"
+)
+
+// grpcStreamOpenKey is the header a stream sends once its task accepted it
+const grpcStreamOpenKey = "a2a-stream"
+
+var (
+	protoIn  = protojson.UnmarshalOptions{DiscardUnknown: true}
+	protoOut = protojson.MarshalOptions{}
+)
+
+// grpcBinding serves the A2AService of an Agent
+type grpcBinding struct {
+	a2apb.UnimplementedA2AServiceServer
+
+	agent  *Agent
+	paths  agentPaths
+	server *grpc.Server
+}
+
+// withGRPCInterface returns the card listing the gRPC binding among its additional
+// interfaces when it is enabled
+func withGRPCInterface(card AgentCard, address string) AgentCard {
+	if address == "" {
+		return card
+	}
+
+	card.AdditionalInterfaces = append(append([]AgentInterface(nil), card.AdditionalInterfaces...), AgentInterface{
+		URL:       address,
+		Transport: TransportGRPC,
+	})
+	return card
+}
+
+// grpcAdvertised returns the address clients reach the gRPC binding listening at
+// listen at: advertise when it is set, listen when it names a host, otherwise the
+// port of listen on the host of the AgentCard URL or of the machine
+func grpcAdvertised(card AgentCard, listen, advertise string) (string, error) {
+	if advertise != "" {
+		return advertise, nil
+	}
+
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "", err
+	}
+	if port == "0" {
+		return "", fmt.Errorf("the port of %q is picked when the binding starts, set the address to advertise", listen)
+	}
+	if ip := net.ParseIP(host); host != "" && (ip == nil || !ip.IsUnspecified()) {
+		return listen, nil
+	}
+
+	if u, err := url.Parse(card.URL); err == nil && u.Hostname() != "" {
+		return net.JoinHostPort(u.Hostname(), port), nil
+	}
+
+	host, err = os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// serveGRPC starts serving the gRPC binding in the background, it is stopped by
+// the shutdown of the Agent
+func (a *Agent) serveGRPC(paths agentPaths) error {
+	if a.options.GRPCAddress == "" {
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", a.options.GRPCAddress)
+	if err != nil {
+		return err
+	}
+
+	b := &grpcBinding{agent: a, paths: paths, server: grpc.NewServer(a.options.GRPCServerOptions...)}
+	a2apb.RegisterA2AServiceServer(b.server, b)
+	a.grpc = b
+
+	a.options.Logger.Log(logger.InfoLevel, "gRPC binding listening on "+lis.Addr().String())
+
+	go func() {
+		if err := b.server.Serve(lis); err != nil {
+			a.options.Logger.Log(logger.ErrorLevel, err)
+		}
+	}()
+
+	return nil
+}
+
+// stop lets the calls in flight finish, for at most finalEventTimeout
+func (b *grpcBinding) stop() {
+	if b == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		b.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(finalEventTimeout):
+		b.server.Stop()
+	}
+}
+
+func (b *grpcBinding) SendTask(ctx context.Context, req *a2apb.SendTaskRequest) (*a2apb.Task, error) {
+	task := &a2apb.Task{}
+	return task, b.call(ctx, TasksSend, req, task)
+}
+
+func (b *grpcBinding) SendTaskSubscribe(req *a2apb.SendTaskRequest, stream grpc.ServerStreamingServer[a2apb.TaskEvent]) error {
+	return b.stream(stream, TasksSendSubscribe, req, "")
+}
+
+func (b *grpcBinding) GetTask(ctx context.Context, req *a2apb.GetTaskRequest) (*a2apb.Task, error) {
+	task := &a2apb.Task{}
+	return task, b.call(ctx, TasksGet, req, task)
+}
+
+func (b *grpcBinding) CancelTask(ctx context.Context, req *a2apb.CancelTaskRequest) (*a2apb.Task, error) {
+	task := &a2apb.Task{}
+	return task, b.call(ctx, TasksCancel, req, task)
+}
+
+func (b *grpcBinding) ResubscribeTask(req *a2apb.ResubscribeTaskRequest, stream grpc.ServerStreamingServer[a2apb.TaskEvent]) error {
+	params := proto.Clone(req).(*a2apb.ResubscribeTaskRequest)
+	params.LastEventId = ""
+
+	return b.stream(stream, TasksResubscribe, params, req.GetLastEventId())
+}
+
+func (b *grpcBinding) SetTaskPushNotification(ctx context.Context, req *a2apb.TaskPushNotificationConfig) (*a2apb.TaskPushNotificationConfig, error) {
+	config := &a2apb.TaskPushNotificationConfig{}
+	return config, b.call(ctx, TasksPushNotificationSet, req, config)
+}
+
+func (b *grpcBinding) GetTaskPushNotification(ctx context.Context, req *a2apb.GetTaskPushNotificationRequest) (*a2apb.TaskPushNotificationConfig, error) {
+	config := &a2apb.TaskPushNotificationConfig{}
+	return config, b.call(ctx, TasksPushNotificationGet, req, config)
+}
+
+func (b *grpcBinding) ListTasks(ctx context.Context, req *a2apb.ListTasksRequest) (*a2apb.TaskList, error) {
+	list := &a2apb.TaskList{}
+	return list, b.call(ctx, TasksList, req, list)
+}
+
+func (b *grpcBinding) GetAgentCard(ctx context.Context, _ *a2apb.GetAgentCardRequest) (*structpb.Struct, error) {
+	raw, err := json.Marshal(b.agent.options.AgentCard)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	card := &structpb.Struct{}
+	if err := protoIn.Unmarshal(raw, card); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return card, nil
+}
+
+// call serves a unary call as the JSON-RPC request of the method, the result of the
+// response is read into result
+func (b *grpcBinding) call(ctx context.Context, method Method, params, result proto.Message) error {
+	body, _, err := rpcBody(method, params)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	w := &frameWriter{}
+	b.agent.local.ServeHTTP(w, b.request(ctx, http.MethodPost, b.paths.RPC, body))
+
+	var res ResponseWrapper
+	if err := decodeReply(w, &res); err != nil {
+		return grpcError(ctx, err)
+	}
+	if res.Error != nil {
+		return grpcError(ctx, *res.Error)
+	}
+
+	if err := protoIn.Unmarshal(res.Result, result); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// stream serves a streaming call as the JSON-RPC request of the method and the
+// stream of its task, resumed after lastEventID when it is set
+func (b *grpcBinding) stream(stream grpc.ServerStreamingServer[a2apb.TaskEvent], method Method, params proto.Message, lastEventID string) error {
+	ctx := stream.Context()
+
+	if b.paths.Stream == "" {
+		return grpcError(ctx, NewError(ErrorUnsupportedOperation, "the agent doesn't support streaming", nil))
+	}
+
+	body, id, err := rpcBody(method, params)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// the request is accepted first, its stream is opened then
+	w := &frameWriter{}
+	b.agent.local.ServeHTTP(w, b.request(ctx, http.MethodPost, b.paths.Stream, body))
+	if err := decodeReply(w, nil); err != nil {
+		return grpcError(ctx, err)
+	}
+
+	if err := stream.SendHeader(metadata.Pairs(grpcStreamOpenKey, "open")); err != nil {
+		return err
+	}
+
+	// the events are sent as the task publishes them, failure ends the call
+	var failure error
+	send := func(res JSONRPCResponse) error {
+		if res.Error != nil {
+			return grpcError(ctx, *res.Error)
+		}
+
+		raw, err := json.Marshal(res.Result)
+		if err != nil {
+			return err
+		}
+		event, err := taskEvent(raw)
+		if err != nil {
+			return err
+		}
+		if res.eventID.seq > 0 {
+			event.EventId = res.eventID.String()
+		}
+		return stream.Send(event)
+	}
+	sink := func(res JSONRPCResponse) error {
+		failure = send(res)
+		return failure
+	}
+
+	events := &frameWriter{}
+	get := b.request(withStreamSink(ctx, sink), http.MethodGet, b.paths.Stream+"?id="+url.QueryEscape(id), nil)
+	if lastEventID != "" {
+		get.Header.Set("Last-Event-ID", lastEventID)
+	}
+	b.agent.local.ServeHTTP(events, get)
+
+	if events.failed() {
+		return grpcError(ctx, decodeReply(events, nil))
+	}
+	return failure
+}
+
+// request builds the local request of a call, the metadata of the call are its
+// headers and the deadline of the call its DeadlineHeader
+func (b *grpcBinding) request(ctx context.Context, method, target string, body []byte) *http.Request {
+	header := http.Header{}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, vs := range md {
+		if strings.HasPrefix(k, ":") || strings.HasPrefix(k, "grpc-") || k == "content-type" || k == "te" {
+			continue
+		}
+		for _, v := range vs {
+			header.Add(k, v)
+		}
+	}
+
+	header.Del(DeadlineHeader)
+	if deadline, ok := ctx.Deadline(); ok {
+		header.Set(DeadlineHeader, deadline.UTC().Format(time.RFC3339Nano))
+	}
+
+	r := localRequest(ctx, method, target, body, header)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+
+	return r
+}
+
+// rpcBody returns the JSON-RPC request of the method with the message as its params,
+// and its ID
+func rpcBody(method Method, params proto.Message) ([]byte, string, error) {
+	raw, err := protoOut.Marshal(params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id := uuid.NewString()
+	body, err := json.Marshal(RequestWrapper{JSONRPC: "2.0", ID: id, Method: method, Params: raw})
+	return body, id, err
+}
+
+// decodeReply reads what a handler replied into res, a nil res only checks for an
+// error. The errors handlers reply with as bare JSONRPCError are returned.
+func decodeReply(w *frameWriter, res *ResponseWrapper) error {
+	if !w.failed() {
+		if res == nil {
+			return nil
+		}
+		if err := json.Unmarshal(w.body.Bytes(), res); err != nil {
+			return NewError(ErrorInternal, err.Error(), nil)
+		}
+		return nil
+	}
+
+	var e JSONRPCError
+	if err := json.Unmarshal(w.body.Bytes(), &e); err == nil && e.Code != 0 {
+		return e
+	}
+
+	var wrapped ResponseWrapper
+	if err := json.Unmarshal(w.body.Bytes(), &wrapped); err == nil && wrapped.Error != nil {
+		return *wrapped.Error
+	}
+
+	return NewError(ErrorInternal, http.StatusText(w.status), nil)
+}
+
+// taskEvent reads a streamed result, artifact updates are told apart by their
+// artifact
+func taskEvent(result json.RawMessage) (*a2apb.TaskEvent, error) {
+	var kind struct {
+		Artifact json.RawMessage `json:"artifact"`
+	}
+	if err := json.Unmarshal(result, &kind); err != nil {
+		return nil, err
+	}
+
+	if kind.Artifact != nil {
+		update := &a2apb.TaskArtifactUpdateEvent{}
+		if err := protoIn.Unmarshal(result, update); err != nil {
+			return nil, err
+		}
+		return &a2apb.TaskEvent{Event: &a2apb.TaskEvent_ArtifactUpdate{ArtifactUpdate: update}}, nil
+	}
+
+	update := &a2apb.TaskStatusUpdateEvent{}
+	if err := protoIn.Unmarshal(result, update); err != nil {
+		return nil, err
+	}
+	return &a2apb.TaskEvent{Event: &a2apb.TaskEvent_StatusUpdate{StatusUpdate: update}}, nil
+}
+
+// grpcError returns the status of the A2A error, the error itself goes in the
+// trailer of the call. Any other error is an internal one.
+func grpcError(ctx context.Context, err error) error {
+	e, ok := err.(JSONRPCError)
+	if !ok {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	md := metadata.Pairs(GRPCErrorCodeKey, strconv.Itoa(int(e.Code)))
+	if e.Data != nil {
+		if raw, err := json.Marshal(e.Data); err == nil {
+			md.Set(GRPCErrorDataKey, string(raw))
+		}
+	}
+	_ = grpc.SetTrailer(ctx, md)
+
+	return status.Error(grpcCode(e.Code), e.Message)
+}
+
+// grpcCode is the status code matching an A2A error code
+func grpcCode(code ErrorCode) codes.Code {
+	switch code {
+	case ErrorTaskNotFound:
+		return codes.NotFound
+	case ErrorTaskCantCancel, ErrorInvalidTaskState:
+		return codes.FailedPrecondition
+	case ErrorParse, ErrorInvalidRequest, ErrorInvalidParams, ErrorIncompatibleContentType:
+		return codes.InvalidArgument
+	case ErrorMethodNotFound, ErrorUnsupportedOperation, ErrorPushNotificationNotSupported:
+		return codes.Unimplemented
+	case ErrorAuthenticationFailed:
+		return codes.Unauthenticated
+	case ErrorPermissionDenied:
+		return codes.PermissionDenied
+	case ErrorRateLimitExceeded:
+		return codes.ResourceExhausted
+	case ErrorServiceUnavailable:
+		return codes.Unavailable
+	case ErrorTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
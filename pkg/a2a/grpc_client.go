@@ -0,0 +1,547 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro-a2a/pkg/a2a/a2apb"
+	go_sse "github.com/tmaxmax/go-sse"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcReconnectDelay is how long GRPCClient waits before resuming a dropped stream
+const grpcReconnectDelay = time.Second
+
+// GRPCClient is a Client of the gRPC binding of agents served with the WithGRPC
+// option, the addresses it is given are gRPC targets, e.g. localhost:9090 or
+// grpc://localhost:9090, as listed among the additional interfaces of the
+// AgentCard. The responses and the events are those of A2AClient. It is safe for
+// concurrent use.
+type GRPCClient struct {
+	options ClientOptions
+	metrics *clientMetrics
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+var _ Client = (*GRPCClient)(nil)
+
+// NewGRPCClient creates a gRPC client, the connections to the agents are opened on
+// their first request and kept until Close
+//
+// Parameters:
+//   - opts: Optional ClientOption values, e.g. WithGRPCDialOptions
+//
+// Returns:
+//   - A pointer to a new GRPCClient instance ready for use
+func NewGRPCClient(opts ...ClientOption) *GRPCClient {
+	c := &GRPCClient{conns: make(map[string]*grpc.ClientConn)}
+
+	for _, o := range opts {
+		o(&c.options)
+	}
+
+	if c.options.Metrics != nil {
+		c.metrics = newClientMetrics(c.options.Metrics)
+	}
+
+	return c
+}
+
+// Close closes the connections to the agents
+func (c *GRPCClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for target, conn := range c.conns {
+		errs = append(errs, conn.Close())
+		delete(c.conns, target)
+	}
+
+	return errors.Join(errs...)
+}
+
+// service returns the A2AService of the agent at addr, over the connection to it
+func (c *GRPCClient) service(addr string) (a2apb.A2AServiceClient, error) {
+	target := strings.TrimPrefix(addr, "grpc://")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, ok := c.conns[target]; ok {
+		return a2apb.NewA2AServiceClient(conn), nil
+	}
+
+	// the options given replace the insecure credentials
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, c.options.GRPCDialOptions...)
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.conns[target] = conn
+
+	return a2apb.NewA2AServiceClient(conn), nil
+}
+
+// outgoing returns ctx carrying the trace context as the metadata of the calls, the
+// deadline of ctx is the deadline of the calls
+func (c *GRPCClient) outgoing(ctx context.Context) context.Context {
+	md := metadata.MD{}
+	for k, v := range c.options.headers(ctx) {
+		if k != DeadlineHeader {
+			md.Set(k, v)
+		}
+	}
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// FetchAgentCard fetches the AgentCard of the agent at addr over gRPC
+//
+// Returns:
+//   - AgentCard: The card published by the agent
+//   - error: An error if the request failed, or nil if successful
+func (c *GRPCClient) FetchAgentCard(ctx context.Context, addr string) (AgentCard, error) {
+	card := AgentCard{}
+
+	svc, err := c.service(addr)
+	if err != nil {
+		return card, NewError(ErrorInvalidRequest, err.Error(), nil)
+	}
+
+	res, err := svc.GetAgentCard(c.outgoing(ctx), &a2apb.GetAgentCardRequest{})
+	if err != nil {
+		return card, NewError(ErrorInternal, fmt.Sprintf("failed to fetch agent card: %v", err), nil)
+	}
+
+	raw, err := protoOut.Marshal(res)
+	if err != nil {
+		return card, NewError(ErrorInternal, err.Error(), nil)
+	}
+	if err := json.Unmarshal(raw, &card); err != nil {
+		return card, NewError(ErrorInternal, fmt.Sprintf("failed to fetch agent card: %v", err), nil)
+	}
+
+	return card, nil
+}
+
+// SendReq sends a request to the agent at addr over gRPC and returns its response,
+// like A2AClient.SendReq the errors the agent replied with are in the response
+//
+// Parameters:
+//   - ctx: Context for the request, its deadline is the deadline of the call
+//   - method: The A2A method to call (e.g., TasksSend, TasksGet)
+//   - params: The parameters for the method, must match the expected type for the method
+//   - addr: The gRPC target of the agent
+//
+// Returns:
+//   - JSONRPCResponse: The response from the agent
+//   - error: An error if the request failed, or nil if successful
+func (c *GRPCClient) SendReq(ctx context.Context, method Method, params Params, addr string) (JSONRPCResponse, error) {
+	if err := validateMethodParams(method, params); err != nil {
+		return JSONRPCResponse{}, NewError(ErrorInvalidRequest, err.Error(), nil)
+	}
+
+	req := JSONRPCRequest{
+		ID:      uuid.NewString(),
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+
+	ctx, span := c.options.startSpan(ctx, req)
+	defer span.End()
+
+	start := time.Now()
+	code := codeOK
+	defer func() { c.metrics.observeRequest(method, code, start) }()
+
+	res, err := c.call(c.outgoing(ctx), method, params, addr)
+	if err != nil {
+		if e, ok := a2aError(err, nil); ok {
+			recordError(span, e)
+			code = errorCode(e)
+			return JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Error: &e}, nil
+		}
+
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		code = "error"
+		return JSONRPCResponse{}, NewError(ErrorInternal, fmt.Sprintf("failed to send request: %v", err), nil)
+	}
+
+	result, err := decodeResult(method, res)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		code = "error"
+		return JSONRPCResponse{}, NewError(ErrorInternal, fmt.Sprintf("failed to read response: %v", err), nil)
+	}
+
+	return JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: result}, nil
+}
+
+// call sends the unary call of the method, the A2A errors come with the trailer of
+// the call
+func (c *GRPCClient) call(ctx context.Context, method Method, params Params, addr string) (proto.Message, error) {
+	svc, err := c.service(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var trailer metadata.MD
+	trailerOpt := grpc.Trailer(&trailer)
+
+	var res proto.Message
+	switch method {
+	case TasksSend:
+		req := &a2apb.SendTaskRequest{}
+		if err := protoParams(params, req); err != nil {
+			return nil, err
+		}
+		res, err = svc.SendTask(ctx, req, trailerOpt)
+	case TasksGet:
+		req := &a2apb.GetTaskRequest{}
+		if err := protoParams(params, req); err != nil {
+			return nil, err
+		}
+		res, err = svc.GetTask(ctx, req, trailerOpt)
+	case TasksCancel:
+		req := &a2apb.CancelTaskRequest{}
+		if err := protoParams(params, req); err != nil {
+			return nil, err
+		}
+		res, err = svc.CancelTask(ctx, req, trailerOpt)
+	case TasksList:
+		req := &a2apb.ListTasksRequest{}
+		if err := protoParams(params, req); err != nil {
+			return nil, err
+		}
+		res, err = svc.ListTasks(ctx, req, trailerOpt)
+	default:
+		e := NewError(ErrorInvalidRequest, fmt.Sprintf("%s responds with a stream, use SendReqStream", method), nil)
+		return nil, e
+	}
+
+	if err != nil {
+		if e, ok := a2aError(err, trailer); ok {
+			return nil, e
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// SendReqStream sends a streaming request to the agent at addr over gRPC and returns
+// the events of the task, as A2AClient.SendReqStream does. A dropped stream is
+// resumed after the last event received, see WithStreamReconnects.
+//
+// Parameters:
+//   - ctx: Context for the request, the stream ends once it is done
+//   - method: TasksSendSubscribe or TasksResubscribe
+//   - params: The parameters for the method, must match the expected type for the method
+//   - addr: The gRPC target of the agent
+//
+// Returns:
+//   - chan go_sse.Event: A channel receiving the events of the task, closed after the final event
+//   - error: An error if the request was refused or the stream couldn't be opened
+func (c *GRPCClient) SendReqStream(ctx context.Context, method Method, params Params, addr string) (chan go_sse.Event, error) {
+	if err := validateMethodParams(method, params); err != nil {
+		return nil, NewError(ErrorInvalidRequest, err.Error(), nil)
+	}
+	if method != TasksSendSubscribe && method != TasksResubscribe {
+		return nil, NewError(ErrorInvalidRequest, fmt.Sprintf("%s doesn't respond with a stream, use SendReq", method), nil)
+	}
+
+	resChan := make(chan go_sse.Event, 100)
+	id := uuid.NewString()
+
+	req := JSONRPCRequest{
+		ID:      id,
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+
+	ctx, span := c.options.startSpan(ctx, req)
+	defer span.End()
+
+	svc, err := c.service(addr)
+	if err != nil {
+		return resChan, NewError(ErrorInvalidRequest, err.Error(), nil)
+	}
+
+	start := time.Now()
+	stream, err := c.open(c.outgoing(ctx), svc, method, params, "")
+	if err != nil {
+		if e, ok := err.(JSONRPCError); ok {
+			recordError(span, e)
+			c.metrics.observeRequest(method, errorCode(e), start)
+			return resChan, e
+		}
+
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		c.metrics.observeRequest(method, "error", start)
+		return resChan, NewError(ErrorInternal, fmt.Sprintf("failed to establish a connection with [%v]: %v", addr, err), nil)
+	}
+
+	c.metrics.observeRequest(method, codeOK, start)
+
+	go c.receive(ctx, svc, stream, id, paramsTaskID(params), resChan)
+
+	return resChan, nil
+}
+
+// open opens the stream of the method, it returns once the agent accepted the
+// request, lastEventID resumes a tasks/resubscribe stream after it
+func (c *GRPCClient) open(ctx context.Context, svc a2apb.A2AServiceClient, method Method, params Params, lastEventID string) (grpc.ServerStreamingClient[a2apb.TaskEvent], error) {
+	var (
+		stream grpc.ServerStreamingClient[a2apb.TaskEvent]
+		err    error
+	)
+
+	switch method {
+	case TasksSendSubscribe:
+		req := &a2apb.SendTaskRequest{}
+		if err := protoParams(params, req); err != nil {
+			return nil, err
+		}
+		stream, err = svc.SendTaskSubscribe(ctx, req)
+	default:
+		req := &a2apb.ResubscribeTaskRequest{}
+		if err := protoParams(params, req); err != nil {
+			return nil, err
+		}
+		req.LastEventId = lastEventID
+		stream, err = svc.ResubscribeTask(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the agent sends the header once the task accepted the stream, the error of
+	// the call otherwise
+	header, err := stream.Header()
+	if err == nil && len(header.Get(grpcStreamOpenKey)) > 0 {
+		return stream, nil
+	}
+
+	if _, err = stream.Recv(); err == nil || err == io.EOF {
+		err = status.Error(codes.Unavailable, "the stream ended before it opened")
+	}
+	if e, ok := a2aError(err, stream.Trailer()); ok {
+		return nil, e
+	}
+	return nil, err
+}
+
+// receive reads the events of the stream into events until the final one, the
+// stream is resumed from the last event it received when it drops
+func (c *GRPCClient) receive(ctx context.Context, svc a2apb.A2AServiceClient, stream grpc.ServerStreamingClient[a2apb.TaskEvent], id, taskID string, events chan go_sse.Event) {
+	defer close(events)
+
+	reconnects := c.options.StreamReconnects
+	if reconnects == 0 {
+		reconnects = DefaultStreamReconnects
+	}
+
+	var (
+		last go_sse.Event
+		idle int
+	)
+
+	for {
+		msg, err := stream.Recv()
+		if err == nil {
+			idle = 0
+
+			e, taskEventID, err := sseEventOf(id, msg)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			if taskID == "" {
+				taskID = taskEventID
+			}
+			if duplicateEvent(last, e) {
+				continue
+			}
+			last = e
+
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+
+			if finalEvent(e.Data) {
+				return
+			}
+			continue
+		}
+
+		if err == io.EOF || ctx.Err() != nil {
+			return
+		}
+
+		// the agent replied with an error, it is the last event of the stream
+		if e, ok := a2aError(err, stream.Trailer()); ok {
+			raw, _ := json.Marshal(JSONRPCResponse{JSONRPC: "2.0", ID: id, Error: &e})
+			select {
+			case events <- go_sse.Event{Type: "message", Data: string(raw)}:
+			case <-ctx.Done():
+			}
+			return
+		}
+
+		// the stream dropped, it is resumed after the last event received
+		for {
+			idle++
+			if reconnects < 0 || idle > reconnects || taskID == "" {
+				log.Println(err)
+				return
+			}
+
+			select {
+			case <-time.After(grpcReconnectDelay):
+			case <-ctx.Done():
+				return
+			}
+
+			stream, err = c.open(c.outgoing(ctx), svc, TasksResubscribe, TaskIDParams{ID: taskID}, last.LastEventID)
+			if err == nil {
+				break
+			}
+			if e, ok := err.(JSONRPCError); ok {
+				log.Println(e)
+				return
+			}
+		}
+	}
+}
+
+// protoParams reads the params into the request message of their call
+func protoParams(params Params, req proto.Message) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return protoIn.Unmarshal(raw, req)
+}
+
+// decodeResult returns the result of the method carried by the response message
+func decodeResult(method Method, res proto.Message) (Result, error) {
+	raw, err := protoOut.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == TasksList {
+		var list TaskList
+		err := json.Unmarshal(raw, &list)
+		return list, err
+	}
+
+	var task Task
+	err = json.Unmarshal(raw, &task)
+	return task, err
+}
+
+// sseEventOf returns the event as A2AClient receives it, the response to the
+// request with the given ID, and the ID of its task
+func sseEventOf(id string, msg *a2apb.TaskEvent) (go_sse.Event, string, error) {
+	var (
+		result Result
+		taskID string
+	)
+
+	switch event := msg.GetEvent().(type) {
+	case *a2apb.TaskEvent_StatusUpdate:
+		raw, err := protoOut.Marshal(event.StatusUpdate)
+		if err != nil {
+			return go_sse.Event{}, "", err
+		}
+		var update TaskStatusUpdateEvent
+		if err := json.Unmarshal(raw, &update); err != nil {
+			return go_sse.Event{}, "", err
+		}
+		result, taskID = update, update.ID
+
+	case *a2apb.TaskEvent_ArtifactUpdate:
+		raw, err := protoOut.Marshal(event.ArtifactUpdate)
+		if err != nil {
+			return go_sse.Event{}, "", err
+		}
+		var update TaskArtifactUpdateEvent
+		if err := json.Unmarshal(raw, &update); err != nil {
+			return go_sse.Event{}, "", err
+		}
+		result, taskID = update, update.ID
+
+	default:
+		return go_sse.Event{}, "", fmt.Errorf("unknown task event %T", event)
+	}
+
+	raw, err := json.Marshal(JSONRPCResponse{JSONRPC: "2.0", ID: id, Result: result})
+	if err != nil {
+		return go_sse.Event{}, "", err
+	}
+
+	return go_sse.Event{LastEventID: msg.GetEventId(), Type: "message", Data: string(raw)}, taskID, nil
+}
+
+// a2aError returns the A2A error of a failed call, read from its trailer or mapped
+// from its status. The transport errors aren't A2A errors.
+func a2aError(err error, trailer metadata.MD) (JSONRPCError, bool) {
+	if e, ok := err.(JSONRPCError); ok {
+		return e, true
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return JSONRPCError{}, false
+	}
+
+	if v := trailer.Get(GRPCErrorCodeKey); len(v) > 0 {
+		if code, err := strconv.Atoi(v[0]); err == nil {
+			var data map[string]any
+			if d := trailer.Get(GRPCErrorDataKey); len(d) > 0 {
+				_ = json.Unmarshal([]byte(d[0]), &data)
+			}
+			return NewError(ErrorCode(code), st.Message(), data), true
+		}
+	}
+
+	switch st.Code() {
+	case codes.NotFound:
+		return NewError(ErrorTaskNotFound, st.Message(), nil), true
+	case codes.InvalidArgument:
+		return NewError(ErrorInvalidParams, st.Message(), nil), true
+	case codes.Unimplemented:
+		return NewError(ErrorUnsupportedOperation, st.Message(), nil), true
+	case codes.Unauthenticated:
+		return NewError(ErrorAuthenticationFailed, st.Message(), nil), true
+	case codes.PermissionDenied:
+		return NewError(ErrorPermissionDenied, st.Message(), nil), true
+	}
+
+	return JSONRPCError{}, false
+}
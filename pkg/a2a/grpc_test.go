@@ -0,0 +1,174 @@
+package a2a
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// freeAddress returns a local address nothing listens on
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	return lis.Addr().String()
+}
+
+func TestGRPCRoundTrip(t *testing.T) {
+	var calls atomic.Int32
+	count := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		calls.Add(1)
+		return handler(ctx, req)
+	}
+
+	addr := freeAddress(t)
+	card := AgentCard{
+		Name:         "Binary",
+		Capabilities: &AgentCapabilities{Streaming: true},
+		Skills:       []AgentSkill{{ID: "unary", Name: "Unary"}, {ID: "streaming", Name: "Streaming"}},
+	}
+	a := NewAgent(card,
+		WithSkillHandler("unary", completeHandler{}),
+		WithSkillStreamHandler("streaming", streamHandler{}),
+		WithGRPC(addr),
+		WithGRPCServerOptions(grpc.UnaryInterceptor(count)),
+	)
+	serveAgent(t, a)
+
+	c := NewGRPCClient()
+	defer c.Close()
+	ctx := context.Background()
+
+	fetched, err := c.FetchAgentCard(ctx, addr)
+	if err != nil || fetched.Name != "Binary" {
+		t.Fatalf("card = %+v, %v", fetched, err)
+	}
+
+	unary := textMessage("m1", "hi")
+	unary.Metadata = map[string]any{"skillId": "unary"}
+	res, err := c.SendReq(ctx, TasksSend, TaskSendParams{ID: "t1", Message: unary}, addr)
+	if err != nil || res.Error != nil {
+		t.Fatalf("tasks/send: %v %v", err, res.Error)
+	}
+	if task, ok := res.Result.(Task); !ok || task.ID != "t1" || task.Status.State != TaskStateCompleted {
+		t.Fatalf("tasks/send result = %+v, want t1 completed", res.Result)
+	}
+
+	res, err = c.SendReq(ctx, TasksGet, TaskQueryParams{ID: "missing"}, addr)
+	if err != nil || res.Error == nil || res.Error.Code != ErrorTaskNotFound {
+		t.Fatalf("tasks/get of a missing task: %v %+v, want ErrorTaskNotFound", err, res.Error)
+	}
+
+	streaming := textMessage("m2", "hi")
+	streaming.Metadata = map[string]any{"skillId": "streaming"}
+	events, err := c.SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t2", Message: streaming}, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := nextEvent(t, events, func(s streamed) bool { return s.text != "" }); s.text != "done" {
+		t.Fatalf("artifact %q, want done", s.text)
+	}
+	nextEvent(t, events, inState(TaskStateCompleted))
+
+	if calls.Load() == 0 {
+		t.Fatal("the server wasn't created with the options")
+	}
+}
+
+func TestGRPCAdvertised(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		url       string
+		listen    string
+		advertise string
+		want      string
+		fail      bool
+	}{
+		{name: "advertised", listen: ":9090", advertise: "agent.example.com:443", want: "agent.example.com:443"},
+		{name: "listen address with a host", url: "http://agent.example.com", listen: "10.0.0.1:9090", want: "10.0.0.1:9090"},
+		{name: "host of the card", url: "http://agent.example.com:8080/", listen: ":9090", want: "agent.example.com:9090"},
+		{name: "unspecified host", url: "http://agent.example.com", listen: "0.0.0.0:9090", want: "agent.example.com:9090"},
+		{name: "host of the machine", listen: "[::]:9090", want: net.JoinHostPort(hostname, "9090")},
+		{name: "port picked on start", listen: ":0", fail: true},
+		{name: "invalid", listen: "9090", fail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := grpcAdvertised(AgentCard{URL: tt.url}, tt.listen, tt.advertise)
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("grpcAdvertised = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("grpcAdvertised = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	a := NewAgent(AgentCard{Name: "Binary", URL: "http://agent.example.com"}, WithGRPC(":9090"))
+	interfaces := a.options.AgentCard.AdditionalInterfaces
+	if len(interfaces) != 1 || interfaces[0].URL != "agent.example.com:9090" || interfaces[0].Transport != TransportGRPC {
+		t.Fatalf("additional interfaces = %+v, want agent.example.com:9090", interfaces)
+	}
+}
+
+func TestGRPCStreamsAreAdmitted(t *testing.T) {
+	addr := freeAddress(t)
+	a := NewAgent(AgentCard{Name: "Binary", Capabilities: &AgentCapabilities{Streaming: true}},
+		WithAgentStreamHandler(streamHandler{}),
+		WithGRPC(addr),
+		WithRateLimit(0.01, 1),
+	)
+	serveAgent(t, a)
+
+	c := NewGRPCClient()
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// the events of the stream come with their position in the log of the task
+	events, err := c.SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")}, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last eventPos
+	n := 0
+	for e := range events {
+		pos, ok := parseEventID(e.LastEventID)
+		if !ok || !last.before(pos) {
+			t.Fatalf("event ID %q after %v", e.LastEventID, last)
+		}
+		last = pos
+		n++
+	}
+	if n == 0 {
+		t.Fatal("the stream had no events")
+	}
+	if task, err := a.loadTask("t1"); err != nil || task.Status.State != TaskStateCompleted {
+		t.Fatalf("task = %+v, %v, want it completed", task, err)
+	}
+
+	// the second one is over the rate limit
+	_, err = c.SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t2", Message: textMessage("m2", "hi")}, addr)
+	if e, ok := err.(JSONRPCError); !ok || e.Code != ErrorRateLimitExceeded {
+		t.Fatalf("err = %v, want ErrorRateLimitExceeded", err)
+	}
+}
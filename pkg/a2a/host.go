@@ -0,0 +1,143 @@
+package a2a
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	httpServer "github.com/micro/plugins/v5/server/http"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go-micro.dev/v5/logger"
+	"go-micro.dev/v5/registry"
+	"go-micro.dev/v5/server"
+)
+
+// AgentHost serves several Agents on one go-micro HTTP server. Every Agent keeps its
+// own AgentCard, handlers and store, it is served under its own path and publishes
+// its card at /<AgentName>/.well-known/agent.json. Each Agent is registered as an
+// endpoint of the host service carrying its AgentCard, see DiscoverAgents.
+//
+// An Agent created without the WithNamespace option keeps its records in the
+// namespace named after it once mounted.
+type AgentHost struct {
+	Server server.Server
+
+	options HostOptions
+	agents  []*Agent
+}
+
+type HostOptions struct {
+	Logger   logger.Logger
+	Registry registry.Registry
+	// Prometheus registry served at MetricsPath, nil serves no metrics
+	Metrics *prometheus.Registry
+}
+
+type HostOption func(ho *HostOptions)
+
+func WithHostLogger(logger logger.Logger) HostOption {
+	return func(ho *HostOptions) {
+		ho.Logger = logger
+	}
+}
+
+// WithHostRegistry sets the registry the host and its Agents are published to, it
+// defaults to the go-micro v5 default registry
+func WithHostRegistry(registry registry.Registry) HostOption {
+	return func(ho *HostOptions) {
+		ho.Registry = registry
+	}
+}
+
+// WithHostMetrics serves the metrics gathered by the Prometheus registry at
+// MetricsPath, the mounted Agents register their metrics with it when they are
+// created with WithMetrics(reg) using the same registry
+func WithHostMetrics(reg *prometheus.Registry) HostOption {
+	return func(ho *HostOptions) {
+		ho.Metrics = reg
+	}
+}
+
+// NewAgentHost creates a host listening on address and registered as the service name
+func NewAgentHost(name, address string, opts ...HostOption) *AgentHost {
+	host := &AgentHost{
+		Server: httpServer.NewServer(
+			server.Name(name),
+			server.Address(address),
+		),
+	}
+
+	for _, o := range opts {
+		o(&host.options)
+	}
+
+	// set the default logger
+	if host.options.Logger == nil {
+		host.options.Logger = logger.NewLogger()
+	}
+
+	// set the default registry
+	if host.options.Registry == nil {
+		host.options.Registry = registry.NewRegistry()
+	}
+
+	return host
+}
+
+// Mount adds Agents to the host, the names of the Agents must be unique as they are
+// the paths the Agents are served at and their default namespaces
+func (h *AgentHost) Mount(agents ...*Agent) error {
+	for _, a := range agents {
+		for _, m := range h.agents {
+			if m.name() == a.name() {
+				return fmt.Errorf("an agent named %q is already mounted", a.name())
+			}
+		}
+
+		if a.options.Namespace == "" {
+			a.options.Namespace = a.name()
+			a.options.Store = newNamespacedStore(a.options.Store, a.options.Namespace)
+		}
+		h.agents = append(h.agents, a)
+	}
+
+	return nil
+}
+
+// SwitchOn serves the mounted Agents and blocks until the service stops, the
+// Agents are shut down concurrently, each one within its own grace period
+func (h *AgentHost) SwitchOn() {
+	router := newRouter(h.options.Logger)
+
+	var opts []server.HandlerOption
+	var names []string
+	for _, a := range h.agents {
+		cardPath, err := url.JoinPath("/", a.name(), AgentCardPath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		paths := a.mount(router, cardPath)
+		opts = append(opts, server.EndpointMetadata(a.name(), cardMetadata(a.options.AgentCard, paths)))
+		names = append(names, a.name())
+	}
+
+	if h.options.Metrics != nil {
+		router.GET(MetricsPath, metricsHandler(h.options.Metrics))
+	}
+
+	if err := h.Server.Init(server.Metadata(map[string]string{"a2a.agents": strings.Join(names, ",")})); err != nil {
+		log.Fatalln(err)
+	}
+
+	hd := h.Server.NewHandler(router, opts...)
+	if err := h.Server.Handle(hd); err != nil {
+		log.Fatalln(err)
+	}
+
+	runService(h.Server, h.options.Logger, h.options.Registry, func() error {
+		return shutdownAll(h.agents)
+	})
+}
@@ -0,0 +1,30 @@
+package a2a
+
+import (
+	"testing"
+
+	"go-micro.dev/v5/store"
+)
+
+func TestMountKeepsAgentsApart(t *testing.T) {
+	shared := store.NewMemoryStore()
+	one := NewAgent(AgentCard{Name: "One"}, WithStore(shared))
+	two := NewAgent(AgentCard{Name: "Two"}, WithStore(shared))
+
+	host := NewAgentHost("host", ":0")
+	if err := host.Mount(one, two); err != nil {
+		t.Fatal(err)
+	}
+
+	task := &Task{ID: "t1", Status: TaskStatus{State: TaskStateCompleted}}
+	if err := one.saveTask(task); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := one.loadTask("t1"); err != nil {
+		t.Fatalf("the agent doesn't find its own task: %v", err)
+	}
+	if _, err := two.loadTask("t1"); err != store.ErrNotFound {
+		t.Fatalf("the other agent found the task: err = %v", err)
+	}
+}
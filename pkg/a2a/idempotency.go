@@ -0,0 +1,248 @@
+package a2a
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/logger"
+	"go-micro.dev/v5/store"
+)
+
+// DefaultIdempotencyWindow is how long the Agent remembers the tasks/send requests
+// it served, a retry within the window gets the outcome of the first request
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// submissions are kept in the Agent store next to the tasks
+const submissionKeyPrefix = "submission/"
+
+// submissionKey identifies a tasks/send request by the task it is for, the caller
+// and its message, or its JSON-RPC ID when the message has no ID, so callers can't
+// get the outcome of each other's requests. Requests that don't name their task
+// create a new one every time, they have no key.
+func submissionKey(params TaskSendParams, reqID any, caller string) string {
+	taskID := params.ID
+	if taskID == "" {
+		taskID = params.Message.TaskId
+	}
+
+	id := params.Message.MessageId
+	if id == "" && reqID != nil {
+		id = fmt.Sprintf("%v", reqID)
+	}
+
+	if taskID == "" || id == "" {
+		return ""
+	}
+	return submissionKeyPrefix + taskID + "/" + url.PathEscape(caller) + "/" + url.PathEscape(id)
+}
+
+// submissionDigest fingerprints the params of a tasks/send request, a retry
+// carries the same params
+func submissionDigest(params TaskSendParams) (string, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// submission is what the Agent remembers of a tasks/send request, the response is
+// set once the handler returned or the task was interrupted
+type submission struct {
+	TaskID   string           `json:"taskId"`
+	Digest   string           `json:"digest,omitempty"`
+	Response *JSONRPCResponse `json:"response,omitempty"`
+}
+
+func (a *Agent) idempotencyWindow() time.Duration {
+	if a.options.IdempotencyWindow > 0 {
+		return a.options.IdempotencyWindow
+	}
+	return DefaultIdempotencyWindow
+}
+
+// claimSubmission returns the submission stored under the key, or stores a pending
+// one and returns nil when the request is new. A request reusing the key of another
+// one with different params is rejected with ErrorInvalidParams.
+func (a *Agent) claimSubmission(key string, params TaskSendParams) (*submission, error) {
+	digest, err := submissionDigest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	a.submissionsMu.Lock()
+	defer a.submissionsMu.Unlock()
+
+	s, err := a.readSubmission(key)
+	if err == nil {
+		if s.Digest != digest {
+			return nil, NewError(ErrorInvalidParams, "the message ID was used for a different request", map[string]any{"id": s.TaskID, "messageId": params.Message.MessageId})
+		}
+		return s, nil
+	}
+	if err != store.ErrNotFound {
+		return nil, err
+	}
+
+	return nil, a.writeSubmission(key, submission{TaskID: paramsTaskID(params), Digest: digest})
+}
+
+func (a *Agent) readSubmission(key string) (*submission, error) {
+	records, err := a.options.Store.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	var s submission
+	if err := json.Unmarshal(records[0].Value, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (a *Agent) writeSubmission(key string, s submission) error {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return a.options.Store.Write(&store.Record{
+		Key:    key,
+		Value:  raw,
+		Expiry: a.idempotencyWindow(),
+	})
+}
+
+// releaseSubmission forgets a request that was not accepted so it can be retried
+func (a *Agent) releaseSubmission(key string) {
+	if key == "" {
+		return
+	}
+
+	if err := a.options.Store.Delete(key); err != nil && err != store.ErrNotFound {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	}
+}
+
+// completeSubmissions stores the outcome of the run for every request it served
+func (a *Agent) completeSubmissions(run *taskRun) {
+	run.mu.Lock()
+	keys := run.submissions
+	run.mu.Unlock()
+
+	if len(keys) == 0 {
+		return
+	}
+
+	res, err := run.response(nil)
+	if err != nil {
+		return
+	}
+
+	for _, key := range keys {
+		a.completeSubmission(key, run.taskID, res)
+	}
+}
+
+// completeSubmission stores the outcome of the request stored under the key, a
+// retry gets it from then on
+func (a *Agent) completeSubmission(key, taskID string, res JSONRPCResponse) {
+	if key == "" {
+		return
+	}
+
+	a.submissionsMu.Lock()
+	defer a.submissionsMu.Unlock()
+
+	s, err := a.readSubmission(key)
+	if err != nil && err != store.ErrNotFound {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		return
+	}
+	if s == nil {
+		s = &submission{TaskID: taskID}
+	}
+	s.Response = &res
+
+	if err := a.writeSubmission(key, *s); err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	}
+}
+
+// replaySubmission answers a retried request: with the outcome of the first one
+// when it is known, by waiting for the handler still serving it otherwise
+func (a *Agent) replaySubmission(c *gin.Context, key string, s *submission, reqID any) {
+	if s.Response != nil {
+		res := *s.Response
+		res.ID = reqID
+		a.respond(c, res)
+		return
+	}
+
+	if run, ok := a.liveRun(s.TaskID); ok && run.serves(key) {
+		if !run.isWaiting() {
+			run.wait(c.Request.Context())
+		}
+		a.reply(c, run, reqID)
+		return
+	}
+
+	// the Agent stopped before the handler returned, the task is what it left
+	task, err := a.loadTask(s.TaskID)
+	if err == store.ErrNotFound {
+		e := NewError(ErrorTaskNotFound, "task not found", map[string]any{"id": s.TaskID})
+		c.JSON(http.StatusNotFound, e)
+		return
+	}
+	if err != nil {
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, JSONRPCResponse{JSONRPC: "2.0", ID: reqID, Result: task})
+}
+
+// serve records that the run answers the request stored under the key
+func (run *taskRun) serve(key string) {
+	if key == "" {
+		return
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	run.submissions = append(run.submissions, key)
+}
+
+// serves reports whether the run answers the request stored under the key
+func (run *taskRun) serves(key string) bool {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	for _, k := range run.submissions {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// isWaiting reports whether the handler is paused waiting for a follow-up message
+func (run *taskRun) isWaiting() bool {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	return run.waiting
+}
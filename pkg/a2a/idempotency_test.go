@@ -0,0 +1,108 @@
+package a2a
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// countingHandler completes every task it is sent and counts its calls
+type countingHandler struct {
+	calls *atomic.Int32
+}
+
+func (h countingHandler) TaskHandler(req JSONRPCRequest) JSONRPCResponse {
+	h.calls.Add(1)
+	return completeHandler{}.TaskHandler(req)
+}
+
+func TestRetriedSendIsReplayed(t *testing.T) {
+	h := countingHandler{calls: new(atomic.Int32)}
+	srv, paths := serveAgent(t, NewAgent(AgentCard{Name: "Once"}, WithAgentHandler(h)))
+	url := srv.URL + paths.RPC
+
+	params := TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")}
+	for i := 0; i < 3; i++ {
+		status, reply := postAs(t, url, "", TasksSend, params)
+		if status != http.StatusOK {
+			t.Fatalf("attempt %d: %d %v", i, status, reply)
+		}
+
+		result, _ := reply["result"].(map[string]any)
+		state, _ := result["status"].(map[string]any)
+		if result["id"] != "t1" || state["state"] != string(TaskStateCompleted) {
+			t.Fatalf("attempt %d replied %v, want the completed task", i, reply)
+		}
+	}
+
+	if n := h.calls.Load(); n != 1 {
+		t.Fatalf("the handler was called %d times, want once", n)
+	}
+}
+
+func TestReusedMessageIDIsRejected(t *testing.T) {
+	h := countingHandler{calls: new(atomic.Int32)}
+	srv, paths := serveAgent(t, NewAgent(AgentCard{Name: "Once"}, WithAgentHandler(h)))
+	url := srv.URL + paths.RPC
+
+	if status, reply := postAs(t, url, "", TasksSend, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")}); status != http.StatusOK {
+		t.Fatalf("tasks/send: %d %v", status, reply)
+	}
+
+	status, reply := postAs(t, url, "", TasksSend, TaskSendParams{ID: "t1", Message: textMessage("m1", "something else")})
+	if status != http.StatusBadRequest || reply["code"] != float64(ErrorInvalidParams) {
+		t.Fatalf("reply = %d %v, want 400 with ErrorInvalidParams", status, reply)
+	}
+
+	if n := h.calls.Load(); n != 1 {
+		t.Fatalf("the handler was called %d times, want once", n)
+	}
+}
+
+func TestSubmissionsAreIsolatedByCaller(t *testing.T) {
+	h := countingHandler{calls: new(atomic.Int32)}
+	srv, paths := serveAgent(t, NewAgent(AgentCard{Name: "Once"}, WithAgentHandler(h), WithCaller(headerCaller)))
+	url := srv.URL + paths.RPC
+
+	params := TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")}
+	if status, reply := postAs(t, url, "alice", TasksSend, params); status != http.StatusOK {
+		t.Fatalf("tasks/send: %d %v", status, reply)
+	}
+
+	// the same request of another caller isn't a retry, it doesn't get the task of
+	// alice but finds it completed
+	status, reply := postAs(t, url, "bob", TasksSend, params)
+	if status != http.StatusBadRequest || reply["code"] != float64(ErrorInvalidTaskState) {
+		t.Fatalf("reply = %d %v, want 400 with ErrorInvalidTaskState", status, reply)
+	}
+
+	// alice still gets hers
+	if status, reply := postAs(t, url, "alice", TasksSend, params); status != http.StatusOK {
+		t.Fatalf("retry: %d %v", status, reply)
+	}
+}
+
+func TestSubmissionKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		params TaskSendParams
+		reqID  any
+		caller string
+		want   string
+	}{
+		{name: "message ID", params: TaskSendParams{ID: "t1", Message: Message{MessageId: "m1"}}, reqID: 1, caller: "alice", want: "submission/t1/alice/m1"},
+		{name: "task ID of the message", params: TaskSendParams{Message: Message{TaskId: "t1", MessageId: "m1"}}, caller: "alice", want: "submission/t1/alice/m1"},
+		{name: "request ID", params: TaskSendParams{ID: "t1"}, reqID: 7, caller: "alice", want: "submission/t1/alice/7"},
+		{name: "escaped", params: TaskSendParams{ID: "t1", Message: Message{MessageId: "a/b"}}, caller: "user/1", want: "submission/t1/user%2F1/a%2Fb"},
+		{name: "no task", params: TaskSendParams{Message: Message{MessageId: "m1"}}, caller: "alice"},
+		{name: "no ID", params: TaskSendParams{ID: "t1"}, caller: "alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := submissionKey(tt.params, tt.reqID, tt.caller); got != tt.want {
+				t.Fatalf("submissionKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
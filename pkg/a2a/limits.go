@@ -0,0 +1,297 @@
+package a2a
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/logger"
+	"go-micro.dev/v5/store"
+)
+
+// RateLimit is a token bucket, a caller may send Burst requests at once and Rate
+// requests per second on average
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// CallerFunc identifies who sent a request, every caller has its own rate limits
+// and quotas
+type CallerFunc func(r *http.Request) string
+
+// DefaultCaller identifies callers by their remote IP. The Agent doesn't verify
+// credentials, a CallerFunc telling callers apart by their credentials, see
+// WithCaller, must verify them: a caller choosing its identity escapes its limits.
+func DefaultCaller(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// quotas are counted per caller and per UTC day in the Agent store
+const quotaKeyPrefix = "quota/"
+
+func quotaKey(scope, caller string, day time.Time) string {
+	return fmt.Sprintf("%s%s/%s/%s", quotaKeyPrefix, day.Format(time.DateOnly), scope, caller)
+}
+
+// limitScope is what a limit applies to: every request, a method or a skill
+type limitScope struct {
+	name  string
+	rate  *RateLimit
+	quota int
+}
+
+// idle buckets are dropped once in a while so one-off callers don't pile up
+const bucketSweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter applies the rate limits and the daily quotas of an Agent
+type limiter struct {
+	agent *Agent
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+
+	quotaMu sync.Mutex
+}
+
+func newLimiter(a *Agent) *limiter {
+	return &limiter{
+		agent:   a,
+		buckets: make(map[string]*bucket),
+		swept:   time.Now(),
+	}
+}
+
+// requestScopes are the limits applying to every request of the method
+func (a *Agent) requestScopes(method Method) []limitScope {
+	o := a.options
+
+	var scopes []limitScope
+	if o.RateLimit != nil || o.DailyQuota > 0 {
+		scopes = append(scopes, limitScope{name: "agent", rate: o.RateLimit, quota: o.DailyQuota})
+	}
+
+	rl, hasRate := o.MethodRateLimits[method]
+	quota := o.MethodDailyQuotas[method]
+	if hasRate || quota > 0 {
+		s := limitScope{name: "method:" + string(method), quota: quota}
+		if hasRate {
+			s.rate = &rl
+		}
+		scopes = append(scopes, s)
+	}
+
+	return scopes
+}
+
+// skillScopes are the limits applying to the tasks routed to the skill
+func (a *Agent) skillScopes(skillID string) []limitScope {
+	rl, hasRate := a.options.SkillRateLimits[skillID]
+	quota := a.options.SkillDailyQuotas[skillID]
+	if skillID == "" || (!hasRate && quota <= 0) {
+		return nil
+	}
+
+	s := limitScope{name: "skill:" + skillID, quota: quota}
+	if hasRate {
+		s.rate = &rl
+	}
+	return []limitScope{s}
+}
+
+// taskScopes are the limits applying to a task sent with the method and routed to
+// the skill, the ones of the request and the ones of the skill
+func (a *Agent) taskScopes(method Method, skillID string) []limitScope {
+	return append(a.requestScopes(method), a.skillScopes(skillID)...)
+}
+
+// admit applies the limits of the scopes to the caller of the request. A rejected
+// request is answered with ErrorRateLimitExceeded and a Retry-After header, or with
+// ErrorServiceUnavailable when its quota can't be checked, the caller should not
+// reply any further.
+func (a *Agent) admit(c *gin.Context, scopes []limitScope) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+
+	retryAfter, scope, ok, err := a.limiter.admit(a.caller(c.Request), scopes, time.Now())
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		e := NewError(ErrorServiceUnavailable, "the quota of the caller can't be checked, try again later", map[string]any{"scope": scope})
+		c.JSON(http.StatusServiceUnavailable, e)
+		return false
+	}
+	if ok {
+		return true
+	}
+
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	e := NewError(ErrorRateLimitExceeded, "rate limit exceeded", map[string]any{
+		"retryAfter": seconds,
+		"scope":      scope,
+	})
+	c.JSON(http.StatusTooManyRequests, e)
+
+	return false
+}
+
+// admit takes a token from the bucket of every scope and counts the request in
+// every quota. Every limit is checked before any is used, a rejected request uses
+// neither tokens nor quota, the rejecting scope is returned along with how long
+// the caller should wait. A quota that can't be checked rejects the request with
+// an error.
+func (l *limiter) admit(caller string, scopes []limitScope, now time.Time) (time.Duration, string, bool, error) {
+	l.quotaMu.Lock()
+	defer l.quotaMu.Unlock()
+
+	counters, wait, scope, ok, err := l.check(caller, scopes, now)
+	if err != nil || !ok {
+		return wait, scope, false, err
+	}
+
+	if wait, scope, ok := l.take(caller, scopes, now); !ok {
+		return wait, scope, false, nil
+	}
+
+	if err := l.count(counters, now); err != nil {
+		return 0, "", false, err
+	}
+
+	return 0, "", true, nil
+}
+
+func (l *limiter) take(caller string, scopes []limitScope, now time.Time) (time.Duration, string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	// refill every bucket first so a rejection leaves the others untouched
+	taken := make([]*bucket, 0, len(scopes))
+	for _, s := range scopes {
+		if s.rate == nil || s.rate.Rate <= 0 {
+			continue
+		}
+
+		capacity := float64(max(s.rate.Burst, 1))
+
+		key := s.name + "/" + caller
+		b, ok := l.buckets[key]
+		if !ok {
+			b = &bucket{tokens: capacity, last: now}
+			l.buckets[key] = b
+		}
+
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*s.rate.Rate)
+		b.last = now
+
+		if b.tokens < 1 {
+			wait := time.Duration((1 - b.tokens) / s.rate.Rate * float64(time.Second))
+			return wait, s.name, false
+		}
+		taken = append(taken, b)
+	}
+
+	for _, b := range taken {
+		b.tokens--
+	}
+
+	return 0, "", true
+}
+
+// sweep drops the buckets that refilled completely, they are the same as new ones
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < bucketSweepInterval {
+		return
+	}
+	l.swept = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= bucketSweepInterval {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// quotaCounter is the number of requests a caller sent today in a quota scope
+type quotaCounter struct {
+	key  string
+	used int
+}
+
+// check reads the daily quota counters of the caller, it returns them unless one
+// of the quotas is used up. It must be called with quotaMu held.
+func (l *limiter) check(caller string, scopes []limitScope, now time.Time) ([]quotaCounter, time.Duration, string, bool, error) {
+	day := now.UTC().Truncate(24 * time.Hour)
+
+	var counters []quotaCounter
+	for _, s := range scopes {
+		if s.quota <= 0 {
+			continue
+		}
+
+		key := quotaKey(s.name, caller, day)
+		used, err := l.used(key)
+		if err != nil {
+			return nil, 0, s.name, false, fmt.Errorf("quota counter %s: %w", key, err)
+		}
+
+		if used >= s.quota {
+			return nil, day.Add(24 * time.Hour).Sub(now), s.name, false, nil
+		}
+		counters = append(counters, quotaCounter{key: key, used: used})
+	}
+
+	return counters, 0, "", true, nil
+}
+
+// count counts the request in the quota counters returned by check, they are kept
+// in the store until the day is over. It must be called with quotaMu held.
+func (l *limiter) count(counters []quotaCounter, now time.Time) error {
+	untilTomorrow := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+
+	for _, q := range counters {
+		err := l.agent.options.Store.Write(&store.Record{
+			Key:    q.key,
+			Value:  []byte(strconv.Itoa(q.used + 1)),
+			Expiry: untilTomorrow + time.Hour,
+		})
+		if err != nil {
+			return fmt.Errorf("quota counter %s: %w", q.key, err)
+		}
+	}
+
+	return nil
+}
+
+func (l *limiter) used(key string) (int, error) {
+	records, err := l.agent.options.Store.Read(key)
+	if err == store.ErrNotFound || (err == nil && len(records) == 0) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(string(records[0].Value))
+}
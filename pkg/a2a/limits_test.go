@@ -0,0 +1,176 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-micro.dev/v5/store"
+)
+
+func TestDefaultCallerIgnoresCredentials(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.RemoteAddr = "10.0.0.1:4242"
+
+	want := DefaultCaller(r)
+	for _, h := range []string{"Authorization", "X-API-Key"} {
+		r.Header.Set(h, "random-"+h)
+		if got := DefaultCaller(r); got != want {
+			t.Fatalf("with %s the caller is %q, want %q", h, got, want)
+		}
+	}
+}
+
+func TestQuotaRejectsUnreadableCounters(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Limited"}, WithDailyQuota(10))
+	scopes := a.requestScopes(TasksGet)
+	now := time.Now()
+
+	if _, _, ok, err := a.limiter.admit("ip:10.0.0.1", scopes, now); !ok || err != nil {
+		t.Fatalf("first request: ok = %v, err = %v", ok, err)
+	}
+
+	key := quotaKey("agent", "ip:10.0.0.1", now.UTC().Truncate(24*time.Hour))
+	if err := a.options.Store.Write(&store.Record{Key: key, Value: []byte(`{"not":"a counter"}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, ok, err := a.limiter.admit("ip:10.0.0.1", scopes, now); ok || err == nil {
+		t.Fatalf("with a corrupted counter: ok = %v, err = %v, want the request rejected", ok, err)
+	}
+}
+
+func TestStreamRequestIDsCantReachOtherKeys(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Streaming", Capabilities: &AgentCapabilities{Streaming: true}}, WithAgentStreamHandler(streamHandler{}), WithDailyQuota(10))
+	srv, paths := serveAgent(t, a)
+
+	day := time.Now().UTC().Format(time.DateOnly)
+	for _, id := range []string{fmt.Sprintf("quota/%s/agent/ip:127.0.0.1", day), "a/b"} {
+		body, _ := json.Marshal(JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: TasksSendSubscribe, Params: TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")}})
+		res, err := http.Post(srv.URL+paths.Stream, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("a stream request with the ID %q got %d, want 400", id, res.StatusCode)
+		}
+	}
+
+	// the counter still counts
+	c := NewA2AClient()
+	res, err := c.SendReq(context.Background(), TasksGet, TaskQueryParams{ID: "t1"}, srv.URL+paths.RPC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Error != nil && res.Error.Code == ErrorServiceUnavailable {
+		t.Fatalf("the quota counter was overwritten: %v", res.Error)
+	}
+}
+
+func TestRejectedTasksDontUseTheQuota(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Limited", Skills: []AgentSkill{{ID: "translate"}}}, WithAgentHandler(completeHandler{}), WithDailyQuota(2), WithSkillDailyQuota("translate", 1))
+	srv, paths := serveAgent(t, a)
+	url := srv.URL + paths.RPC
+
+	send := func(id, skillID string) (int, map[string]any) {
+		msg := textMessage(id, "hi")
+		if skillID != "" {
+			msg.Metadata = map[string]any{"skillId": skillID}
+		}
+		return postAs(t, url, "", TasksSend, TaskSendParams{ID: id, Message: msg})
+	}
+
+	if status, reply := send("t1", "translate"); status != http.StatusOK {
+		t.Fatalf("first task: %d %v", status, reply)
+	}
+
+	// the skill quota rejects the second task, the agent quota isn't used
+	status, reply := send("t2", "translate")
+	if status != http.StatusTooManyRequests {
+		t.Fatalf("second task: %d %v, want 429", status, reply)
+	}
+	if data, _ := reply["data"].(map[string]any); data["scope"] != "skill:translate" {
+		t.Fatalf("rejected by %v, want the skill quota", reply["data"])
+	}
+
+	if status, reply := send("t3", ""); status != http.StatusOK {
+		t.Fatalf("task of no skill: %d %v, the rejected task used the agent quota", status, reply)
+	}
+
+	if status, reply := send("t4", ""); status != http.StatusTooManyRequests {
+		t.Fatalf("task over the agent quota: %d %v, want 429", status, reply)
+	}
+}
+
+func TestRateLimitedRequestsGetRetryAfter(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Limited"}, WithAgentHandler(completeHandler{}), WithRateLimit(0.5, 1))
+	srv, paths := serveAgent(t, a)
+
+	body, _ := json.Marshal(JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: TasksGet, Params: TaskQueryParams{ID: "t1"}})
+	post := func() *http.Response {
+		res, err := http.Post(srv.URL+paths.RPC, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	post().Body.Close()
+
+	res := post()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", res.StatusCode)
+	}
+	if h := res.Header.Get("Retry-After"); h != "2" {
+		t.Fatalf("Retry-After = %q, want 2", h)
+	}
+
+	var e JSONRPCError
+	if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Code != ErrorRateLimitExceeded || e.Data["retryAfter"] != float64(2) {
+		t.Fatalf("error = %+v, want ErrorRateLimitExceeded with retryAfter 2", e)
+	}
+}
+
+func TestClientHonorsRetryAfter(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Limited"}, WithAgentHandler(completeHandler{}), WithRateLimit(1, 1))
+	srv, paths := serveAgent(t, a)
+	url := srv.URL + paths.RPC
+
+	send := func(c *A2AClient, id string) JSONRPCResponse {
+		t.Helper()
+
+		res, err := c.SendReq(context.Background(), TasksSend, TaskSendParams{ID: id, Message: textMessage(id, "hi")}, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	// without retries the rejection is replied as is
+	send(NewA2AClient(), "t1")
+	if res := send(NewA2AClient(WithRateLimitRetries(-1)), "t2"); res.Result != nil {
+		t.Fatalf("the request over the rate limit got %v", res.Result)
+	}
+
+	// with them the client waits for the second the agent asked for
+	start := time.Now()
+	res := send(NewA2AClient(), "t3")
+	if task, ok := res.Result.(Task); !ok || task.Status.State != TaskStateCompleted {
+		t.Fatalf("result = %v, want the completed task", res.Result)
+	}
+	if waited := time.Since(start); waited < 500*time.Millisecond {
+		t.Fatalf("the client retried after %v, before Retry-After", waited)
+	}
+}
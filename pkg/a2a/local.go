@@ -0,0 +1,92 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The WebSocket sessions and the gRPC binding don't speak HTTP to their clients,
+// they serve every request they get as a local HTTP request on routes of their own,
+// see Agent.local. The requests thus go through the same middlewares, admission and
+// handlers as the JSON-RPC ones. The events of their streams aren't written as SSE,
+// they are handed to the session or the call as they come, see withStreamSink.
+
+// localRouter returns the routes the local requests of the Agent are served on, nil
+// when neither the WebSocket endpoint nor the gRPC binding is enabled
+func (a *Agent) localRouter(paths agentPaths) *gin.Engine {
+	if !a.options.WebSocket && a.options.GRPCAddress == "" {
+		return nil
+	}
+
+	router := gin.New()
+	router.Use(recovery(a.options.Logger))
+	a.routes(router, paths)
+
+	return router
+}
+
+// localRequest builds a local request, it is done with ctx
+func localRequest(ctx context.Context, method, target string, body []byte, header http.Header) *http.Request {
+	r, _ := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+
+	r.Header = header
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	return r
+}
+
+// streamSink takes the events of a stream opened by a local request, an error ends
+// the stream
+type streamSink func(JSONRPCResponse) error
+
+type streamSinkKey struct{}
+
+// withStreamSink returns the context of a local request whose stream events go to
+// the sink instead of its response
+func withStreamSink(ctx context.Context, sink streamSink) context.Context {
+	return context.WithValue(ctx, streamSinkKey{}, sink)
+}
+
+// streamSinkFrom returns the sink of the stream events of the request, if any
+func streamSinkFrom(ctx context.Context) (streamSink, bool) {
+	sink, ok := ctx.Value(streamSinkKey{}).(streamSink)
+	return sink, ok
+}
+
+// frameWriter is the http.ResponseWriter of the local requests, it keeps what the
+// handler replied
+type frameWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *frameWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
+}
+
+func (w *frameWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *frameWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+// Flush is called by the streams that fail before they are handed to their sink
+func (w *frameWriter) Flush() {}
+
+func (w *frameWriter) failed() bool {
+	return w.status >= http.StatusBadRequest
+}
@@ -0,0 +1,156 @@
+package a2a
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/logger"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Redaction picks what is hidden from the payloads logged with WithPayloadLogging
+type Redaction struct {
+	// text of TextParts and data of DataParts
+	Parts bool
+	// base64 content of FileParts
+	FileBytes bool
+	// PushNotificationConfig.Token
+	PushTokens bool
+	// credentials of AuthenticationInfo and AuthConfig
+	Credentials bool
+}
+
+// DefaultRedaction hides everything a payload may carry that isn't metadata
+var DefaultRedaction = Redaction{Parts: true, FileBytes: true, PushTokens: true, Credentials: true}
+
+// redacted replaces the values hidden from the logs
+const redacted = "[REDACTED]"
+
+// redact returns the JSON of v with the values picked by the redaction hidden. It
+// works on the JSON so the payloads of every method and event are covered.
+func (r Redaction) redact(v any) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err.Error()
+	}
+
+	var tree any
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return err.Error()
+	}
+
+	out, err := json.Marshal(r.walk(tree))
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
+
+// walk hides the values picked by the redaction in the decoded JSON, the keys are
+// matched regardless of case as they are when the JSON is decoded
+func (r Redaction) walk(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		kind, _ := v["kind"].(string)
+		for k, child := range v {
+			switch {
+			case r.Parts && kind == string(PartTypeText) && strings.EqualFold(k, "text"),
+				r.Parts && kind == string(PartTypeData) && strings.EqualFold(k, "data"),
+				r.FileBytes && strings.EqualFold(k, "bytes"),
+				r.PushTokens && strings.EqualFold(k, "token"),
+				r.Credentials && strings.EqualFold(k, "credentials"):
+				v[k] = redacted
+			default:
+				v[k] = r.walk(child)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = r.walk(child)
+		}
+	}
+	return v
+}
+
+// logPayload logs a request or an event of the Agent at Debug level, redacted, when
+// payload logging is on
+func (a *Agent) logPayload(msg string, v any) {
+	if !a.options.LogPayloads {
+		return
+	}
+
+	redaction := DefaultRedaction
+	if a.options.Redaction != nil {
+		redaction = *a.options.Redaction
+	}
+
+	a.options.Logger.Log(logger.DebugLevel, msg+": "+redaction.redact(v))
+}
+
+// caller identifies who sent the request
+func (a *Agent) caller(r *http.Request) string {
+	if a.options.Caller == nil {
+		return DefaultCaller(r)
+	}
+	return a.options.Caller(r)
+}
+
+// logFieldsKey is the gin context key the attributes of the request span are
+// collected under for the request log
+const logFieldsKey = "logFields"
+
+// logFieldNames are the span attributes copied to the request log and their names
+var logFieldNames = map[attribute.Key]string{
+	semconv.RPCJsonrpcRequestIDKey: "requestId",
+	semconv.RPCMethodKey:           "method",
+	AttributeTaskID:                "taskId",
+	AttributeSkillID:               "skill",
+}
+
+// collectLogFields keeps the attributes the request is annotated with that belong
+// in the request log
+func collectLogFields(c *gin.Context, attrs []attribute.KeyValue) {
+	fields, _ := c.Get(logFieldsKey)
+	m, _ := fields.(map[string]any)
+
+	for _, attr := range attrs {
+		name, ok := logFieldNames[attr.Key]
+		if !ok {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]any)
+			c.Set(logFieldsKey, m)
+		}
+		m[name] = attr.Value.Emit()
+	}
+}
+
+// logRequest logs one line per request served, with the fields collected while
+// serving it but none of its payload
+func (a *Agent) logRequest(c *gin.Context, code string, start time.Time) {
+	fields := map[string]any{
+		"path":     c.FullPath(),
+		"caller":   a.caller(c.Request),
+		"status":   c.Writer.Status(),
+		"code":     code,
+		"duration": time.Since(start).String(),
+	}
+
+	if collected, ok := c.Get(logFieldsKey); ok {
+		for k, v := range collected.(map[string]any) {
+			fields[k] = v
+		}
+	}
+
+	level := logger.InfoLevel
+	if c.Writer.Status() >= http.StatusInternalServerError {
+		level = logger.ErrorLevel
+	}
+
+	a.options.Logger.Fields(fields).Log(level, "request served")
+}
@@ -0,0 +1,105 @@
+package a2a
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name      string
+		redaction Redaction
+		v         any
+		want      string
+	}{
+		{
+			name:      "text part",
+			redaction: DefaultRedaction,
+			v:         TextPart{Kind: PartTypeText, Text: "secret"},
+			want:      `{"kind":"text","text":"[REDACTED]"}`,
+		},
+		{
+			name:      "text part kept",
+			redaction: Redaction{},
+			v:         TextPart{Kind: PartTypeText, Text: "hello"},
+			want:      `{"kind":"text","text":"hello"}`,
+		},
+		{
+			name:      "text outside of a text part",
+			redaction: DefaultRedaction,
+			v:         map[string]any{"kind": "note", "text": "hello"},
+			want:      `{"kind":"note","text":"hello"}`,
+		},
+		{
+			name:      "nested objects",
+			redaction: DefaultRedaction,
+			v: map[string]any{"params": map[string]any{
+				"pushNotification": map[string]any{"url": "https://hook", "token": "secret", "authentication": map[string]any{"schemes": []any{"Bearer"}, "credentials": "secret"}},
+			}},
+			want: `{"params":{"pushNotification":{"authentication":{"credentials":"[REDACTED]","schemes":["Bearer"]},"token":"[REDACTED]","url":"https://hook"}}}`,
+		},
+		{
+			name:      "arrays",
+			redaction: DefaultRedaction,
+			v: []any{
+				map[string]any{"kind": "text", "text": "secret"},
+				map[string]any{"kind": "data", "data": map[string]any{"card": "secret"}},
+				map[string]any{"kind": "file", "file": map[string]any{"name": "a.txt", "bytes": "c2VjcmV0"}},
+			},
+			want: `[{"kind":"text","text":"[REDACTED]"},{"data":"[REDACTED]","kind":"data"},{"file":{"bytes":"[REDACTED]","name":"a.txt"},"kind":"file"}]`,
+		},
+		{
+			name:      "only the picked values",
+			redaction: Redaction{PushTokens: true},
+			v:         map[string]any{"token": "secret", "credentials": "kept", "parts": []any{map[string]any{"kind": "text", "text": "kept"}}},
+			want:      `{"credentials":"kept","parts":[{"kind":"text","text":"kept"}],"token":"[REDACTED]"}`,
+		},
+		{
+			name:      "keys that differ in case",
+			redaction: DefaultRedaction,
+			v:         map[string]any{"Token": "secret", "CREDENTIALS": "secret", "Bytes": "c2VjcmV0", "part": map[string]any{"kind": "text", "Text": "secret"}},
+			want:      `{"Bytes":"[REDACTED]","CREDENTIALS":"[REDACTED]","Token":"[REDACTED]","part":{"Text":"[REDACTED]","kind":"text"}}`,
+		},
+		{
+			name:      "not an object",
+			redaction: DefaultRedaction,
+			v:         "token",
+			want:      `"token"`,
+		},
+		{
+			name:      "raw JSON",
+			redaction: DefaultRedaction,
+			v:         json.RawMessage(`{"token":"secret"}`),
+			want:      `{"token":"[REDACTED]"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.redaction.redact(tt.v); got != tt.want {
+				t.Fatalf("redact = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactNonJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{name: "invalid raw JSON", v: json.RawMessage(`token=secret`)},
+		{name: "unsupported value", v: map[string]any{"token": "secret", "ch": make(chan int)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the error is logged in place of the payload, which never shows
+			got := DefaultRedaction.redact(tt.v)
+			if got == "" || strings.Contains(got, "secret") {
+				t.Fatalf("redact = %q, want an error without the payload", got)
+			}
+		})
+	}
+}
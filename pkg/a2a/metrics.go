@@ -0,0 +1,206 @@
+package a2a
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go-micro.dev/v5/store"
+)
+
+// MetricsPath is where SwitchOn serves the metrics of an Agent created with the
+// WithMetrics option, and of an AgentHost created with WithHostMetrics
+const MetricsPath = "/metrics"
+
+const metricsNamespace = "a2a"
+
+// the code label of the requests that succeeded
+const codeOK = "ok"
+
+// metricsHandler serves the metrics gathered by the registry in the Prometheus
+// text format
+func metricsHandler(reg *prometheus.Registry) gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+}
+
+// agentMetrics are the Prometheus collectors of an Agent. They carry the name of
+// the Agent as a constant label so Agents mounted on one AgentHost can share a
+// registry. A nil *agentMetrics records nothing.
+type agentMetrics struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	taskDuration    *prometheus.HistogramVec
+	storeDuration   *prometheus.HistogramVec
+	pushDeliveries  *prometheus.CounterVec
+}
+
+func newAgentMetrics(a *Agent, reg prometheus.Registerer) *agentMetrics {
+	reg = prometheus.WrapRegistererWith(prometheus.Labels{"agent": a.name()}, reg)
+
+	m := &agentMetrics{
+		requests: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "requests_total",
+			Help:      "JSON-RPC requests served by method and error code, ok when the request succeeded.",
+		}, []string{"method", "code"})),
+
+		requestDuration: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to reply to JSON-RPC requests by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"})),
+
+		taskDuration: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "task_duration_seconds",
+			Help:      "Time from the moment a task is queued until its handler returns, by skill and final state.",
+			Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300, 900},
+		}, []string{"skill", "state"})),
+
+		storeDuration: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "store_operation_duration_seconds",
+			Help:      "Latency of the Agent store by operation.",
+			Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+		}, []string{"operation"})),
+
+		pushDeliveries: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "push_notifications_total",
+			Help:      "Push notifications by outcome: delivered, failed when the webhook couldn't be reached or didn't reply 2xx, dropped when too many were waiting.",
+		}, []string{"outcome"})),
+	}
+
+	register(reg, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "active_streams",
+		Help:      "SSE streams currently open.",
+	}, func() float64 {
+		return float64(a.streams.Load())
+	}))
+
+	register(reg, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "queue_depth",
+		Help:      "Tasks waiting for a worker.",
+	}, func() float64 {
+		return float64(a.executor.depth())
+	}))
+
+	register(reg, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "running_tasks",
+		Help:      "Tasks whose handler has been queued and hasn't returned yet, paused ones included.",
+	}, func() float64 {
+		return float64(len(a.liveRuns()))
+	}))
+
+	return m
+}
+
+// register adds the collector to the registry, a collector that was registered
+// before, e.g. by another client sharing the registry, is reused
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	if err := reg.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+	}
+	return c
+}
+
+// errorCode is the code label of a request that failed with e
+func errorCode(e JSONRPCError) string {
+	return strconv.Itoa(int(e.Code))
+}
+
+func (m *agentMetrics) observeRequest(method, code string, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(method, code).Inc()
+	m.requestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+func (m *agentMetrics) observeTask(skillID string, state TaskState, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.taskDuration.WithLabelValues(skillID, string(state)).Observe(time.Since(start).Seconds())
+}
+
+func (m *agentMetrics) observePush(outcome string) {
+	if m == nil {
+		return
+	}
+	m.pushDeliveries.WithLabelValues(outcome).Inc()
+}
+
+func (m *agentMetrics) observeStore(operation string, start time.Time) {
+	m.storeDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+// instrumentedStore measures the latency of the Agent store
+type instrumentedStore struct {
+	store.Store
+	metrics *agentMetrics
+}
+
+func (s instrumentedStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	defer s.metrics.observeStore("read", time.Now())
+	return s.Store.Read(key, opts...)
+}
+
+func (s instrumentedStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	defer s.metrics.observeStore("write", time.Now())
+	return s.Store.Write(r, opts...)
+}
+
+func (s instrumentedStore) Delete(key string, opts ...store.DeleteOption) error {
+	defer s.metrics.observeStore("delete", time.Now())
+	return s.Store.Delete(key, opts...)
+}
+
+func (s instrumentedStore) List(opts ...store.ListOption) ([]string, error) {
+	defer s.metrics.observeStore("list", time.Now())
+	return s.Store.List(opts...)
+}
+
+// clientMetrics are the Prometheus collectors of an A2AClient, a nil *clientMetrics
+// records nothing
+type clientMetrics struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+}
+
+func newClientMetrics(reg prometheus.Registerer) *clientMetrics {
+	return &clientMetrics{
+		requests: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "client",
+			Name:      "requests_total",
+			Help:      "JSON-RPC requests sent by method and error code, ok when the request succeeded and error when it didn't reach the agent.",
+		}, []string{"method", "code"})),
+
+		requestDuration: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "client",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken by agents to reply to JSON-RPC requests by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"})),
+	}
+}
+
+func (m *clientMetrics) observeRequest(method Method, code string, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(string(method), code).Inc()
+	m.requestDuration.WithLabelValues(string(method)).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,144 @@
+package a2a
+
+import (
+	"strings"
+)
+
+const (
+	// MimeTypeText is the mode of TextPart
+	MimeTypeText = "text/plain"
+	// MimeTypeJSON is the mode of DataPart
+	MimeTypeJSON = "application/json"
+	// MimeTypeOctetStream is the mode of a FilePart without a mime type
+	MimeTypeOctetStream = "application/octet-stream"
+)
+
+// PartMimeType returns the mode of a message part: text/plain for a TextPart,
+// application/json for a DataPart and the file mime type for a FilePart
+func PartMimeType(p Part) string {
+	switch v := p.(type) {
+	case TextPart, *TextPart:
+		return MimeTypeText
+	case DataPart, *DataPart:
+		return MimeTypeJSON
+	case FilePart:
+		return fileMimeType(v.File)
+	case *FilePart:
+		return fileMimeType(v.File)
+	}
+	return ""
+}
+
+func fileMimeType(f FileBase) string {
+	if f.MimeType == "" {
+		return MimeTypeOctetStream
+	}
+	return f.MimeType
+}
+
+// InputModes returns the modes the agent accepts for the skill, the skill's own
+// InputModes when it declares them and DefaultInputModes otherwise
+func (ac AgentCard) InputModes(skillID string) []string {
+	for _, s := range ac.Skills {
+		if s.ID == skillID && len(s.InputModes) > 0 {
+			return s.InputModes
+		}
+	}
+	return ac.DefaultInputModes
+}
+
+// OutputModes returns the modes the agent produces for the skill, the skill's own
+// OutputModes when it declares them and DefaultOutputModes otherwise
+func (ac AgentCard) OutputModes(skillID string) []string {
+	for _, s := range ac.Skills {
+		if s.ID == skillID && len(s.OutputModes) > 0 {
+			return s.OutputModes
+		}
+	}
+	return ac.DefaultOutputModes
+}
+
+// CheckMessage verifies that every part of the message fits the input modes of the
+// skill, an empty skillID checks against DefaultInputModes. Clients can call it on
+// a fetched AgentCard before sending, agents call it on every incoming message.
+//
+// Returns:
+//   - nil when the message is accepted
+//   - a JSONRPCError with code ErrorIncompatibleContentType otherwise, its Data
+//     holds the index and mime type of the first rejected part and the accepted modes
+func (ac AgentCard) CheckMessage(msg Message, skillID string) error {
+	modes := ac.InputModes(skillID)
+
+	for i, p := range msg.Parts {
+		if mode := PartMimeType(p); !acceptsMode(modes, mode) {
+			return NewError(ErrorIncompatibleContentType, "incompatible content type", map[string]any{
+				"skillId":    skillID,
+				"part":       i,
+				"mimeType":   mode,
+				"inputModes": modes,
+			})
+		}
+	}
+
+	return nil
+}
+
+// CheckOutputModes verifies that the agent produces at least one of the modes the
+// client accepts for the skill, no accepted modes means the client takes anything
+func (ac AgentCard) CheckOutputModes(accepted []string, skillID string) error {
+	modes := ac.OutputModes(skillID)
+	if len(accepted) == 0 || len(modes) == 0 {
+		return nil
+	}
+
+	for _, m := range modes {
+		if acceptsMode(accepted, m) {
+			return nil
+		}
+	}
+
+	return NewError(ErrorIncompatibleContentType, "incompatible output modes", map[string]any{
+		"skillId":             skillID,
+		"outputModes":         modes,
+		"acceptedOutputModes": accepted,
+	})
+}
+
+// acceptsMode reports whether the mime type is one of the modes, modes may use
+// wildcards such as "image/*" or "*/*" and no modes at all accept anything
+func acceptsMode(modes []string, mime string) bool {
+	if len(modes) == 0 {
+		return true
+	}
+
+	mime = normalizeMode(mime)
+	for _, m := range modes {
+		m = normalizeMode(m)
+		switch {
+		case m == "*/*" || m == "*" || m == mime:
+			return true
+		case strings.HasSuffix(m, "/*") && strings.HasPrefix(mime, strings.TrimSuffix(m, "*")):
+			return true
+		}
+	}
+
+	return false
+}
+
+// modeAliases maps the short modes some AgentCards use to mime types
+var modeAliases = map[string]string{
+	"text": MimeTypeText,
+	"data": MimeTypeJSON,
+	"json": MimeTypeJSON,
+	"file": "*/*",
+}
+
+// normalizeMode drops the mime type parameters, e.g. "; charset=utf-8", and
+// resolves the short aliases
+func normalizeMode(mime string) string {
+	mime = strings.ToLower(strings.TrimSpace(strings.Split(mime, ";")[0]))
+	if alias, ok := modeAliases[mime]; ok {
+		return alias
+	}
+	return mime
+}
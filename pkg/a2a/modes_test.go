@@ -0,0 +1,123 @@
+package a2a
+
+import (
+	"net/http"
+	"testing"
+)
+
+// modalCard takes text by default, its image skill takes images and produces PNG
+func modalCard() AgentCard {
+	return AgentCard{
+		Name:               "Modal",
+		DefaultInputModes:  []string{"text"},
+		DefaultOutputModes: []string{"text/plain"},
+		Skills: []AgentSkill{
+			{ID: "image", Name: "Image", InputModes: []string{"image/*"}, OutputModes: []string{"image/png"}},
+		},
+	}
+}
+
+func TestCheckMessage(t *testing.T) {
+	card := modalCard()
+
+	tests := []struct {
+		name    string
+		part    Part
+		skillID string
+		ok      bool
+	}{
+		{name: "text by default", part: TextPart{Kind: PartTypeText, Text: "hi"}, ok: true},
+		{name: "data by default", part: DataPart{Kind: PartTypeData, Data: map[string]any{"a": 1}}},
+		{name: "image for the skill", part: FilePart{Kind: PartTypeFile, File: FileBase{MimeType: "image/jpeg"}}, skillID: "image", ok: true},
+		{name: "text for the skill", part: TextPart{Kind: PartTypeText, Text: "hi"}, skillID: "image"},
+		{name: "file without mime type", part: FilePart{Kind: PartTypeFile}, skillID: "image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := Message{Role: MessageRoleUser, Parts: []Part{TextPart{Kind: PartTypeText, Text: "first"}, tt.part}}
+			if tt.skillID != "" {
+				msg.Parts = msg.Parts[1:]
+			}
+
+			err := card.CheckMessage(msg, tt.skillID)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("rejected: %v", err)
+				}
+				return
+			}
+
+			e, ok := err.(JSONRPCError)
+			if !ok || e.Code != ErrorIncompatibleContentType {
+				t.Fatalf("err = %v, want ErrorIncompatibleContentType", err)
+			}
+			if want := len(msg.Parts) - 1; e.Data["part"] != want {
+				t.Fatalf("rejected part %v, want %d", e.Data["part"], want)
+			}
+		})
+	}
+}
+
+func TestCheckOutputModes(t *testing.T) {
+	card := modalCard()
+
+	tests := []struct {
+		name     string
+		accepted []string
+		skillID  string
+		ok       bool
+	}{
+		{name: "anything", ok: true},
+		{name: "text by default", accepted: []string{"text/plain; charset=utf-8"}, ok: true},
+		{name: "wildcard", accepted: []string{"image/*"}, skillID: "image", ok: true},
+		{name: "json by default", accepted: []string{"application/json"}},
+		{name: "text for the skill", accepted: []string{"text/plain"}, skillID: "image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := card.CheckOutputModes(tt.accepted, tt.skillID)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("rejected: %v", err)
+				}
+				return
+			}
+
+			if e, ok := err.(JSONRPCError); !ok || e.Code != ErrorIncompatibleContentType {
+				t.Fatalf("err = %v, want ErrorIncompatibleContentType", err)
+			}
+		})
+	}
+}
+
+func TestAgentRejectsUnsupportedModes(t *testing.T) {
+	a := NewAgent(modalCard(), WithAgentHandler(completeHandler{}))
+	srv, paths := serveAgent(t, a)
+
+	tests := []struct {
+		name   string
+		params TaskSendParams
+	}{
+		{name: "input mode", params: TaskSendParams{ID: "t1", Message: Message{Kind: MessageKind, MessageId: "m1", Role: MessageRoleUser, Parts: []Part{DataPart{Kind: PartTypeData, Data: map[string]any{"a": 1}}}}}},
+		{name: "output mode", params: TaskSendParams{ID: "t2", Message: textMessage("m2", "hi"), AcceptedOutputModes: []string{"application/json"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reply := postAs(t, srv.URL+paths.RPC, "", TasksSend, tt.params)
+			if status != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %v", status, reply)
+			}
+			if code := reply["code"]; code != float64(ErrorIncompatibleContentType) {
+				t.Fatalf("code = %v, want ErrorIncompatibleContentType: %v", code, reply)
+			}
+
+			// the task was never created
+			if _, err := a.loadTask(tt.params.ID); err == nil {
+				t.Fatalf("task %s was stored", tt.params.ID)
+			}
+		})
+	}
+}
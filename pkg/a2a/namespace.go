@@ -0,0 +1,40 @@
+package a2a
+
+import (
+	"go-micro.dev/v5/store"
+)
+
+// namespacedStore is a store.Store keeping every record in the table named after
+// the namespace, within the default database of the wrapped store
+type namespacedStore struct {
+	store.Store
+	table string
+}
+
+func newNamespacedStore(s store.Store, namespace string) store.Store {
+	return &namespacedStore{Store: s, table: namespace}
+}
+
+func (n *namespacedStore) database() string {
+	return n.Store.Options().Database
+}
+
+func (n *namespacedStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	return n.Store.Read(key, append(opts, store.ReadFrom(n.database(), n.table))...)
+}
+
+func (n *namespacedStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	return n.Store.Write(r, append(opts, store.WriteTo(n.database(), n.table))...)
+}
+
+func (n *namespacedStore) Delete(key string, opts ...store.DeleteOption) error {
+	return n.Store.Delete(key, append(opts, store.DeleteFrom(n.database(), n.table))...)
+}
+
+func (n *namespacedStore) List(opts ...store.ListOption) ([]string, error) {
+	return n.Store.List(append(opts, store.ListFrom(n.database(), n.table))...)
+}
+
+func (n *namespacedStore) String() string {
+	return n.Store.String() + "/" + n.table
+}
@@ -1,6 +1,9 @@
 package a2a
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 // (TasksSend, TaskSendParams)
 //
@@ -21,6 +24,10 @@ var MethodToParamsType = map[Method]Params{
 	TasksGet:           TaskQueryParams{}, // Task retrieval uses TaskQueryParams
 	TasksCancel:        TaskIDParams{},    // Task cancellation uses TaskIDParams
 	TasksResubscribe:   TaskIDParams{},    // Task resubscription uses TaskIDParams
+	TasksList:          TaskListParams{},  // Task listing uses TaskListParams
+
+	TasksPushNotificationSet: TaskPushNotificationConfig{}, // Setting a push config uses TaskPushNotificationConfig
+	TasksPushNotificationGet: TaskIDParams{},               // Getting a push config uses TaskIDParams
 }
 
 // Method represents an A2A API method name.
@@ -35,11 +42,18 @@ const (
 	TasksCancel        Method = "tasks/cancel"        // Cancel a running task
 	TasksResubscribe   Method = "tasks/resubscribe"   // Resubscribe to updates for an existing task
 
+	// Extension methods, see ExtensionMethods
+	TasksList Method = "tasks/list" // List the tasks of an agent
+
 	// Push notification methods
 	TasksPushNotificationGet Method = "tasks/pushNotification/get" // Get push notification configuration
 	TasksPushNotificationSet Method = "tasks/pushNotification/set" // Set push notification configuration
 )
 
+// ExtensionMethods are the methods Agents serve on top of the A2A specification,
+// the AgentCard lists them in its metadata under CardExtensionMethodsKey
+var ExtensionMethods = []Method{TasksList}
+
 // SSEResponse represents a Server-Sent Event response containing a JSON-RPC response.
 // This is used when receiving streaming updates from an agent.
 type SSEResponse struct {
@@ -81,6 +95,10 @@ type TaskSendParams struct {
 	HistoryLength    int                     `json:"historyLength,omitempty"`    // number of recent messages to retrieve
 	PushNotification *PushNotificationConfig `json:"pushNotification,omitempty"` // notification config
 	Metadata         map[string]any          `json:"metadata,omitempty"`         // extension metadata
+
+	// AcceptedOutputModes are the mime types the client can handle in the reply,
+	// empty means any
+	AcceptedOutputModes []string `json:"acceptedOutputModes,omitempty"`
 }
 
 func (t TaskSendParams) paramGlue() {}
@@ -98,6 +116,24 @@ type JSONRPCRequest struct {
 	ID      any    `json:"id,omitempty"` // Can be string, int, or nil
 	Method  Method `json:"method"`
 	Params  Params `json:"params,omitempty"`
+
+	// ctx is set by the Agent before a handler is invoked, it is never serialized
+	ctx context.Context
+}
+
+// Context returns the request's context. Handlers use it to reach the task being
+// processed and for their own downstream calls. It is never nil.
+func (r JSONRPCRequest) Context() context.Context {
+	if r.ctx != nil {
+		return r.ctx
+	}
+	return context.Background()
+}
+
+// WithContext returns a copy of the request with its context changed to ctx
+func (r JSONRPCRequest) WithContext(ctx context.Context) JSONRPCRequest {
+	r.ctx = ctx
+	return r
 }
 
 func (r JSONRPCRequest) MarshalJSON() ([]byte, error) {
@@ -129,7 +165,7 @@ func (r *JSONRPCRequest) UnmarshalJSON(data []byte) error {
 	r.Method = temp.Method
 
 	switch r.Method {
-	case TasksCancel, TasksResubscribe:
+	case TasksCancel, TasksResubscribe, TasksPushNotificationGet:
 		var v TaskIDParams
 		if err := json.Unmarshal(temp.Params, &v); err != nil {
 			return err
@@ -148,6 +184,20 @@ func (r *JSONRPCRequest) UnmarshalJSON(data []byte) error {
 			return err
 		}
 		r.Params = v
+	case TasksPushNotificationSet:
+		var v TaskPushNotificationConfig
+		if err := json.Unmarshal(temp.Params, &v); err != nil {
+			return err
+		}
+		r.Params = v
+	case TasksList:
+		var v TaskListParams
+		if len(temp.Params) > 0 {
+			if err := json.Unmarshal(temp.Params, &v); err != nil {
+				return err
+			}
+		}
+		r.Params = v
 	default:
 		return nil
 	}
@@ -200,6 +250,13 @@ type JSONRPCResponse struct {
 	ID      any           `json:"id,omitempty"` // Can be string, int, or nil
 	Result  Result        `json:"result,omitempty"`
 	Error   *JSONRPCError `json:"error,omitempty"`
+
+	// eventID is the position of a streamed response in the event log of its
+	// task, it is sent as the SSE event ID
+	eventID eventPos
+	// flushed marks a response the run sends itself to learn when the events
+	// ahead of it are published, see taskRun.flush
+	flushed chan struct{}
 }
 
 func (r JSONRPCResponse) MarshalJSON() ([]byte, error) {
@@ -242,7 +299,13 @@ func (r *JSONRPCResponse) UnmarshalJSON(data []byte) error {
 	}
 
 	// Check for fields that would identify the result type
-	if _, hasID := resultMap["id"]; hasID {
+	if _, hasTasks := resultMap["tasks"]; hasTasks {
+		var list TaskList
+		if err := json.Unmarshal(temp.Result, &list); err != nil {
+			return err
+		}
+		r.Result = list
+	} else if _, hasID := resultMap["id"]; hasID {
 		if _, hasStatus := resultMap["status"]; hasStatus {
 			// This is likely a Task
 			var task Task
@@ -259,6 +322,12 @@ func (r *JSONRPCResponse) UnmarshalJSON(data []byte) error {
 			}
 			result := Result(event)
 			r.Result = result
+		} else if _, hasConfig := resultMap["pushNotificationConfig"]; hasConfig {
+			var config TaskPushNotificationConfig
+			if err := json.Unmarshal(temp.Result, &config); err != nil {
+				return err
+			}
+			r.Result = config
 		} else if _, hasFinal := resultMap["final"]; hasFinal {
 			// This is likely a TaskStatusUpdateEvent
 			var event TaskStatusUpdateEvent
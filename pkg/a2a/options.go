@@ -1,18 +1,104 @@
 package a2a
 
 import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go-micro.dev/v5/broker"
 	"go-micro.dev/v5/logger"
+	"go-micro.dev/v5/registry"
 	"go-micro.dev/v5/store"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 )
 
 type AgentOptions struct {
 	AgentCard AgentCard
 	Logger    logger.Logger
 	Store     store.Store
+	// table of the store the Agent keeps its records in
+	Namespace string
+	// registry the Agent and its AgentCard are published to
+	Registry registry.Registry
 	// use for an agent that replies with one response
 	AgentHandler *AgentHandler
 	// use for an agent that replies with multiple data objects
 	AgentStreamHandler *AgentStreamHandler
+	// handlers for the requests routed to a given AgentSkill.ID
+	SkillHandlers       map[string]AgentHandler
+	SkillStreamHandlers map[string]AgentStreamHandler
+	// picks the skill of requests that don't name one
+	SkillMatcher SkillMatcher
+	// number of handlers running at the same time
+	Workers int
+	// number of tasks waiting for a worker before new ones are rejected
+	QueueSize int
+	// number of handlers paused waiting for input or auth that don't hold a worker
+	MaxPaused int
+	// maximum number of handlers running at the same time per AgentSkill.ID
+	SkillConcurrency map[string]int
+	// time given to the running handlers to return when the Agent stops
+	ShutdownGracePeriod time.Duration
+	// state of the tasks still running once the grace period is over
+	ShutdownPolicy ShutdownPolicy
+	// provider of the tracer the Agent spans are started with
+	TracerProvider trace.TracerProvider
+	// propagator extracting the caller trace from the request headers
+	Propagator propagation.TextMapPropagator
+	// registry the Agent metrics are registered with, nil disables them
+	Metrics *prometheus.Registry
+	// identifies the caller the rate limits and quotas apply to
+	Caller CallerFunc
+	// lets tasks/list return the tasks of every caller instead of the caller's own
+	ListAllCallers bool
+	// rate limits per caller, for every request, per method and per AgentSkill.ID
+	RateLimit        *RateLimit
+	MethodRateLimits map[Method]RateLimit
+	SkillRateLimits  map[string]RateLimit
+	// number of requests a caller may send per UTC day, for every request, per
+	// method and per AgentSkill.ID
+	DailyQuota        int
+	MethodDailyQuotas map[Method]int
+	SkillDailyQuotas  map[string]int
+	// maximum execution time of the handlers, for every skill and per AgentSkill.ID
+	TaskTimeout   time.Duration
+	SkillTimeouts map[string]time.Duration
+	// how long tasks are kept once they reached a state, 0 keeps them forever
+	TaskRetention map[TaskState]time.Duration
+	// how often the expired tasks are purged
+	SweepInterval time.Duration
+	// called with every task before it is purged
+	Archiver ArchiveFunc
+	// how long a tasks/sendSubscribe request waits for its stream to be opened
+	StreamRequestTTL time.Duration
+	// events held for a stream client, what happens when it falls behind and how
+	// long an idle stream waits before sending a heartbeat, negative disables them
+	StreamBuffer       int
+	SlowConsumerPolicy SlowConsumerPolicy
+	StreamHeartbeat    time.Duration
+	// how long a client may reconnect to a stream and resume it
+	StreamResumeWindow time.Duration
+	// serve the WebSocket endpoint next to the streaming one
+	WebSocket bool
+	// address the gRPC binding is served at, empty disables it, the address the
+	// AgentCard lists it at and the options of its server
+	GRPCAddress       string
+	GRPCAdvertise     string
+	GRPCServerOptions []grpc.ServerOption
+	// broker the task events are published to and the topics they go to
+	Broker       broker.Broker
+	BrokerTopics TopicScope
+	// identifies the Agent among its replicas and how long it is deemed alive
+	// after it last announced itself
+	ReplicaID    string
+	ReplicaLease time.Duration
+	// how long the outcome of a tasks/send request is kept for its retries
+	IdempotencyWindow time.Duration
+	// log the requests and events at Debug level, redacted
+	LogPayloads bool
+	// what is hidden from the logged payloads, nil means DefaultRedaction
+	Redaction *Redaction
 }
 
 type AgentOption func(ao *AgentOptions)
@@ -29,6 +115,23 @@ func WithStore(store store.Store) AgentOption {
 	}
 }
 
+// WithRegistry sets the registry the Agent is published to, it defaults to the
+// go-micro v5 default registry
+func WithRegistry(registry registry.Registry) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.Registry = registry
+	}
+}
+
+// WithNamespace keeps the Agent records in their own table of the store, Agents
+// sharing a store should use distinct namespaces. The Agents mounted on an
+// AgentHost default to their name.
+func WithNamespace(namespace string) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.Namespace = namespace
+	}
+}
+
 func WithAgentHandler(handler AgentHandler) AgentOption {
 	return func(ao *AgentOptions) {
 		ao.AgentHandler = &handler
@@ -40,3 +143,378 @@ func WithAgentStreamHandler(streamHandler AgentStreamHandler) AgentOption {
 		ao.AgentStreamHandler = &streamHandler
 	}
 }
+
+// WithSkillHandler registers the handler for the requests routed to the skill, the
+// skill must be advertised in the AgentCard
+func WithSkillHandler(skillID string, handler AgentHandler) AgentOption {
+	return func(ao *AgentOptions) {
+		if ao.SkillHandlers == nil {
+			ao.SkillHandlers = make(map[string]AgentHandler)
+		}
+		ao.SkillHandlers[skillID] = handler
+	}
+}
+
+// WithSkillStreamHandler registers the stream handler for the requests routed to
+// the skill, the skill must be advertised in the AgentCard
+func WithSkillStreamHandler(skillID string, streamHandler AgentStreamHandler) AgentOption {
+	return func(ao *AgentOptions) {
+		if ao.SkillStreamHandlers == nil {
+			ao.SkillStreamHandlers = make(map[string]AgentStreamHandler)
+		}
+		ao.SkillStreamHandlers[skillID] = streamHandler
+	}
+}
+
+// WithSkillMatcher sets how requests that don't name a skill in their metadata are
+// routed, without a matcher they go to the default handlers
+func WithSkillMatcher(matcher SkillMatcher) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.SkillMatcher = matcher
+	}
+}
+
+// WithWorkerPool bounds the number of handlers the Agent runs concurrently and the
+// number of tasks waiting in the submitted state, once the queue is full new tasks
+// are rejected with ErrorServiceUnavailable
+func WithWorkerPool(workers, queueSize int) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.Workers = workers
+		ao.QueueSize = queueSize
+	}
+}
+
+// WithMaxPaused bounds the number of handlers paused by RequestInput or RequestAuth
+// that give their worker back to the pool, the next ones keep their worker until
+// they are answered. It defaults to DefaultMaxPaused.
+func WithMaxPaused(n int) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.MaxPaused = n
+	}
+}
+
+// WithSkillConcurrency limits the number of handlers running at the same time for
+// requests asking for the given skill
+func WithSkillConcurrency(skillID string, limit int) AgentOption {
+	return func(ao *AgentOptions) {
+		if ao.SkillConcurrency == nil {
+			ao.SkillConcurrency = make(map[string]int)
+		}
+		ao.SkillConcurrency[skillID] = limit
+	}
+}
+
+// WithShutdownGracePeriod sets how long the Agent waits for running handlers to
+// return when it stops, it defaults to DefaultShutdownGracePeriod
+func WithShutdownGracePeriod(d time.Duration) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.ShutdownGracePeriod = d
+	}
+}
+
+// WithShutdownPolicy sets the state of the tasks whose handlers didn't return
+// within the grace period, it defaults to ShutdownFail
+func WithShutdownPolicy(policy ShutdownPolicy) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.ShutdownPolicy = policy
+	}
+}
+
+// WithTracerProvider sets the OpenTelemetry provider of the Agent spans, it
+// defaults to the global provider
+func WithTracerProvider(tp trace.TracerProvider) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.TracerProvider = tp
+	}
+}
+
+// WithPropagator sets how the trace of the caller is read from the request
+// headers, it defaults to the W3C trace context and baggage
+func WithPropagator(p propagation.TextMapPropagator) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.Propagator = p
+	}
+}
+
+// WithMetrics registers the Agent metrics with the Prometheus registry and serves
+// them at MetricsPath, a nil registry is replaced with a new one
+func WithMetrics(reg *prometheus.Registry) AgentOption {
+	return func(ao *AgentOptions) {
+		if reg == nil {
+			reg = prometheus.NewRegistry()
+		}
+		ao.Metrics = reg
+	}
+}
+
+// WithCaller sets how callers are told apart for the rate limits, the quotas and
+// their tasks, it defaults to DefaultCaller. A CallerFunc identifying callers by
+// their credentials must verify them.
+func WithCaller(caller CallerFunc) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.Caller = caller
+	}
+}
+
+// WithListAllCallers lets tasks/list return the tasks of every caller and filter
+// them by TaskListParams.Caller. Without it a caller only lists its own tasks,
+// only use it when every client of the Agent may see every task.
+func WithListAllCallers() AgentOption {
+	return func(ao *AgentOptions) {
+		ao.ListAllCallers = true
+	}
+}
+
+// WithRateLimit limits every caller to rate requests per second with bursts of
+// burst requests, requests over the limit are rejected with ErrorRateLimitExceeded
+func WithRateLimit(rate float64, burst int) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.RateLimit = &RateLimit{Rate: rate, Burst: burst}
+	}
+}
+
+// WithMethodRateLimit limits the requests of every caller to the method, on top
+// of WithRateLimit
+func WithMethodRateLimit(method Method, rate float64, burst int) AgentOption {
+	return func(ao *AgentOptions) {
+		if ao.MethodRateLimits == nil {
+			ao.MethodRateLimits = make(map[Method]RateLimit)
+		}
+		ao.MethodRateLimits[method] = RateLimit{Rate: rate, Burst: burst}
+	}
+}
+
+// WithSkillRateLimit limits the tasks every caller sends to the skill, on top of
+// WithRateLimit and WithMethodRateLimit
+func WithSkillRateLimit(skillID string, rate float64, burst int) AgentOption {
+	return func(ao *AgentOptions) {
+		if ao.SkillRateLimits == nil {
+			ao.SkillRateLimits = make(map[string]RateLimit)
+		}
+		ao.SkillRateLimits[skillID] = RateLimit{Rate: rate, Burst: burst}
+	}
+}
+
+// WithDailyQuota limits the number of requests every caller may send per UTC day,
+// the counters are kept in the Agent store so they survive restarts
+func WithDailyQuota(requests int) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.DailyQuota = requests
+	}
+}
+
+// WithMethodDailyQuota limits the number of requests to the method every caller
+// may send per UTC day
+func WithMethodDailyQuota(method Method, requests int) AgentOption {
+	return func(ao *AgentOptions) {
+		if ao.MethodDailyQuotas == nil {
+			ao.MethodDailyQuotas = make(map[Method]int)
+		}
+		ao.MethodDailyQuotas[method] = requests
+	}
+}
+
+// WithSkillDailyQuota limits the number of tasks every caller may send to the
+// skill per UTC day
+func WithSkillDailyQuota(skillID string, requests int) AgentOption {
+	return func(ao *AgentOptions) {
+		if ao.SkillDailyQuotas == nil {
+			ao.SkillDailyQuotas = make(map[string]int)
+		}
+		ao.SkillDailyQuotas[skillID] = requests
+	}
+}
+
+// WithTaskTimeout bounds the execution time of the handlers, a task whose handler
+// overruns it fails with ErrorTimeout and the handler context is cancelled. Clients
+// can ask for an earlier deadline with the DeadlineHeader. The time a handler spends
+// paused in RequestInput or RequestAuth doesn't count.
+func WithTaskTimeout(d time.Duration) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.TaskTimeout = d
+	}
+}
+
+// WithSkillTimeout bounds the execution time of the handlers of the skill, it
+// overrides WithTaskTimeout for the skill
+func WithSkillTimeout(skillID string, d time.Duration) AgentOption {
+	return func(ao *AgentOptions) {
+		if ao.SkillTimeouts == nil {
+			ao.SkillTimeouts = make(map[string]time.Duration)
+		}
+		ao.SkillTimeouts[skillID] = d
+	}
+}
+
+// WithPayloadLogging logs the requests the Agent receives and the events it streams
+// at Debug level, with the values picked by the redaction hidden. Without it only
+// one line per request is logged, with no payload.
+func WithPayloadLogging() AgentOption {
+	return func(ao *AgentOptions) {
+		ao.LogPayloads = true
+	}
+}
+
+// WithRedaction sets what is hidden from the logged payloads, it defaults to
+// DefaultRedaction
+func WithRedaction(r Redaction) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.Redaction = &r
+	}
+}
+
+// WithIdempotencyWindow sets how long the Agent remembers the tasks/send requests
+// it served. A request of the same caller for the same task and message ID within
+// the window doesn't invoke the handler again, it gets the outcome of the first one
+// or waits for it, and is rejected with ErrorInvalidParams when its params differ.
+// It defaults to DefaultIdempotencyWindow.
+//
+// tasks/sendSubscribe requests aren't remembered, a client whose stream broke
+// resumes it with tasks/resubscribe, and the streams opened for the same task
+// while its handler runs share that handler.
+func WithIdempotencyWindow(d time.Duration) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.IdempotencyWindow = d
+	}
+}
+
+// WithTaskRetention sets how long tasks are kept once they reached the state, the
+// tasks are purged by a background sweep. A zero retention keeps them forever. The
+// states it isn't given for keep their DefaultTaskRetention.
+func WithTaskRetention(state TaskState, d time.Duration) AgentOption {
+	return func(ao *AgentOptions) {
+		if ao.TaskRetention == nil {
+			ao.TaskRetention = make(map[TaskState]time.Duration)
+		}
+		ao.TaskRetention[state] = d
+	}
+}
+
+// WithSweepInterval sets how often the expired tasks are purged, it defaults to
+// DefaultSweepInterval
+func WithSweepInterval(d time.Duration) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.SweepInterval = d
+	}
+}
+
+// WithArchiver sets the function every task is handed to before it is purged, a
+// task it fails to archive is kept until the next sweep
+func WithArchiver(archive ArchiveFunc) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.Archiver = archive
+	}
+}
+
+// WithStreamRequestTTL sets how long a tasks/sendSubscribe request is kept waiting
+// for the client to open its stream, it defaults to DefaultStreamRequestTTL
+func WithStreamRequestTTL(d time.Duration) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.StreamRequestTTL = d
+	}
+}
+
+// WithBroker publishes every status and artifact event of the Agent tasks to the
+// broker, wrapped in an EventEnvelope. The Agent connects the broker when it is
+// switched on. Use SubscribeEvents to receive them.
+func WithBroker(b broker.Broker) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.Broker = b
+	}
+}
+
+// WithBrokerTopics sets the topics the task events are published to, it defaults
+// to TopicPerAgent
+func WithBrokerTopics(scope TopicScope) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.BrokerTopics = scope
+	}
+}
+
+// WithReplicaID sets the ID the Agent goes by among its replicas, the Agents sharing
+// a store and a broker. It defaults to a random ID.
+func WithReplicaID(id string) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.ReplicaID = id
+	}
+}
+
+// WithReplicaLease sets how long the other replicas wait for the Agent to announce
+// itself again before they consider the tasks it runs abandoned, it defaults to
+// DefaultReplicaLease
+func WithReplicaLease(d time.Duration) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.ReplicaLease = d
+	}
+}
+
+// WithStreamBuffer sets how many events a stream holds for a client that reads them
+// slower than they are produced, it defaults to DefaultStreamBuffer
+func WithStreamBuffer(n int) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.StreamBuffer = n
+	}
+}
+
+// WithSlowConsumerPolicy sets what happens once the buffer of a stream is full, it
+// defaults to SlowConsumerDisconnect
+func WithSlowConsumerPolicy(p SlowConsumerPolicy) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.SlowConsumerPolicy = p
+	}
+}
+
+// WithStreamHeartbeat sets how long a stream stays silent before it sends an SSE
+// comment, it defaults to DefaultStreamHeartbeat and a negative interval disables
+// the heartbeats
+func WithStreamHeartbeat(d time.Duration) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.StreamHeartbeat = d
+	}
+}
+
+// WithStreamResumeWindow sets how long a client may reconnect to a stream it opened
+// and resume it after the last event it received, it defaults to
+// DefaultStreamResumeWindow
+func WithStreamResumeWindow(d time.Duration) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.StreamResumeWindow = d
+	}
+}
+
+// WithWebSocket serves a WebSocket endpoint at /<name>/ws, a session carries the
+// requests of the client and their responses, streams included, over a single
+// connection. A session serves up to 32 requests at once and reads messages of up
+// to 4 MiB.
+func WithWebSocket() AgentOption {
+	return func(ao *AgentOptions) {
+		ao.WebSocket = true
+	}
+}
+
+// WithGRPC serves the gRPC binding of the A2A operations at the address, e.g.
+// ":9090", the AgentCard lists it among its additional interfaces. A listen address
+// without a host is listed with the host of the AgentCard URL, or of the machine
+// when the card has none, see WithGRPCAdvertise.
+func WithGRPC(address string) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.GRPCAddress = address
+	}
+}
+
+// WithGRPCAdvertise sets the address the AgentCard lists the gRPC binding at, e.g.
+// "agent.example.com:443" when the binding is reached through a load balancer
+func WithGRPCAdvertise(address string) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.GRPCAdvertise = address
+	}
+}
+
+// WithGRPCServerOptions sets the options the server of the gRPC binding is created
+// with, e.g. its transport credentials, interceptors or message size limits. The
+// server is plaintext without them.
+func WithGRPCServerOptions(opts ...grpc.ServerOption) AgentOption {
+	return func(ao *AgentOptions) {
+		ao.GRPCServerOptions = append(ao.GRPCServerOptions, opts...)
+	}
+}
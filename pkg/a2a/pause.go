@@ -0,0 +1,124 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotInHandler is returned by RequestInput and RequestAuth when the context
+// doesn't belong to a request handed over by an Agent
+var ErrNotInHandler = errors.New("a2a: context doesn't belong to an agent handler")
+
+// RequestInput pauses the task in the input-required state with prompt as its
+// status message and blocks until the client sends the follow-up message for the
+// same task ID, which is returned. It works for both TaskHandler and StreamHandler,
+// pass it the context of the request the handler received.
+//
+// A unary client gets the paused task as the reply to its tasks/send and the
+// reply to the follow-up once the handler pauses again or returns. A streaming
+// client keeps its stream open and receives the status updates in it.
+//
+// The handler gives its worker back while it is paused, a new task runs in its
+// place, and waits for a worker to be free once the follow-up message arrives.
+// Past the number of paused handlers set by WithMaxPaused the next ones keep their
+// worker. The time spent paused doesn't count against the maximum execution time
+// of the task, the deadline the client asked for still applies.
+//
+// If the context is done first, or the Agent restarted in the meantime, the
+// follow-up message invokes the handler again with the full task history instead.
+func RequestInput(ctx context.Context, prompt Message) (Message, error) {
+	return pause(ctx, TaskStateInputRequired, prompt)
+}
+
+// RequestAuth works as RequestInput but pauses the task in the auth-required
+// state, the prompt tells the client which credentials the agent needs
+func RequestAuth(ctx context.Context, prompt Message) (Message, error) {
+	return pause(ctx, TaskStateAuthRequired, prompt)
+}
+
+func pause(ctx context.Context, state TaskState, prompt Message) (Message, error) {
+	run, ok := runFromContext(ctx)
+	if !ok {
+		return Message{}, ErrNotInHandler
+	}
+
+	if prompt.MessageId == "" {
+		prompt.MessageId = uuid.NewString()
+	}
+	if prompt.Role == "" {
+		prompt.Role = MessageRoleAgent
+	}
+	prompt.Kind = MessageKind
+	prompt.TaskId = run.taskID
+
+	if t, ok := TaskFromContext(ctx); ok {
+		prompt.ContextId = t.ContextID
+	}
+
+	// the prompt follows the events the handler sent before pausing
+	run.flush()
+
+	// the run waits before the prompt is out, a client answering it right away
+	// claims the pause
+	run.mu.Lock()
+	run.waiting = true
+	run.mu.Unlock()
+
+	run.publish(JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      run.reqID,
+		Result: &TaskStatusUpdateEvent{
+			ID:     run.taskID,
+			Status: TaskStatus{State: state, Message: &prompt},
+		},
+	})
+
+	// let a unary request waiting on the run reply with the paused task, unless a
+	// follow-up claimed the pause already
+	run.mu.Lock()
+	if run.waiting {
+		select {
+		case run.paused <- struct{}{}:
+		default:
+		}
+	}
+	run.mu.Unlock()
+
+	// the worker runs other tasks and the execution time stops counting until the
+	// client answers
+	parked := run.agent.executor.park()
+	run.pauseClock()
+
+	select {
+	case msg := <-run.resume:
+		if parked {
+			run.agent.executor.unpark(ctx)
+		}
+		run.resumeClock()
+		run.publish(JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      run.reqID,
+			Result: &TaskStatusUpdateEvent{
+				ID:     run.taskID,
+				Status: TaskStatus{State: TaskStateWorking},
+			},
+		})
+		return msg, nil
+
+	case <-ctx.Done():
+		run.mu.Lock()
+		run.waiting = false
+		run.mu.Unlock()
+		if parked {
+			run.agent.executor.unpark(ctx)
+		}
+		return Message{}, ctx.Err()
+	}
+}
+
+// isPaused reports whether the task waits for a follow-up message from the client
+func isPaused(state TaskState) bool {
+	return state == TaskStateInputRequired || state == TaskStateAuthRequired
+}
@@ -0,0 +1,76 @@
+package a2a
+
+import (
+	"context"
+	"testing"
+
+	go_sse "github.com/tmaxmax/go-sse"
+)
+
+func TestPauseFollowsTheEventsOfTheHandler(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Asking", Capabilities: &AgentCapabilities{Streaming: true}}, WithAgentStreamHandler(streamHandler{}))
+	srv, paths := serveAgent(t, a)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// more tasks pause than there are workers
+	for i := 0; i <= DefaultWorkers; i++ {
+		id := "t" + string(rune('a'+i))
+		events, err := NewA2AClient().SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: id, Message: textMessage(id, "hi"), Metadata: map[string]any{"ask": true}}, srv.URL+paths.Stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the working status the handler sent first never lands after the prompt
+		nextEvent(t, events, inState(TaskStateWorking))
+		nextEvent(t, events, inState(TaskStateInputRequired))
+
+		task, err := a.loadTask(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.Status.State != TaskStateInputRequired {
+			t.Fatalf("task %s is %s, want input-required", id, task.Status.State)
+		}
+	}
+}
+
+func TestPausedHandlersGiveTheirWorkerBack(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Asking", Capabilities: &AgentCapabilities{Streaming: true}}, WithAgentStreamHandler(streamHandler{}), WithWorkerPool(2, 10))
+	srv, paths := serveAgent(t, a)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	send := func(id string, ask bool) chan go_sse.Event {
+		t.Helper()
+
+		events, err := NewA2AClient().SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: id, Message: textMessage(id, "bob"), Metadata: map[string]any{"ask": ask}}, srv.URL+paths.Stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return events
+	}
+
+	// both workers' worth of tasks wait for input
+	paused := make([]chan go_sse.Event, 2)
+	for i := range paused {
+		paused[i] = send("paused"+string(rune('a'+i)), true)
+		nextEvent(t, paused[i], inState(TaskStateInputRequired))
+	}
+
+	// a new task still gets a worker
+	events := send("new", false)
+	nextEvent(t, events, inState(TaskStateCompleted))
+
+	// the paused tasks carry on once they are answered
+	for i := range paused {
+		id := "paused" + string(rune('a'+i))
+		answer := send(id, false)
+		if s := nextEvent(t, answer, func(s streamed) bool { return s.text != "" }); s.text != "hello bob" {
+			t.Fatalf("task %s answered %q", id, s.text)
+		}
+		nextEvent(t, answer, inState(TaskStateCompleted))
+	}
+}
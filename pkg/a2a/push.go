@@ -0,0 +1,314 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/logger"
+	"go-micro.dev/v5/store"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PushTokenHeader carries the PushNotificationConfig.Token of the task on every
+// notification, the client checks it to tell the notifications of its tasks apart
+const PushTokenHeader = "X-A2A-Notification-Token"
+
+// how long the webhook of a client is given to take a notification
+const pushTimeout = 10 * time.Second
+
+// notifications waiting for delivery before the next ones are dropped
+const pushQueueSize = 256
+
+// Outcomes of a push notification, the outcome label of the delivery metrics
+const (
+	pushDelivered = "delivered"
+	pushFailed    = "failed"
+	pushDropped   = "dropped"
+)
+
+// push notification configs are kept in the Agent store next to their task
+const pushKeyPrefix = "push/"
+
+func pushKey(taskID string) string {
+	return pushKeyPrefix + taskID
+}
+
+// pushSupported reports whether the Agent advertises the push notifications
+// capability, without it the push methods are rejected
+func (a *Agent) pushSupported() bool {
+	caps := a.options.AgentCard.Capabilities
+	return caps != nil && caps.PushNotifications
+}
+
+// errPushNotSupported is returned to the push requests of an Agent that doesn't
+// advertise the capability
+func errPushNotSupported() JSONRPCError {
+	return NewError(ErrorPushNotificationNotSupported, "the agent doesn't support push notifications", nil)
+}
+
+// checkPushConfig verifies that the config names a webhook the Agent can post to
+func checkPushConfig(config PushNotificationConfig) error {
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return NewError(ErrorInvalidParams, "the push notification url must be an absolute http or https URL", map[string]any{"url": config.URL})
+	}
+	return nil
+}
+
+// setPushConfig stores the push notification config of the task, the changes of
+// its status are posted to the webhook from then on
+func (a *Agent) setPushConfig(taskID string, config PushNotificationConfig) error {
+	if !a.pushSupported() {
+		return errPushNotSupported()
+	}
+
+	if err := checkPushConfig(config); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	return a.options.Store.Write(&store.Record{Key: pushKey(taskID), Value: raw})
+}
+
+// keepPushConfig stores the push notification config a tasks/send or
+// tasks/sendSubscribe request carries, if any
+func (a *Agent) keepPushConfig(params TaskSendParams) error {
+	if params.PushNotification == nil {
+		return nil
+	}
+	return a.setPushConfig(params.ID, *params.PushNotification)
+}
+
+// pushConfig reads the push notification config of the task, store.ErrNotFound is
+// returned as is when the task has none
+func (a *Agent) pushConfig(taskID string) (PushNotificationConfig, error) {
+	records, err := a.options.Store.Read(pushKey(taskID))
+	if err != nil {
+		return PushNotificationConfig{}, err
+	}
+	if len(records) == 0 {
+		return PushNotificationConfig{}, store.ErrNotFound
+	}
+
+	var config PushNotificationConfig
+	if err := json.Unmarshal(records[0].Value, &config); err != nil {
+		return PushNotificationConfig{}, err
+	}
+	return config, nil
+}
+
+// pushNotificationHandler serves tasks/pushNotification/set and get, only for the
+// tasks the Agent knows
+func (a *Agent) pushNotificationHandler(c *gin.Context, r JSONRPCRequest) {
+	if !a.pushSupported() {
+		c.JSON(http.StatusBadRequest, errPushNotSupported())
+		return
+	}
+
+	var taskID string
+	switch params := r.Params.(type) {
+	case TaskPushNotificationConfig:
+		taskID = params.ID
+	case TaskIDParams:
+		taskID = params.ID
+	default:
+		e := NewError(ErrorInvalidRequest, "request should include a TaskPushNotificationConfig or a TaskIDParams as params", nil)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	if _, err := a.loadTask(taskID); err == store.ErrNotFound {
+		e := NewError(ErrorTaskNotFound, "task not found", map[string]any{"id": taskID})
+		c.JSON(http.StatusNotFound, e)
+		return
+	} else if err != nil {
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if params, ok := r.Params.(TaskPushNotificationConfig); ok {
+		err := a.setPushConfig(params.ID, params.PushNotificationConfig)
+		if e, ok := err.(JSONRPCError); ok {
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		if err != nil {
+			e := NewError(ErrorInternal, err.Error(), nil)
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+
+		c.JSON(http.StatusOK, JSONRPCResponse{JSONRPC: "2.0", ID: r.ID, Result: params})
+		return
+	}
+
+	config, err := a.pushConfig(taskID)
+	if err == store.ErrNotFound {
+		c.JSON(http.StatusOK, JSONRPCResponse{JSONRPC: "2.0", ID: r.ID})
+		return
+	}
+	if err != nil {
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, JSONRPCResponse{JSONRPC: "2.0", ID: r.ID, Result: TaskPushNotificationConfig{ID: taskID, PushNotificationConfig: config}})
+}
+
+// notification is the task posted to the webhook of a push notification config
+type notification struct {
+	config PushNotificationConfig
+	task   Task
+}
+
+// pusher posts the notifications of the Agent tasks in the background, one at a
+// time so a webhook receives the statuses of a task in order. The notifications
+// that don't fit in its queue are dropped rather than holding the task.
+type pusher struct {
+	agent  *Agent
+	client *http.Client
+	queue  chan notification
+
+	start sync.Once
+	stop  sync.Once
+	ctx   context.Context
+	halt  context.CancelFunc
+}
+
+func newPusher(a *Agent) *pusher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &pusher{
+		agent:  a,
+		client: &http.Client{Timeout: pushTimeout},
+		queue:  make(chan notification, pushQueueSize),
+		ctx:    ctx,
+		halt:   cancel,
+	}
+}
+
+// run starts delivering the notifications, calling it more than once has no effect
+func (p *pusher) run() {
+	p.start.Do(func() {
+		go func() {
+			for {
+				select {
+				case n := <-p.queue:
+					p.deliver(n)
+				case <-p.ctx.Done():
+					return
+				}
+			}
+		}()
+	})
+}
+
+// close stops the deliveries, the notifications still queued are dropped
+func (p *pusher) close() {
+	p.stop.Do(p.halt)
+}
+
+// notifyPush queues a notification for the task when its status changed and the
+// client asked for push notifications
+func (a *Agent) notifyPush(t *Task, events []TaskEvent) {
+	if !a.pushSupported() {
+		return
+	}
+
+	changed := false
+	for _, e := range events {
+		if e.Kind == EventStatus {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	config, err := a.pushConfig(t.ID)
+	if err == store.ErrNotFound {
+		return
+	}
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		return
+	}
+
+	select {
+	case a.pusher.queue <- notification{config: config, task: *t}:
+	default:
+		a.options.Logger.Log(logger.WarnLevel, "push notification of task "+t.ID+" dropped, too many are waiting")
+		a.metrics.observePush(pushDropped)
+	}
+}
+
+// deliver posts the task to the webhook, the token and credentials of the config
+// go in the headers along with the trace of the Agent
+func (p *pusher) deliver(n notification) {
+	a := p.agent
+
+	ctx, span := a.tracer().Start(p.ctx, "a2a.push",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(AttributeTaskID.String(n.task.ID), AttributeTaskState.String(string(n.task.Status.State))),
+	)
+	defer span.End()
+
+	err := p.post(ctx, n)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		a.options.Logger.Log(logger.WarnLevel, fmt.Sprintf("push notification of task %s failed: %v", n.task.ID, err))
+		a.metrics.observePush(pushFailed)
+		return
+	}
+
+	a.metrics.observePush(pushDelivered)
+}
+
+func (p *pusher) post(ctx context.Context, n notification) error {
+	body, err := json.Marshal(n.task)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.config.Token != "" {
+		req.Header.Set(PushTokenHeader, n.config.Token)
+	}
+	if auth := n.config.Authentication; auth != nil && auth.Credentials != "" && len(auth.Schemes) > 0 {
+		req.Header.Set("Authorization", auth.Schemes[0]+" "+auth.Credentials)
+	}
+	propagatorFrom(p.agent.options.Propagator).Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("the webhook replied %d", res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package a2a
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// webhook records the notifications it receives and replies them with status
+type webhook struct {
+	status        int
+	tokens        chan string
+	notifications chan Task
+}
+
+func newWebhook(t *testing.T, status int) (*webhook, *httptest.Server) {
+	w := &webhook{status: status, tokens: make(chan string, 16), notifications: make(chan Task, 16)}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var task Task
+		if err := json.NewDecoder(r.Body).Decode(&task); err == nil {
+			w.tokens <- r.Header.Get(PushTokenHeader)
+			w.notifications <- task
+		}
+		rw.WriteHeader(w.status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return w, srv
+}
+
+// pushingAgent advertises push notifications and registers its metrics with reg
+func pushingAgent(reg *prometheus.Registry) *Agent {
+	card := AgentCard{Name: "Pushing", Capabilities: &AgentCapabilities{PushNotifications: true}}
+	return NewAgent(card, WithAgentHandler(completeHandler{}), WithMetrics(reg))
+}
+
+// pushOutcome returns the number of push notifications of the Agent with the outcome
+func pushOutcome(a *Agent, outcome string) float64 {
+	return testutil.ToFloat64(a.metrics.pushDeliveries.WithLabelValues(outcome))
+}
+
+// waitForOutcome waits until want push notifications had the outcome
+func waitForOutcome(t *testing.T, a *Agent, outcome string, want float64) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for pushOutcome(a, outcome) < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%v push notifications %s, want %v", pushOutcome(a, outcome), outcome, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPushNotificationsAreDeliveredAndCounted(t *testing.T) {
+	hook, hookSrv := newWebhook(t, http.StatusOK)
+
+	reg := prometheus.NewPedanticRegistry()
+	a := pushingAgent(reg)
+	srv, paths := serveAgent(t, a)
+
+	params := TaskSendParams{ID: "t1", Message: textMessage("m1", "hi"), PushNotification: &PushNotificationConfig{URL: hookSrv.URL, Token: "secret"}}
+	if status, reply := postAs(t, srv.URL+paths.RPC, "", TasksSend, params); status != http.StatusOK {
+		t.Fatalf("tasks/send: %d %v", status, reply)
+	}
+
+	// the webhook gets every status of the task in order, the last one completed
+	var states []TaskState
+	for len(states) == 0 || states[len(states)-1] != TaskStateCompleted {
+		select {
+		case task := <-hook.notifications:
+			if token := <-hook.tokens; token != "secret" {
+				t.Fatalf("token = %q", token)
+			}
+			states = append(states, task.Status.State)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("notified of %v only", states)
+		}
+	}
+	if states[0] != TaskStateSubmitted {
+		t.Fatalf("notified of %v, want submitted first", states)
+	}
+
+	waitForOutcome(t, a, pushDelivered, float64(len(states)))
+	if n := pushOutcome(a, pushFailed); n != 0 {
+		t.Fatalf("%v push notifications failed", n)
+	}
+
+	// the counters are served along with the other metrics of the Agent
+	want := fmt.Sprintf(`
+		# HELP a2a_push_notifications_total Push notifications by outcome: delivered, failed when the webhook couldn't be reached or didn't reply 2xx, dropped when too many were waiting.
+		# TYPE a2a_push_notifications_total counter
+		a2a_push_notifications_total{agent="Pushing",outcome="delivered"} %d
+		a2a_push_notifications_total{agent="Pushing",outcome="failed"} 0
+	`, len(states))
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "a2a_push_notifications_total"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPushNotificationFailuresAreCounted(t *testing.T) {
+	_, hookSrv := newWebhook(t, http.StatusInternalServerError)
+
+	reg := prometheus.NewPedanticRegistry()
+	a := pushingAgent(reg)
+	srv, paths := serveAgent(t, a)
+
+	params := TaskSendParams{ID: "t1", Message: textMessage("m1", "hi"), PushNotification: &PushNotificationConfig{URL: hookSrv.URL}}
+	if status, reply := postAs(t, srv.URL+paths.RPC, "", TasksSend, params); status != http.StatusOK {
+		t.Fatalf("tasks/send: %d %v", status, reply)
+	}
+
+	// submitted, working and completed
+	waitForOutcome(t, a, pushFailed, 3)
+	if n := pushOutcome(a, pushDelivered); n != 0 {
+		t.Fatalf("%v push notifications delivered", n)
+	}
+}
+
+func TestPushNotificationConfig(t *testing.T) {
+	_, hookSrv := newWebhook(t, http.StatusOK)
+
+	srv, paths := serveAgent(t, pushingAgent(prometheus.NewRegistry()))
+	url := srv.URL + paths.RPC
+
+	if status, reply := postAs(t, url, "", TasksSend, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")}); status != http.StatusOK {
+		t.Fatalf("tasks/send: %d %v", status, reply)
+	}
+
+	config := TaskPushNotificationConfig{ID: "t1", PushNotificationConfig: PushNotificationConfig{URL: hookSrv.URL, Token: "secret"}}
+	if status, reply := postAs(t, url, "", TasksPushNotificationSet, config); status != http.StatusOK {
+		t.Fatalf("set: %d %v", status, reply)
+	}
+
+	status, reply := postAs(t, url, "", TasksPushNotificationGet, TaskIDParams{ID: "t1"})
+	if status != http.StatusOK {
+		t.Fatalf("get: %d %v", status, reply)
+	}
+	result, _ := reply["result"].(map[string]any)
+	got, _ := result["pushNotificationConfig"].(map[string]any)
+	if got["url"] != hookSrv.URL || got["token"] != "secret" {
+		t.Fatalf("config = %v", result)
+	}
+
+	tests := []struct {
+		name   string
+		method Method
+		params any
+		status int
+		code   ErrorCode
+	}{
+		{name: "unknown task", method: TasksPushNotificationGet, params: TaskIDParams{ID: "nope"}, status: http.StatusNotFound, code: ErrorTaskNotFound},
+		{name: "relative url", method: TasksPushNotificationSet, params: TaskPushNotificationConfig{ID: "t1", PushNotificationConfig: PushNotificationConfig{URL: "/hook"}}, status: http.StatusBadRequest, code: ErrorInvalidParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reply := postAs(t, url, "", tt.method, tt.params)
+			if status != tt.status || reply["code"] != float64(tt.code) {
+				t.Fatalf("reply = %d %v, want %d with code %d", status, reply, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestPushNotificationsNeedTheCapability(t *testing.T) {
+	srv, paths := serveAgent(t, NewAgent(AgentCard{Name: "Quiet"}, WithAgentHandler(completeHandler{})))
+
+	params := TaskSendParams{ID: "t1", Message: textMessage("m1", "hi"), PushNotification: &PushNotificationConfig{URL: "http://127.0.0.1/hook"}}
+	status, reply := postAs(t, srv.URL+paths.RPC, "", TasksSend, params)
+	if status != http.StatusBadRequest || reply["code"] != float64(ErrorPushNotificationNotSupported) {
+		t.Fatalf("reply = %d %v, want ErrorPushNotificationNotSupported", status, reply)
+	}
+}
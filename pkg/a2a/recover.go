@@ -0,0 +1,49 @@
+package a2a
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/logger"
+)
+
+// recovery replaces gin.Recovery, a panic while serving a request is logged with
+// its stack and answered with ErrorInternal instead of a bare 500
+func recovery(l logger.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, v any) {
+		l.Log(logger.ErrorLevel, fmt.Sprintf("panic serving %s %s: %v\n%s", c.Request.Method, c.Request.URL.Path, v, debug.Stack()))
+
+		e := NewError(ErrorInternal, "the agent failed to process the request", nil)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, e)
+	})
+}
+
+// handlerPanicked fails the task of a handler that panicked, the stack is logged
+// and the client receives ErrorInternal. It must be called from the deferred
+// function that recovered the panic.
+func (a *Agent) handlerPanicked(run *taskRun, v any) {
+	a.options.Logger.Log(logger.ErrorLevel, fmt.Sprintf("handler of task %s panicked: %v\n%s", run.taskID, v, debug.Stack()))
+
+	e := NewError(ErrorInternal, "the agent failed to process the task", map[string]any{"id": run.taskID})
+	a.failRun(run, e, "the agent failed to process the task")
+}
+
+// jobPanicked is the last resort for a job that panicked outside of its handler,
+// the run of the job fails with ErrorInternal unless it ended already
+func (a *Agent) jobPanicked(j *job, v any) {
+	a.options.Logger.Log(logger.ErrorLevel, fmt.Sprintf("job of task %s panicked: %v\n%s", j.run.taskID, v, debug.Stack()))
+
+	e := NewError(ErrorInternal, "the agent failed to process the task", map[string]any{"id": j.run.taskID})
+	a.failRun(j.run, e, "the agent failed to process the task")
+}
+
+// closeQuietly closes the channel a panicking StreamHandler may have closed already
+func closeQuietly(out chan JSONRPCResponse) {
+	defer func() {
+		_ = recover()
+	}()
+	close(out)
+}
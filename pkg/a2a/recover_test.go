@@ -0,0 +1,80 @@
+package a2a
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// panicHandler panics on the messages saying "panic" and completes the others
+type panicHandler struct{}
+
+func (panicHandler) TaskHandler(req JSONRPCRequest) JSONRPCResponse {
+	params := req.Params.(TaskSendParams)
+	if t, ok := params.Message.Parts[0].(TextPart); ok && t.Text == "panic" {
+		panic("boom")
+	}
+	return completeHandler{}.TaskHandler(req)
+}
+
+func TestPanickingHandlerFailsTheTask(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Panicking"}, WithAgentHandler(panicHandler{}), WithWorkerPool(1, 10))
+	srv, paths := serveAgent(t, a)
+	url := srv.URL + paths.RPC
+
+	status, reply := postAs(t, url, "", TasksSend, TaskSendParams{ID: "t1", Message: textMessage("m1", "panic")})
+	if status != http.StatusInternalServerError || reply["code"] != float64(ErrorInternal) {
+		t.Fatalf("reply = %d %v, want 500 with ErrorInternal", status, reply)
+	}
+
+	task, err := a.loadTask("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status.State != TaskStateFailed {
+		t.Fatalf("task is %s, want failed", task.Status.State)
+	}
+
+	// the only worker survived the panic
+	if status, reply := postAs(t, url, "", TasksSend, TaskSendParams{ID: "t2", Message: textMessage("m2", "hi")}); status != http.StatusOK {
+		t.Fatalf("tasks/send after the panic: %d %v", status, reply)
+	}
+}
+
+func TestPanickingJobFailsItsRun(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Panicking"}, WithAgentHandler(completeHandler{}))
+	serveAgent(t, a)
+
+	if _, _, err := a.prepareTask(TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")}, "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// the run isn't the live run of its task, it fails all the same
+	run := a.newRun(context.Background(), "t1", 1, "")
+	j, err := a.executor.submit(run, func() { panic("boom") })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-j.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the job never ran")
+	}
+
+	run.mu.Lock()
+	failure := run.failure
+	run.mu.Unlock()
+	if failure == nil || failure.Code != ErrorInternal {
+		t.Fatalf("the run failed with %v, want ErrorInternal", failure)
+	}
+
+	task, err := a.loadTask("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status.State != TaskStateFailed {
+		t.Fatalf("task is %s, want failed", task.Status.State)
+	}
+}
@@ -26,8 +26,11 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	httpServer "github.com/micro/plugins/v5/server/http"
 
 	"go-micro.dev/v5"
@@ -36,7 +39,9 @@ import (
 	"go-micro.dev/v5/server"
 	"go-micro.dev/v5/store"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ResultChan chan JSONRPCResponse
@@ -53,27 +58,56 @@ type AgentStreamHandler interface {
 type ClientChan chan string
 
 type Agent struct {
-	options AgentOptions
-	Server  server.Server
+	options  AgentOptions
+	Server   server.Server
+	executor *executor
+
+	runsMu sync.Mutex
+	runs   map[string]*taskRun
+
+	// tasksMu orders the writes of the tasks and of their event logs
+	tasksMu sync.Mutex
+
+	// draining is set once the Agent started shutting down
+	draining atomic.Bool
+	// streams counts the open SSE streams
+	streams atomic.Int64
+
+	// metrics is nil unless the WithMetrics option is provided
+	metrics *agentMetrics
+	limiter *limiter
+
+	sweeper *sweeper
+	replica *replica
+	pusher  *pusher
+
+	// local serves the requests of the WebSocket sessions and of the gRPC binding
+	local *gin.Engine
+	// grpc is nil unless the WithGRPC option is provided
+	grpc *grpcBinding
+
+	// submissionsMu makes checking and recording a tasks/send request atomic
+	submissionsMu sync.Mutex
+	// continueMu makes checking and continuing a paused task atomic
+	continueMu sync.Mutex
 }
 
 // NewAgent creates new remote Agent (Server), if the WithStore option is not provided
 // the store will default to the go-micro v5 memory store
 func NewAgent(agentCard AgentCard, opts ...AgentOption) *Agent {
-	re := regexp.MustCompile(`[ .]`) // Match spaces and periods
-	agentName := re.ReplaceAllString(agentCard.Name, "")
-
 	agent := &Agent{
-		Server: httpServer.NewServer(
-			server.Name(agentName),
-			server.Address(agentCard.URL),
-		),
-
 		options: AgentOptions{
 			AgentCard: agentCard,
 		},
+
+		runs: make(map[string]*taskRun),
 	}
 
+	agent.Server = httpServer.NewServer(
+		server.Name(agent.name()),
+		server.Address(agentCard.URL),
+	)
+
 	for _, o := range opts {
 		o(&agent.options)
 	}
@@ -83,19 +117,101 @@ func NewAgent(agentCard AgentCard, opts ...AgentOption) *Agent {
 		agent.options.Store = store.NewMemoryStore()
 	}
 
+	// keep the Agent records apart from the ones of Agents sharing the store
+	if agent.options.Namespace != "" {
+		agent.options.Store = newNamespacedStore(agent.options.Store, agent.options.Namespace)
+	}
+
+	// measure the store latency along with the requests and tasks
+	if agent.options.Metrics != nil {
+		agent.metrics = newAgentMetrics(agent, agent.options.Metrics)
+		agent.options.Store = instrumentedStore{Store: agent.options.Store, metrics: agent.metrics}
+	}
+
 	// set the default logger
 	if agent.options.Logger == nil {
 		agent.options.Logger = logger.NewLogger()
 	}
 
+	// replicas tell each other apart by their ID
+	if agent.options.ReplicaID == "" {
+		agent.options.ReplicaID = uuid.NewString()
+	}
+
+	// set the default registry
+	if agent.options.Registry == nil {
+		agent.options.Registry = registry.NewRegistry()
+	}
+
+	// advertise the extension methods along with the A2A ones
+	agent.options.AgentCard = withExtensionMethods(agent.options.AgentCard)
+	if agent.options.GRPCAddress != "" {
+		address, err := grpcAdvertised(agent.options.AgentCard, agent.options.GRPCAddress, agent.options.GRPCAdvertise)
+		if err != nil {
+			agent.options.Logger.Log(logger.WarnLevel, "the gRPC binding isn't listed on the AgentCard: "+err.Error())
+		}
+		agent.options.AgentCard = withGRPCInterface(agent.options.AgentCard, address)
+	}
+
+	agent.limiter = newLimiter(agent)
+	agent.sweeper = newSweeper(agent)
+	agent.pusher = newPusher(agent)
+	agent.replica = newReplica(agent)
+	agent.executor = newExecutor(agent.options.Workers, agent.options.QueueSize, agent.options.MaxPaused, agent.options.SkillConcurrency)
+	agent.executor.panicked = agent.jobPanicked
+
 	return agent
 }
 
 func (a *Agent) SwitchOn() {
+	router := newRouter(a.options.Logger)
+
+	paths := a.mount(router, AgentCardPath)
+
+	if a.options.Metrics != nil {
+		router.GET(MetricsPath, metricsHandler(a.options.Metrics))
+	}
+
+	// the AgentCard is published on the registry node and on the Agent endpoint so
+	// peers can discover the Agent by its skills, see DiscoverAgents
+	if err := a.Server.Init(server.Metadata(cardMetadata(a.options.AgentCard, paths))); err != nil {
+		log.Fatalln(err)
+	}
+
+	hd := a.Server.NewHandler(router, server.EndpointMetadata(a.name(), cardMetadata(a.options.AgentCard, paths)))
+	if err := a.Server.Handle(hd); err != nil {
+		log.Fatalln(err)
+	}
+
+	runService(a.Server, a.options.Logger, a.options.Registry, a.shutdown)
+}
+
+// agentPaths are the routes an Agent serves
+type agentPaths struct {
+	RPC       string
+	Stream    string
+	WebSocket string
+	Card      string
+}
+
+// name returns the Agent name without spaces and periods, it is the name of the
+// service and the path the Agent is served at
+func (a *Agent) name() string {
+	return serviceName(a.options.AgentCard.Name)
+}
+
+// serviceName strips the spaces and periods of an AgentCard name
+func serviceName(cardName string) string {
+	re := regexp.MustCompile(`[ .]`) // Match spaces and periods
+	return re.ReplaceAllString(cardName, "")
+}
+
+// newRouter returns the gin engine Agents are mounted on
+func newRouter(l logger.Logger) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 
-	router.Use(gin.Recovery())
+	router.Use(recovery(l))
 
 	// TODO(daniel) check for Authentication, agent card provides information about the method
 	// that should be used to Auth and then Use the proper Middleware
@@ -104,42 +220,101 @@ func (a *Agent) SwitchOn() {
 	// 		"admin": "admin123", // username : admin, password : admin123
 	// 	}))
 
+	return router
+}
+
+// mount verifies the Agent, starts its workers and registers its routes on the
+// router, the AgentCard is served at cardPath
+func (a *Agent) mount(router gin.IRoutes, cardPath string) agentPaths {
+	// every advertised skill must be served by a handler
+	if err := a.verifySkills(); err != nil {
+		log.Fatalln(err)
+	}
+
 	// check if the Agent supports streaming
-	streamingSupported := a.options.AgentStreamHandler != nil && a.options.AgentCard.Capabilities.Streaming
+	streamingSupported := a.streamingSupported()
 
 	a.options.Logger.Log(logger.InfoLevel, fmt.Sprintf("streamingSupported: %v", streamingSupported))
 
 	// build endpoints based on AgentCard.Name and AgentCard.Capabilities.Streaming
-	var path, pathStream string
-	re := regexp.MustCompile(`[ .]`) // Match spaces and periods
-	modified := re.ReplaceAllString(a.options.AgentCard.Name, "")
-	path, err := url.JoinPath("/", modified)
+	paths := agentPaths{Card: cardPath}
+	path, err := url.JoinPath("/", a.name())
 	if err != nil {
 		log.Fatalln(err)
 	}
+	paths.RPC = path
 
 	if streamingSupported {
-		pathStream, err = url.JoinPath("/", modified, "/stream")
+		paths.Stream, err = url.JoinPath("/", a.name(), "/stream")
 		if err != nil {
 			log.Fatalln(err)
 		}
 	}
 
-	router.POST(path, agentHandler(a))
-	if streamingSupported {
-		router.POST(pathStream, agentHandler(a))
-		router.GET(pathStream, sseHeadersMiddleware(), streamHandlerMiddleware(a), agentStreamHandler(a))
+	if a.options.WebSocket {
+		paths.WebSocket, err = url.JoinPath("/", a.name(), "/ws")
+		if err != nil {
+			log.Fatalln(err)
+		}
 	}
 
-	hd := a.Server.NewHandler(router)
-	if err := a.Server.Handle(hd); err != nil {
+	router.GET(paths.Card, func(c *gin.Context) {
+		c.JSON(http.StatusOK, a.options.AgentCard)
+	})
+
+	a.routes(router, paths)
+
+	a.local = a.localRouter(paths)
+	if a.options.WebSocket {
+		router.GET(paths.WebSocket, webSocketHandler(a, paths))
+	}
+	if err := a.serveGRPC(paths); err != nil {
 		log.Fatalln(err)
 	}
 
+	a.executor.start()
+	a.sweeper.run()
+	a.pusher.run()
+
+	if a.options.Broker != nil {
+		if err := a.options.Broker.Connect(); err != nil {
+			a.options.Logger.Log(logger.ErrorLevel, err)
+		}
+	}
+	a.replica.run()
+
+	// the tasks a previous shutdown left resumable run again
+	if a.options.ShutdownPolicy == ShutdownResumable {
+		go a.resumeInterrupted()
+	}
+
+	return paths
+}
+
+// routes registers the JSON-RPC and streaming routes of the Agent
+func (a *Agent) routes(router gin.IRoutes, paths agentPaths) {
+	router.POST(paths.RPC, instrumentMiddleware(a), agentHandler(a))
+	if paths.Stream != "" {
+		router.POST(paths.Stream, instrumentMiddleware(a), agentHandler(a))
+		router.GET(paths.Stream, sseHeadersMiddleware(), instrumentMiddleware(a), streamHandlerMiddleware(a), agentStreamHandler(a))
+	}
+}
+
+// streamingSupported reports whether the Agent has a stream handler and advertises
+// the streaming capability
+func (a *Agent) streamingSupported() bool {
+	hasStreamHandler := a.options.AgentStreamHandler != nil || len(a.options.SkillStreamHandlers) > 0
+	return hasStreamHandler && a.options.AgentCard.Capabilities != nil && a.options.AgentCard.Capabilities.Streaming
+}
+
+// runService runs the go-micro service around the server until it is stopped,
+// beforeStop drains the Agents while the server still answers
+func runService(srv server.Server, l logger.Logger, reg registry.Registry, beforeStop func() error) {
 	service := micro.NewService(
-		micro.Server(a.Server),
-		micro.Registry(registry.NewRegistry()),
-		micro.Logger(a.options.Logger),
+		micro.Server(srv),
+		micro.Registry(reg),
+		micro.Logger(l),
+		micro.BeforeStop(beforeStop),
 	)
 
 	service.Init()
@@ -157,58 +332,171 @@ func agentHandler(a *Agent) gin.HandlerFunc {
 			return
 		}
 
-		a.options.Logger.Log(logger.InfoLevel, r)
+		a.logPayload("request", r)
+		c.Set(rpcMethodKey, string(r.Method))
+
+		// tasks are admitted once their skill is known, all their limits at once
+		if r.Method != TasksSend && r.Method != TasksSendSubscribe && !a.admit(c, a.requestScopes(r.Method)) {
+			return
+		}
+
+		// the span of the request is the parent of the task span handlers receive in
+		// their context, the calls they make to other agents join the trace
+		trace.SpanFromContext(c.Request.Context()).SetName(string(r.Method))
+		annotate(c, requestAttributes(r)...)
 
 		switch r.Method {
 		case TasksSend:
-			_, ok := (r.Params).(TaskSendParams)
+			a.sendTask(c, r)
+
+		case TasksSendSubscribe:
+			// check if id exitst in JSONRPCRequest
+			if r.ID == nil {
+				e := NewError(ErrorInvalidRequest, "ID shouldn't be nil", nil)
+				c.JSON(http.StatusBadRequest, e)
+				return
+			}
+
+			key, err := a.streamRequestKey(c.Request, r.ID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, err)
+				return
+			}
+
+			params, ok := (r.Params).(TaskSendParams)
 			if !ok {
 				e := NewError(ErrorInvalidRequest, "request should include a TaskSendParams as params", nil)
 				c.JSON(http.StatusBadRequest, e)
 				return
 			}
 
-			if a.options.AgentHandler == nil {
-				e := NewError(ErrorInternal, "the Agent doesn't implement AgentHandler", nil)
+			if a.isDraining() {
+				c.JSON(http.StatusServiceUnavailable, errShuttingDown())
+				return
+			}
+
+			skillID, err := a.routeSkill(params)
+			if e, ok := err.(JSONRPCError); ok {
+				c.JSON(http.StatusBadRequest, e)
+				return
+			}
+			if err != nil {
+				e := NewError(ErrorInternal, err.Error(), nil)
 				c.JSON(http.StatusInternalServerError, e)
 				return
 			}
 
-			res := (*a.options.AgentHandler).TaskHandler(r)
-			if res.Error != nil {
-				c.JSON(http.StatusBadRequest, res.Error)
+			annotate(c, skillAttributes(skillID)...)
+
+			if !a.admit(c, a.taskScopes(r.Method, skillID)) {
 				return
 			}
-			c.JSON(http.StatusOK, res)
 
-		case TasksSendSubscribe:
-			// check if id exitst in JSONRPCRequest
-			if r.ID == nil {
-				e := NewError(ErrorInvalidRequest, "ID shouldn't be nil", nil)
+			if _, ok := a.streamHandler(skillID); !ok {
+				e := NewError(ErrorUnsupportedOperation, "the skill doesn't support streaming", map[string]any{"skillId": skillID})
 				c.JSON(http.StatusBadRequest, e)
 				return
 			}
 
-			// save it in the store key=id | value=JSONRPCRequest
-			rawReq, err := json.Marshal(r)
+			// the task stays submitted until the stream is opened and a worker picks it up
+			_, params, err = a.prepareTask(params, skillID, a.caller(c.Request))
+			if e, ok := err.(JSONRPCError); ok {
+				c.JSON(http.StatusBadRequest, e)
+				return
+			}
 			if err != nil {
-				e := NewError(ErrorInternal, "faild to Marshal request", nil)
+				e := NewError(ErrorInternal, err.Error(), nil)
 				c.JSON(http.StatusInternalServerError, e)
 				return
 			}
+			r.Params = params
+			annotate(c, AttributeTaskID.String(params.ID))
 
-			err = a.options.Store.Write(&store.Record{Key: fmt.Sprintf("%v", r.ID), Value: rawReq, Expiry: time.Second * 60})
+			// a follow-up for a paused handler resumes it, the stream opened for this
+			// request replays the task from there, from the running handler or from
+			// its replica, even once the handler returned
+			_, meta, err := a.loadTaskRecord(params.ID)
 			if err != nil {
 				e := NewError(ErrorInternal, err.Error(), nil)
 				c.JSON(http.StatusInternalServerError, e)
 				return
 			}
 
+			resumed := false
+			if run, ok := a.claimPaused(params.ID); ok {
+				resumed = run.deliver(params.Message)
+			} else if _, ok := a.remoteOwner(params.ID); ok {
+				if err := a.resumeRemote(c.Request.Context(), params.ID, params.Message, false); err != nil {
+					e := NewError(ErrorInternal, err.Error(), nil)
+					c.JSON(http.StatusInternalServerError, e)
+					return
+				}
+				resumed = true
+			}
+
+			var after int64
+			if resumed {
+				r = JSONRPCRequest{JSONRPC: "2.0", ID: r.ID, Method: TasksResubscribe, Params: TaskIDParams{ID: params.ID}}
+				after = meta.seq
+			}
+
+			// save it in the store key=stream/<caller>/<id> | value=JSONRPCRequest
+			if err := a.storeStreamRequest(key, r, after); err != nil {
+				e := NewError(ErrorInternal, err.Error(), nil)
+				c.JSON(http.StatusInternalServerError, e)
+				return
+			}
+
 			// return OK
 			c.JSON(http.StatusOK, nil)
 
 		case TasksGet:
+			params, ok := (r.Params).(TaskQueryParams)
+			if !ok {
+				e := NewError(ErrorInvalidRequest, "request should include a TaskQueryParams as params", nil)
+				c.JSON(http.StatusBadRequest, e)
+				return
+			}
+
+			task, err := a.loadTask(params.ID)
+			if err == store.ErrNotFound {
+				e := NewError(ErrorTaskNotFound, "task not found", map[string]any{"id": params.ID})
+				c.JSON(http.StatusNotFound, e)
+				return
+			}
+			if err != nil {
+				e := NewError(ErrorInternal, err.Error(), nil)
+				c.JSON(http.StatusInternalServerError, e)
+				return
+			}
+
+			trimHistory(task, params.HistoryLength)
+
+			// agents advertising the capability return the statuses the task went through
+			if caps := a.options.AgentCard.Capabilities; caps != nil && caps.StateTransitionHistory {
+				history, err := a.StatusHistory(task.ID)
+				if err != nil && err != store.ErrNotFound {
+					e := NewError(ErrorInternal, err.Error(), nil)
+					c.JSON(http.StatusInternalServerError, e)
+					return
+				}
+				setMetadata(task, StateTransitionHistoryKey, history)
+			}
+
+			c.JSON(http.StatusOK, JSONRPCResponse{JSONRPC: "2.0", ID: r.ID, Result: task})
+
+		case TasksList:
+			a.listTasksHandler(c, r)
+
 		case TasksCancel:
+			a.cancelTask(c, r)
+
+		case TasksResubscribe:
+			a.resubscribeTask(c, r)
+
+		case TasksPushNotificationGet, TasksPushNotificationSet:
+			a.pushNotificationHandler(c, r)
+
 		default:
 			e := NewError(ErrorInvalidRequest, "unsupported A2A method", nil)
 			c.JSON(http.StatusInternalServerError, e)
@@ -229,15 +517,95 @@ func agentStreamHandler(a *Agent) gin.HandlerFunc {
 			return
 		}
 
-		c.Stream(func(w io.Writer) bool {
-			if result, ok := <-resultChan; ok {
-				a.options.Logger.Log(logger.InfoLevel, result)
-				c.SSEvent("message", result)
-				return true
+		a.streams.Add(1)
+		defer a.streams.Add(-1)
+
+		// the events are taken off the channel as they come so a slow client doesn't
+		// hold the producer, the SlowConsumerPolicy applies once the queue is full.
+		// Everything stops when the client goes away.
+		ctx := c.Request.Context()
+		queue := newStreamQueue(a.streamBuffer(), a.options.SlowConsumerPolicy)
+
+		go func() {
+			defer queue.close()
+
+			for {
+				select {
+				case result, ok := <-resultChan:
+					if !ok || !queue.push(result) {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		// the streams of the WebSocket sessions and of the gRPC binding get their
+		// events as they are, the others as SSE
+		sink, local := streamSinkFrom(ctx)
+		if !local {
+			sink = func(result JSONRPCResponse) error {
+				writeEvent(c, result)
+				return nil
+			}
+		}
+
+		// idle SSE streams get a comment now and then so proxies keep them open
+		interval := a.streamHeartbeat()
+		var ticker *time.Ticker
+		var heartbeat <-chan time.Time
+		if interval > 0 && !local {
+			ticker = time.NewTicker(interval)
+			defer ticker.Stop()
+			heartbeat = ticker.C
+		}
+
+		for {
+			select {
+			case <-queue.ready:
+				events, done := queue.take()
+				for _, result := range events {
+					a.logPayload("event", result)
+					if err := sink(result); err != nil {
+						return
+					}
+				}
+				if !local {
+					c.Writer.Flush()
+				}
+				if ticker != nil {
+					ticker.Reset(interval)
+				}
+
+				if done {
+					if dropped := queue.droppedEvents(); dropped > 0 {
+						a.options.Logger.Log(logger.WarnLevel, fmt.Sprintf("%d events of stream %s dropped for a slow client", dropped, c.Query("id")))
+					}
+					return
+				}
+
+			case <-heartbeat:
+				if _, err := io.WriteString(c.Writer, ": keepalive\n\n"); err != nil {
+					return
+				}
+				c.Writer.Flush()
+
+			case <-ctx.Done():
+				return
 			}
-			return false
-		})
+		}
+	}
+}
+
+// writeEvent writes the response as an SSE message, with its position in the event
+// log of the task as the event ID when it has one, see eventPos
+func writeEvent(c *gin.Context, result JSONRPCResponse) {
+	event := sse.Event{Event: "message", Data: result}
+	if result.eventID.seq > 0 {
+		event.Id = result.eventID.String()
 	}
+	c.Render(-1, event)
 }
 
 func streamHandlerMiddleware(a *Agent) gin.HandlerFunc {
@@ -251,8 +619,14 @@ func streamHandlerMiddleware(a *Agent) gin.HandlerFunc {
 			return
 		}
 
+		key, err := a.streamRequestKey(c.Request, id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
+
 		// check if id exists in store
-		record, err := a.options.Store.Read(id)
+		record, err := a.options.Store.Read(key)
 		if err != nil {
 			e := NewError(ErrorInternal, "request id no found", nil)
 			c.JSON(http.StatusInternalServerError, e)
@@ -270,7 +644,92 @@ func streamHandlerMiddleware(a *Agent) gin.HandlerFunc {
 
 		// a channel for sending back results
 		results := make(ResultChan, 1)
-		go (*a.options.AgentStreamHandler).StreamHandler(r, results)
+
+		ctx := c.Request.Context()
+		annotate(c, requestAttributes(r)...)
+
+		// a client reconnecting resumes the stream after the last event it received,
+		// the stream of a follow-up after the events preceding it
+		after, reconnecting := lastEventID(c.Request.Header)
+		if seq, ok := streamRequestAfter(record[0]); ok && !reconnecting {
+			after, reconnecting = afterEvent(seq), true
+		}
+
+		if r.Method == TasksResubscribe {
+			params, _ := (r.Params).(TaskIDParams)
+			a.keepStreamRequest(key, r.ID, params.ID)
+			a.resubscribeStream(c, r.ID, params.ID, after, reconnecting)
+			return
+		}
+
+		params, _ := (r.Params).(TaskSendParams)
+		a.keepStreamRequest(key, r.ID, params.ID)
+
+		if reconnecting {
+			a.resubscribeStream(c, r.ID, params.ID, after, true)
+			return
+		}
+
+		// a stream for a task whose handler is still running, e.g. one resumed after
+		// a pause, subscribes to it instead of invoking the handler again
+		if run, ok := a.liveRun(params.ID); ok {
+			run.attach(ctx, r.ID, results)
+			c.Set("resultChan", results)
+			c.Next()
+			return
+		}
+
+		// the handler runs on another replica, its events come from the broker
+		if _, ok := a.remoteOwner(params.ID); ok {
+			a.relayStream(c, r.ID, params.ID, eventPos{}, false)
+			return
+		}
+
+		task, err := a.loadTask(params.ID)
+		if err != nil {
+			e := NewError(ErrorTaskNotFound, "task not found", map[string]any{"id": params.ID})
+			c.JSON(http.StatusNotFound, e)
+			c.Abort()
+			return
+		}
+
+		skillID := taskSkill(task)
+		annotate(c, skillAttributes(skillID)...)
+
+		handler, ok := a.streamHandler(skillID)
+		if !ok {
+			e := NewError(ErrorUnsupportedOperation, "the skill doesn't support streaming", map[string]any{"skillId": skillID})
+			c.JSON(http.StatusBadRequest, e)
+			c.Abort()
+			return
+		}
+
+		// the task was accepted but the Agent stopped before its stream was opened
+		if a.isDraining() {
+			a.interruptTask(params.ID)
+			c.JSON(http.StatusServiceUnavailable, errShuttingDown())
+			c.Abort()
+			return
+		}
+
+		// another stream may have started the handler meanwhile
+		run, ok := a.joinRun(ctx, params.ID, r.ID, skillID)
+		run.attach(ctx, r.ID, results)
+		if !ok {
+			c.Set("resultChan", results)
+			c.Next()
+			return
+		}
+		run.requested = requestDeadline(c.Request.Header, params)
+
+		_, err = a.executor.submit(run, a.streamJob(run, handler, r))
+		if err != nil {
+			a.endRun(run)
+			a.rejectTask(params.ID)
+			c.JSON(http.StatusServiceUnavailable, err)
+			c.Abort()
+			return
+		}
 
 		c.Set("resultChan", results)
 
@@ -288,26 +747,222 @@ func sseHeadersMiddleware() gin.HandlerFunc {
 	}
 }
 
-func updateRecord(key string, status string, rec []*store.Record, newValue Result) (*store.Record, error) {
-	vRaw := *&rec[0].Value
-	var v map[int64]Result
-	err := json.Unmarshal(vRaw, &v)
+// sendTask handles tasks/send, the request waits for the handler to return or to
+// pause and replies with the task
+func (a *Agent) sendTask(c *gin.Context, r JSONRPCRequest) {
+	params, ok := (r.Params).(TaskSendParams)
+	if !ok {
+		e := NewError(ErrorInvalidRequest, "request should include a TaskSendParams as params", nil)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
 
-	now := time.Now().Unix()
+	if a.isDraining() {
+		c.JSON(http.StatusServiceUnavailable, errShuttingDown())
+		return
+	}
 
-	v[now] = newValue
-	valuesAsBytes, err := json.Marshal(v)
+	skillID, err := a.routeSkill(params)
+	if e, ok := err.(JSONRPCError); ok {
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
 	if err != nil {
-		return nil, err
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	annotate(c, skillAttributes(skillID)...)
+
+	if !a.admit(c, a.taskScopes(r.Method, skillID)) {
+		return
 	}
-	record := store.Record{
-		Key:      key,
-		Expiry:   time.Second * 60,
-		Value:    valuesAsBytes,
-		Metadata: map[string]interface{}{"status": status},
+
+	handler, ok := a.taskHandler(skillID)
+	if !ok {
+		e := NewError(ErrorInternal, "the Agent doesn't implement AgentHandler", map[string]any{"skillId": skillID})
+		c.JSON(http.StatusInternalServerError, e)
+		return
 	}
 
-	return &record, nil
+	// a retry of a request that was served already gets its outcome, the handler
+	// isn't invoked again
+	key := submissionKey(params, r.ID, a.caller(c.Request))
+	if key != "" {
+		prev, err := a.claimSubmission(key, params)
+		if e, ok := err.(JSONRPCError); ok {
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		if err != nil {
+			e := NewError(ErrorInternal, err.Error(), nil)
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+		if prev != nil {
+			annotate(c, AttributeTaskID.String(prev.TaskID))
+			a.replaySubmission(c, key, prev, r.ID)
+			return
+		}
+	}
+
+	// the task waits in the submitted state until a worker picks it up
+	_, params, err = a.prepareTask(params, skillID, a.caller(c.Request))
+	if e, ok := err.(JSONRPCError); ok {
+		a.releaseSubmission(key)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+	if err != nil {
+		a.releaseSubmission(key)
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+	r.Params = params
+	annotate(c, AttributeTaskID.String(params.ID))
+
+	// a follow-up for a paused handler resumes it instead of invoking it again
+	if run, ok := a.claimPaused(params.ID); ok {
+		if run.deliver(params.Message) {
+			run.serve(key)
+			run.wait(c.Request.Context())
+		}
+		a.reply(c, run, r.ID)
+		return
+	}
+
+	// the same goes for a handler running on another replica, the task is replied
+	// once the handler paused again or returned
+	if _, ok := a.remoteOwner(params.ID); ok {
+		if err := a.resumeRemote(c.Request.Context(), params.ID, params.Message, true); err != nil {
+			a.releaseSubmission(key)
+			e := NewError(ErrorInternal, err.Error(), nil)
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+
+		task, err := a.loadTask(params.ID)
+		if err != nil {
+			a.releaseSubmission(key)
+			e := NewError(ErrorInternal, err.Error(), nil)
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+
+		res := JSONRPCResponse{JSONRPC: "2.0", ID: r.ID, Result: task}
+		a.completeSubmission(key, task.ID, res)
+		a.respond(c, res)
+		return
+	}
+
+	run := a.startRun(c.Request.Context(), params.ID, r.ID, skillID)
+	run.requested = requestDeadline(c.Request.Header, params)
+	run.serve(key)
+	_, err = a.executor.submit(run, a.unaryJob(run, handler, r))
+	if err != nil {
+		a.endRun(run)
+		a.rejectTask(params.ID)
+		a.releaseSubmission(key)
+		c.JSON(http.StatusServiceUnavailable, err)
+		return
+	}
+
+	run.wait(c.Request.Context())
+	a.reply(c, run, r.ID)
+}
+
+// reply answers a unary request with the outcome of the run
+func (a *Agent) reply(c *gin.Context, run *taskRun, reqID any) {
+	res, err := run.response(reqID)
+	if err != nil {
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	a.respond(c, res)
+}
+
+// respond writes the response of a unary request, errors are replied as is with a
+// status matching their code
+func (a *Agent) respond(c *gin.Context, res JSONRPCResponse) {
+	switch {
+	case res.Error != nil && res.Error.Code == ErrorTimeout:
+		c.JSON(http.StatusGatewayTimeout, res.Error)
+	case res.Error != nil && res.Error.Code == ErrorInternal:
+		c.JSON(http.StatusInternalServerError, res.Error)
+	case res.Error != nil:
+		c.JSON(http.StatusBadRequest, res.Error)
+	default:
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// recordResult stores the outcome of a TaskHandler call on the task, the task
+// keeps the ID it was submitted with whatever ID the handler returns
+func (a *Agent) recordResult(taskID string, res JSONRPCResponse) {
+	task, err := a.loadTask(taskID)
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		return
+	}
+
+	if res.Error != nil {
+		task.Status = TaskStatus{State: TaskStateFailed}
+	} else if t, ok := resultTask(res.Result); ok {
+		t.ID = task.ID
+		if t.ContextID == "" {
+			t.ContextID = task.ContextID
+		}
+		if len(t.History) < len(task.History) {
+			t.History = task.History
+		}
+		for k, v := range task.Metadata {
+			if _, ok := t.Metadata[k]; !ok {
+				setMetadata(t, k, v)
+			}
+		}
+		task = t
+	}
+
+	if err := a.saveTask(task); err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	}
+}
+
+// recordEvent projects a StreamHandler response onto the stored task, it returns
+// the sequence number of the last event of the task, 0 when it wasn't recorded
+func (a *Agent) recordEvent(taskID string, res JSONRPCResponse) int64 {
+	if taskID == "" {
+		return 0
+	}
+
+	task, err := a.loadTask(taskID)
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		return 0
+	}
+
+	if res.Error != nil {
+		task.Status = TaskStatus{State: TaskStateFailed}
+	} else {
+		applyEvent(task, res.Result)
+	}
+
+	seq, err := a.storeTask(task)
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	}
+	return seq
+}
+
+// rejectTask marks a task that could not be queued as rejected
+func (a *Agent) rejectTask(taskID string) {
+	if _, err := a.setTaskState(taskID, TaskStateRejected); err != nil && err != store.ErrNotFound {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	}
 }
 
 func newErrRes(r *JSONRPCRequest, e *JSONRPCError) *SSEResponse {
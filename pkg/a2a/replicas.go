@@ -0,0 +1,494 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go-micro.dev/v5/broker"
+	"go-micro.dev/v5/logger"
+	"go-micro.dev/v5/store"
+)
+
+// Agents with the same AgentCard sharing a store and a broker are replicas of one
+// agent, a load balancer may send the requests of a task to any of them. The
+// replica running the handler of a task owns it: the other ones relay its events
+// from the broker to their streams and forward the cancellations and follow-up
+// messages to it on the control topic of the agent. The store keeps:
+//
+//	replica/<replica>   the replicas alive, written with their lease as expiry
+//	owner/<task>        the replica running the handler of the task, written with
+//	                    the lease as expiry and renewed while the handler runs
+//
+// Without a broker the Agent runs alone and keeps none of them.
+const (
+	replicaKeyPrefix = "replica/"
+	ownerKeyPrefix   = "owner/"
+)
+
+// DefaultReplicaLease is how long a replica is deemed alive after it last announced
+// itself when the WithReplicaLease option is not provided, it announces itself
+// three times per lease
+const DefaultReplicaLease = 30 * time.Second
+
+func replicaKey(id string) string {
+	return replicaKeyPrefix + id
+}
+
+func ownerKey(taskID string) string {
+	return ownerKeyPrefix + taskID
+}
+
+// controlTopic is where the replicas of the agent send each other control messages
+func controlTopic(agentName string) string {
+	return "a2a." + serviceName(agentName) + ".control"
+}
+
+type controlAction string
+
+const (
+	controlCancel controlAction = "cancel" // cancel the task
+	controlResume controlAction = "resume" // resume the paused handler with Message
+)
+
+// controlMessage asks the replica running the task to act on it
+type controlMessage struct {
+	Action  controlAction `json:"action"`
+	TaskID  string        `json:"taskId"`
+	Replica string        `json:"replica"`
+	Message *Message      `json:"message,omitempty"`
+}
+
+func (a *Agent) replicaLease() time.Duration {
+	if a.options.ReplicaLease > 0 {
+		return a.options.ReplicaLease
+	}
+	return DefaultReplicaLease
+}
+
+// replica announces the Agent to the other replicas and listens to their control
+// messages in the background
+type replica struct {
+	agent *Agent
+	sub   broker.Subscriber
+
+	start sync.Once
+	stop  sync.Once
+	ctx   context.Context
+	halt  context.CancelFunc
+	done  chan struct{}
+}
+
+func newReplica(a *Agent) *replica {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &replica{agent: a, ctx: ctx, halt: cancel, done: make(chan struct{})}
+}
+
+// run starts announcing the replica, calling it more than once has no effect
+func (r *replica) run() {
+	r.start.Do(func() {
+		a := r.agent
+		if a.options.Broker == nil {
+			close(r.done)
+			return
+		}
+
+		sub, err := a.options.Broker.Subscribe(controlTopic(a.options.AgentCard.Name), a.control)
+		if err != nil {
+			a.options.Logger.Log(logger.ErrorLevel, err)
+		}
+		r.sub = sub
+
+		a.announce()
+
+		go func() {
+			defer close(r.done)
+
+			ticker := time.NewTicker(a.replicaLease() / 3)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					a.announce()
+					a.renewOwnership()
+				case <-r.ctx.Done():
+					return
+				}
+			}
+		}()
+	})
+}
+
+// close stops listening and withdraws the replica, the other ones consider the
+// tasks it still owns abandoned
+func (r *replica) close() {
+	r.stop.Do(func() {
+		r.halt()
+
+		started := true
+		r.start.Do(func() { started = false })
+		if !started {
+			return
+		}
+		<-r.done
+
+		if r.sub != nil {
+			if err := r.sub.Unsubscribe(); err != nil {
+				r.agent.options.Logger.Log(logger.ErrorLevel, err)
+			}
+		}
+
+		if r.agent.options.Broker != nil {
+			r.agent.deleteRecord(replicaKey(r.agent.options.ReplicaID))
+		}
+	})
+}
+
+// announce renews the lease of the replica
+func (a *Agent) announce() {
+	err := a.options.Store.Write(&store.Record{
+		Key:    replicaKey(a.options.ReplicaID),
+		Value:  []byte(a.options.ReplicaID),
+		Expiry: a.replicaLease(),
+	})
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	}
+}
+
+// own records that the replica runs the handler of the task, the record expires
+// with the lease of the replica unless it is renewed
+func (a *Agent) own(taskID string) {
+	if a.options.Broker == nil {
+		return
+	}
+
+	err := a.options.Store.Write(&store.Record{
+		Key:    ownerKey(taskID),
+		Value:  []byte(a.options.ReplicaID),
+		Expiry: a.replicaLease(),
+	})
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	}
+}
+
+// renewOwnership renews the owner records of the tasks the replica runs. The runs
+// stay registered meanwhile, a run ending disowns its task after the renewal.
+func (a *Agent) renewOwnership() {
+	a.runsMu.Lock()
+	defer a.runsMu.Unlock()
+
+	for taskID := range a.runs {
+		a.own(taskID)
+	}
+}
+
+// disown forgets that the replica runs the task, unless another replica took the
+// task over meanwhile
+func (a *Agent) disown(taskID string) {
+	if a.options.Broker == nil {
+		return
+	}
+
+	records, err := a.options.Store.Read(ownerKey(taskID))
+	if err != nil || len(records) == 0 || string(records[0].Value) != a.options.ReplicaID {
+		return
+	}
+	a.deleteRecord(ownerKey(taskID))
+}
+
+// remoteOwner returns the replica running the handler of the task when it is
+// another replica that is still alive
+func (a *Agent) remoteOwner(taskID string) (string, bool) {
+	if a.options.Broker == nil {
+		return "", false
+	}
+
+	records, err := a.options.Store.Read(ownerKey(taskID))
+	if err != nil || len(records) == 0 {
+		return "", false
+	}
+
+	owner := string(records[0].Value)
+	if owner == a.options.ReplicaID {
+		return "", false
+	}
+
+	if records, err := a.options.Store.Read(replicaKey(owner)); err != nil || len(records) == 0 {
+		return "", false
+	}
+
+	return owner, true
+}
+
+// forward sends the control message to the replica running the task
+func (a *Agent) forward(msg controlMessage) error {
+	msg.Replica = a.options.ReplicaID
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return a.options.Broker.Publish(controlTopic(a.options.AgentCard.Name), &broker.Message{
+		Header: map[string]string{"Content-Type": "application/json"},
+		Body:   body,
+	})
+}
+
+// control receives the control messages of the other replicas, only the replica
+// running the task acts on them. The sender isn't held while it does.
+func (a *Agent) control(e broker.Event) error {
+	var msg controlMessage
+	if err := json.Unmarshal(e.Message().Body, &msg); err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		return nil
+	}
+
+	if msg.Replica == a.options.ReplicaID {
+		return nil
+	}
+	if _, ok := a.liveRun(msg.TaskID); !ok {
+		return nil
+	}
+
+	go func() {
+		switch msg.Action {
+		case controlCancel:
+			if run, ok := a.liveRun(msg.TaskID); ok {
+				a.cancelRun(run)
+			}
+
+		case controlResume:
+			if msg.Message == nil {
+				return
+			}
+			if run, ok := a.claimPaused(msg.TaskID); ok {
+				run.deliver(*msg.Message)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// taskUpdate is an event of a task received from the broker
+type taskUpdate struct {
+	env   EventEnvelope
+	event Result
+}
+
+// the updates of a task a watcher keeps before it falls behind
+const watchBuffer = 16
+
+// taskWatch receives the events of a task published by the replica running it. The
+// broker, and the replica publishing through it, is never held: the updates a
+// watcher that fell behind has no room for are dropped and lagged is signalled,
+// the watcher reads what it missed from the store, where the events are logged
+// before they are published.
+type taskWatch struct {
+	updates chan taskUpdate
+	lagged  chan struct{}
+}
+
+// watchTask watches the events of the task until ctx is done
+func (a *Agent) watchTask(ctx context.Context, taskID string) (*taskWatch, error) {
+	w := &taskWatch{updates: make(chan taskUpdate, watchBuffer), lagged: make(chan struct{}, 1)}
+
+	sub, err := a.options.Broker.Subscribe(a.topic(taskID), func(e broker.Event) error {
+		env, event, err := DecodeEvent(e.Message())
+		if err != nil || env.TaskID != taskID {
+			return nil
+		}
+
+		select {
+		case w.updates <- taskUpdate{env: env, event: event}:
+		default:
+			select {
+			case w.lagged <- struct{}{}:
+			default:
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := sub.Unsubscribe(); err != nil {
+			a.options.Logger.Log(logger.ErrorLevel, err)
+		}
+	}()
+
+	return w, nil
+}
+
+// awaitStatus waits for a status of the task logged after the sequence number
+// that satisfies reached, it returns false when ctx is done first
+func (a *Agent) awaitStatus(ctx context.Context, taskID string, w *taskWatch, seq int64, reached func(TaskState) bool) bool {
+	for {
+		select {
+		case u := <-w.updates:
+			e, ok := u.event.(TaskStatusUpdateEvent)
+			if ok && u.env.Seq > seq && reached(e.Status.State) {
+				return true
+			}
+		case <-w.lagged:
+			// the status the task is in tells as much as the updates dropped
+			t, meta, err := a.loadTaskRecord(taskID)
+			if err == nil && meta.seq > seq && reached(t.Status.State) {
+				return true
+			}
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// resumeRemote hands the follow-up message over to the paused handler of the task
+// running on another replica, it waits until the handler pauses again or returns
+// when wait is set
+func (a *Agent) resumeRemote(ctx context.Context, taskID string, msg Message, wait bool) error {
+	if !wait {
+		return a.forward(controlMessage{Action: controlResume, TaskID: taskID, Message: &msg})
+	}
+
+	_, meta, err := a.loadTaskRecord(taskID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w, err := a.watchTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.forward(controlMessage{Action: controlResume, TaskID: taskID, Message: &msg}); err != nil {
+		return err
+	}
+
+	a.awaitStatus(ctx, taskID, w, meta.seq, func(state TaskState) bool {
+		return isPaused(state) || isTerminal(state)
+	})
+	return nil
+}
+
+// relayTask streams a task the Agent doesn't run the handler of: the stream gets
+// the backlog of the task and then the events published by the replica running
+// it, until the final one. A relay that fell behind reads the events it missed
+// from the store. It ends with them when no replica runs the task, which is
+// checked once per lease.
+func (a *Agent) relayTask(ctx context.Context, taskID string, reqID any, results ResultChan, after eventPos, replay bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var w *taskWatch
+	if a.options.Broker != nil {
+		var err error
+		if w, err = a.watchTask(ctx, taskID); err != nil {
+			cancel()
+			return err
+		}
+	}
+
+	// the backlog goes first, the events published meanwhile are already part of it
+	backlog, seq, err := a.backlog(taskID, reqID, after, replay)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	send := func(res JSONRPCResponse) bool {
+		select {
+		case results <- res:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	orphaned := func() bool {
+		_, remote := a.remoteOwner(taskID)
+		_, local := a.liveRun(taskID)
+		return !remote && !local
+	}
+
+	go func() {
+		defer cancel()
+		defer close(results)
+
+		// last is the position of the last update relayed, the updates after it are
+		// read from the store when the relay fell behind or the task has no replica
+		// running it anymore
+		last := afterEvent(seq)
+		catchUp := func() bool {
+			missed, upTo, err := a.backlog(taskID, reqID, last, true)
+			if err != nil {
+				a.options.Logger.Log(logger.ErrorLevel, err)
+				return false
+			}
+			for _, res := range missed {
+				if !send(res) || isFinalEvent(res) {
+					return false
+				}
+			}
+			seq, last = upTo, afterEvent(upTo)
+			return true
+		}
+
+		for _, res := range backlog {
+			if !send(res) || isFinalEvent(res) {
+				return
+			}
+		}
+		if w == nil {
+			return
+		}
+		if orphaned() {
+			catchUp()
+			return
+		}
+
+		ticker := time.NewTicker(a.replicaLease())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case u := <-w.updates:
+				if u.env.Seq <= seq {
+					continue
+				}
+				pos := eventPos{seq: u.env.Seq, idx: u.env.Index}
+				res := JSONRPCResponse{JSONRPC: "2.0", ID: reqID, Result: u.event, eventID: pos}
+				if !send(res) || isFinalEvent(res) {
+					return
+				}
+				last = pos
+
+			case <-w.lagged:
+				if !catchUp() {
+					return
+				}
+
+			case <-ticker.C:
+				if orphaned() {
+					catchUp()
+					return
+				}
+			case <-a.replica.ctx.Done():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
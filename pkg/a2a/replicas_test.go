@@ -0,0 +1,169 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	go_sse "github.com/tmaxmax/go-sse"
+	"go-micro.dev/v5/broker"
+	"go-micro.dev/v5/store"
+)
+
+func TestReplicasShareTasks(t *testing.T) {
+	shared := store.NewMemoryStore()
+	b := broker.NewMemoryBroker()
+
+	card := AgentCard{Name: "Replicated", Capabilities: &AgentCapabilities{Streaming: true}}
+	replica := func(id string) (string, string) {
+		a := NewAgent(card, WithStore(shared), WithBroker(b), WithReplicaID(id), WithReplicaLease(300*time.Millisecond), WithAgentStreamHandler(streamHandler{}))
+		srv, paths := serveAgent(t, a)
+		return srv.URL + paths.Stream, srv.URL + paths.RPC
+	}
+	one, _ := replica("one")
+	two, twoRPC := replica("two")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := NewA2AClient()
+
+	// the handler runs on one and pauses for input
+	ask := map[string]any{"ask": true}
+	first, err := c.SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi"), Metadata: ask}, one)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextEvent(t, first, inState(TaskStateInputRequired))
+
+	// two relays the task from the broker
+	second, err := c.SendReqStream(ctx, TasksResubscribe, TaskIDParams{ID: "t1"}, two)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextEvent(t, second, inState(TaskStateInputRequired))
+
+	// the follow-up sent to two resumes the handler on one
+	followUp, err := c.SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t1", Message: textMessage("m2", "Ada")}, two)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, events := range map[string]chan go_sse.Event{"one": first, "two": second, "the follow-up": followUp} {
+		if s := nextEvent(t, events, func(s streamed) bool { return s.text != "" }); s.text != "hello Ada" {
+			t.Fatalf("the stream on %s got the artifact %q, want hello Ada", name, s.text)
+		}
+		nextEvent(t, events, inState(TaskStateCompleted))
+	}
+
+	// a paused handler keeps its task owned past the lease
+	third, err := c.SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t2", Message: textMessage("m3", "hi"), Metadata: ask}, one)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextEvent(t, third, inState(TaskStateInputRequired))
+
+	time.Sleep(time.Second)
+	records, err := shared.Read(ownerKey("t2"))
+	if err != nil || len(records) == 0 || string(records[0].Value) != "one" {
+		t.Fatalf("owner of t2 = %v, %v, want one", records, err)
+	}
+
+	// the cancellation sent to two stops the handler on one
+	res, err := c.SendReq(ctx, TasksCancel, TaskIDParams{ID: "t2"}, twoRPC)
+	if err != nil || res.Error != nil {
+		t.Fatalf("cancel: %v %v", err, res.Error)
+	}
+	nextEvent(t, third, inState(TaskStateCanceled))
+
+	if _, err := shared.Read(ownerKey("t2")); err != store.ErrNotFound {
+		t.Fatalf("the owner record of the canceled task is still there: %v", err)
+	}
+}
+
+// burstHandler pauses for input and then sends more artifacts than a watcher of
+// the task keeps before it falls behind
+type burstHandler struct{}
+
+const burstSize = 4 * watchBuffer
+
+func (burstHandler) StreamHandler(req JSONRPCRequest, out chan JSONRPCResponse) {
+	defer close(out)
+
+	params := req.Params.(TaskSendParams)
+	if _, err := RequestInput(req.Context(), Message{MessageId: "ask", Role: MessageRoleAgent, Parts: []Part{TextPart{Kind: PartTypeText, Text: "go?"}}}); err != nil {
+		return
+	}
+
+	for i := 0; i < burstSize; i++ {
+		id := fmt.Sprintf("a%d", i)
+		out <- JSONRPCResponse{Result: &TaskArtifactUpdateEvent{ID: params.ID, Artifact: Artifact{ArtifactID: id, Parts: []Part{TextPart{Kind: PartTypeText, Text: id}}}}}
+	}
+	out <- JSONRPCResponse{Result: &TaskStatusUpdateEvent{ID: params.ID, Status: TaskStatus{State: TaskStateCompleted}, Final: true}}
+}
+
+func TestSlowRelayDoesNotHoldTheOwner(t *testing.T) {
+	shared := store.NewMemoryStore()
+	b := broker.NewMemoryBroker()
+
+	card := AgentCard{Name: "Bursting", Capabilities: &AgentCapabilities{Streaming: true}}
+	one := NewAgent(card, WithStore(shared), WithBroker(b), WithReplicaID("one"), WithAgentStreamHandler(burstHandler{}))
+	two := NewAgent(card, WithStore(shared), WithBroker(b), WithReplicaID("two"), WithAgentStreamHandler(burstHandler{}))
+	srv, paths := serveAgent(t, one)
+	serveAgent(t, two)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := NewA2AClient()
+
+	first, err := c.SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")}, srv.URL+paths.Stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextEvent(t, first, inState(TaskStateInputRequired))
+
+	// two relays the task to a client that doesn't read for now
+	results := make(ResultChan)
+	if err := two.relayTask(ctx, "t1", 1, results, eventPos{}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// the handler on one gets through its burst all the same
+	followUp, err := c.SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t1", Message: textMessage("m2", "go")}, srv.URL+paths.Stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextEvent(t, followUp, inState(TaskStateCompleted))
+
+	// and the relay catches up from the store without losing or repeating an event
+	var artifacts []string
+	var last JSONRPCResponse
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case res, ok := <-results:
+			if !ok {
+				done = true
+				break
+			}
+			last = res
+			if e, ok := res.Result.(TaskArtifactUpdateEvent); ok {
+				artifacts = append(artifacts, e.Artifact.ArtifactID)
+			}
+		case <-timeout:
+			t.Fatalf("the relay never ended, it got %v", artifacts)
+		}
+	}
+
+	if len(artifacts) != burstSize {
+		t.Fatalf("relayed %d artifacts, want %d: %v", len(artifacts), burstSize, artifacts)
+	}
+	for i, id := range artifacts {
+		if id != fmt.Sprintf("a%d", i) {
+			t.Fatalf("relayed %v, want the artifacts in order", artifacts)
+		}
+	}
+	if e, ok := last.Result.(TaskStatusUpdateEvent); !ok || e.Status.State != TaskStateCompleted {
+		t.Fatalf("the relay ended with %+v, want the completed status", last.Result)
+	}
+}
@@ -0,0 +1,306 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/logger"
+	"go-micro.dev/v5/store"
+)
+
+// resubscribeTask handles tasks/resubscribe, like tasks/sendSubscribe the request is
+// kept until the client opens its stream, which gets the task as it goes on from
+// its current status
+func (a *Agent) resubscribeTask(c *gin.Context, r JSONRPCRequest) {
+	if r.ID == nil {
+		e := NewError(ErrorInvalidRequest, "ID shouldn't be nil", nil)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	key, err := a.streamRequestKey(c.Request, r.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	params, ok := (r.Params).(TaskIDParams)
+	if !ok {
+		e := NewError(ErrorInvalidRequest, "request should include a TaskIDParams as params", nil)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+	annotate(c, AttributeTaskID.String(params.ID))
+
+	if !a.streamingSupported() {
+		e := NewError(ErrorUnsupportedOperation, "the agent doesn't support streaming", nil)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	_, err = a.loadTask(params.ID)
+	if err == store.ErrNotFound {
+		e := NewError(ErrorTaskNotFound, "task not found", map[string]any{"id": params.ID})
+		c.JSON(http.StatusNotFound, e)
+		return
+	}
+	if err != nil {
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if err := a.storeStreamRequest(key, r, 0); err != nil {
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// the stream requests are kept per caller under stream/<caller>/<request ID>, a
+// caller only opens the streams it requested. Those of a WebSocket session are kept
+// under stream/<caller>/<session>/<request ID>, the sessions of a caller choose
+// their request IDs on their own.
+const streamKeyPrefix = "stream/"
+
+// streamSessionKey is the context key of the ID of the session a request belongs to
+type streamSessionKey struct{}
+
+// withStreamSession scopes the stream requests made with ctx to the session
+func withStreamSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, streamSessionKey{}, sessionID)
+}
+
+// streamRequestKey is the key the stream request with the ID is kept under for the
+// caller of r, an ID containing a slash is refused with ErrorInvalidRequest
+func (a *Agent) streamRequestKey(r *http.Request, id any) (string, error) {
+	s := fmt.Sprintf("%v", id)
+	if s == "" || strings.Contains(s, "/") {
+		return "", NewError(ErrorInvalidRequest, "the ID of a streaming request can't be empty or contain a slash", map[string]any{"id": id})
+	}
+
+	key := streamKeyPrefix + url.PathEscape(a.caller(r)) + "/"
+	if session, ok := r.Context().Value(streamSessionKey{}).(string); ok {
+		key += session + "/"
+	}
+
+	return key + s, nil
+}
+
+// storeStreamRequest keeps the request under the key until the client opens the
+// stream for it, any replica sharing the store can serve the stream. A stream that
+// replays the task after a sequence number, a follow-up's, has it as after.
+func (a *Agent) storeStreamRequest(key string, r JSONRPCRequest, after int64) error {
+	rawReq, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to Marshal request: %w", err)
+	}
+
+	record := &store.Record{Key: key, Value: rawReq, Expiry: a.streamRequestTTL()}
+	if after > 0 {
+		record.Metadata = map[string]interface{}{"after": strconv.FormatInt(after, 10)}
+	}
+
+	return a.options.Store.Write(record)
+}
+
+// streamRequestAfter returns the sequence number the stream of the request replays
+// the task after, see storeStreamRequest
+func streamRequestAfter(record *store.Record) (int64, bool) {
+	v, ok := record.Metadata["after"].(string)
+	if !ok {
+		return 0, false
+	}
+
+	seq, err := strconv.ParseInt(v, 10, 64)
+	return seq, err == nil
+}
+
+// DefaultStreamResumeWindow is how long a client may reconnect to a stream it
+// opened when the WithStreamResumeWindow option is not provided
+const DefaultStreamResumeWindow = time.Hour
+
+func (a *Agent) streamResumeWindow() time.Duration {
+	if a.options.StreamResumeWindow > 0 {
+		return a.options.StreamResumeWindow
+	}
+	return DefaultStreamResumeWindow
+}
+
+// keepStreamRequest replaces the request of a stream once it is opened with a
+// tasks/resubscribe request for its task, kept for the resume window, so the client
+// reconnecting resumes the stream instead of invoking the handler again
+func (a *Agent) keepStreamRequest(key string, reqID any, taskID string) {
+	raw, err := json.Marshal(JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      reqID,
+		Method:  TasksResubscribe,
+		Params:  TaskIDParams{ID: taskID},
+	})
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		return
+	}
+
+	err = a.options.Store.Write(&store.Record{Key: key, Value: raw, Expiry: a.streamResumeWindow()})
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	}
+}
+
+// eventPos is where a streamed response is in the event log of its task: the
+// sequence number of the event and the index of the update among the ones the
+// event carries, see eventUpdates. A response standing for the whole event, one the
+// run published or the status of the task, has the index wholeEvent.
+type eventPos struct {
+	seq int64
+	idx int
+}
+
+// wholeEvent is the update index of a response that stands for every update of its
+// event
+const wholeEvent = math.MaxInt
+
+// afterEvent is the position following every update of the event
+func afterEvent(seq int64) eventPos {
+	return eventPos{seq: seq, idx: wholeEvent}
+}
+
+// before reports whether the position precedes q
+func (p eventPos) before(q eventPos) bool {
+	return p.seq < q.seq || (p.seq == q.seq && p.idx < q.idx)
+}
+
+// String returns the SSE event ID of the position, "<seq>.<index>" for an update of
+// the event, "<seq>" for the whole event
+func (p eventPos) String() string {
+	if p.idx == wholeEvent {
+		return strconv.FormatInt(p.seq, 10)
+	}
+	return strconv.FormatInt(p.seq, 10) + "." + strconv.Itoa(p.idx)
+}
+
+// parseEventID parses an SSE event ID, see eventPos.String
+func parseEventID(v string) (eventPos, bool) {
+	seq, idx, sub := strings.Cut(strings.TrimSpace(v), ".")
+
+	p := afterEvent(0)
+	var err error
+	if p.seq, err = strconv.ParseInt(seq, 10, 64); err != nil || p.seq < 0 {
+		return eventPos{}, false
+	}
+	if sub {
+		if p.idx, err = strconv.Atoi(idx); err != nil || p.idx < 0 {
+			return eventPos{}, false
+		}
+	}
+	return p, true
+}
+
+// lastEventID returns the position of the last event a reconnecting client
+// received, see JSONRPCResponse.eventID
+func lastEventID(h http.Header) (eventPos, bool) {
+	v := h.Get("Last-Event-ID")
+	if strings.TrimSpace(v) == "" {
+		return eventPos{}, false
+	}
+	return parseEventID(v)
+}
+
+// backlog is what a stream resuming the task gets before its live events: the
+// updates logged after the position when replay is set, the current status of the
+// task otherwise. It returns the sequence number the backlog goes up to.
+func (a *Agent) backlog(taskID string, reqID any, after eventPos, replay bool) ([]JSONRPCResponse, int64, error) {
+	t, meta, err := a.loadTaskRecord(taskID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !replay {
+		status := &TaskStatusUpdateEvent{ID: t.ID, Status: t.Status, Final: isTerminal(t.Status.State)}
+		return []JSONRPCResponse{{JSONRPC: "2.0", ID: reqID, Result: status, eventID: afterEvent(meta.seq)}}, meta.seq, nil
+	}
+
+	// the event the client got part of the updates of is read again
+	events, err := a.TaskEvents(taskID, max(after.seq-1, 0))
+	if err != nil && err != store.ErrNotFound {
+		return nil, 0, err
+	}
+
+	var backlog []JSONRPCResponse
+	upTo := after.seq
+	for _, e := range events {
+		for i, update := range eventUpdates(taskID, e) {
+			pos := eventPos{seq: e.Seq, idx: i}
+			if !after.before(pos) {
+				continue
+			}
+			backlog = append(backlog, JSONRPCResponse{JSONRPC: "2.0", ID: reqID, Result: update, eventID: pos})
+		}
+		upTo = max(upTo, e.Seq)
+	}
+
+	// a task that ended without a final event, interrupted for instance, still ends
+	// the stream
+	if isTerminal(t.Status.State) && (len(backlog) == 0 || !isFinalEvent(backlog[len(backlog)-1])) {
+		status := &TaskStatusUpdateEvent{ID: t.ID, Status: t.Status, Final: true}
+		backlog = append(backlog, JSONRPCResponse{JSONRPC: "2.0", ID: reqID, Result: status, eventID: afterEvent(meta.seq)})
+	}
+
+	return backlog, max(upTo, meta.seq), nil
+}
+
+// resubscribeStream serves the stream of a tasks/resubscribe request or of a client
+// reconnecting, see backlog. A task whose handler runs on this replica gets the
+// stream attached to it, any other one is relayed from the broker.
+func (a *Agent) resubscribeStream(c *gin.Context, reqID any, taskID string, after eventPos, replay bool) {
+	if run, ok := a.liveRun(taskID); ok {
+		results, err := run.attachBacklog(c.Request.Context(), reqID, after, replay)
+		if err != nil {
+			abortStream(c, taskID, err)
+			return
+		}
+		c.Set("resultChan", results)
+		c.Next()
+		return
+	}
+
+	a.relayStream(c, reqID, taskID, after, replay)
+}
+
+// relayStream serves the stream of a task the Agent doesn't run the handler of
+func (a *Agent) relayStream(c *gin.Context, reqID any, taskID string, after eventPos, replay bool) {
+	results := make(ResultChan, 1)
+
+	if err := a.relayTask(c.Request.Context(), taskID, reqID, results, after, replay); err != nil {
+		abortStream(c, taskID, err)
+		return
+	}
+
+	c.Set("resultChan", results)
+	c.Next()
+}
+
+// abortStream replies with the error that kept the stream of the task from opening
+func abortStream(c *gin.Context, taskID string, err error) {
+	if err == store.ErrNotFound {
+		e := NewError(ErrorTaskNotFound, "task not found", map[string]any{"id": taskID})
+		c.JSON(http.StatusNotFound, e)
+		c.Abort()
+		return
+	}
+
+	e := NewError(ErrorInternal, err.Error(), nil)
+	c.JSON(http.StatusInternalServerError, e)
+	c.Abort()
+}
@@ -0,0 +1,251 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_sse "github.com/tmaxmax/go-sse"
+)
+
+func TestEventIDs(t *testing.T) {
+	tests := []struct {
+		id   string
+		pos  eventPos
+		fail bool
+	}{
+		{id: "7", pos: afterEvent(7)},
+		{id: "7.0", pos: eventPos{seq: 7}},
+		{id: "7.2", pos: eventPos{seq: 7, idx: 2}},
+		{id: "x", fail: true},
+		{id: "7.x", fail: true},
+		{id: "-1", fail: true},
+		{id: "7.-1", fail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			pos, ok := parseEventID(tt.id)
+			if ok == tt.fail {
+				t.Fatalf("parseEventID(%q) ok = %v", tt.id, ok)
+			}
+			if tt.fail {
+				return
+			}
+			if pos != tt.pos || pos.String() != tt.id {
+				t.Fatalf("parseEventID(%q) = %v", tt.id, pos)
+			}
+		})
+	}
+
+	// the updates of an event come before the event as a whole, and before the
+	// following event
+	order := []eventPos{{seq: 7}, {seq: 7, idx: 1}, afterEvent(7), {seq: 8}}
+	for i := 1; i < len(order); i++ {
+		if !order[i-1].before(order[i]) || order[i].before(order[i-1]) {
+			t.Fatalf("%v doesn't come before %v", order[i-1], order[i])
+		}
+	}
+}
+
+func TestDuplicateEvent(t *testing.T) {
+	event := func(id, data string) go_sse.Event {
+		return go_sse.Event{LastEventID: id, Data: data}
+	}
+
+	tests := []struct {
+		name      string
+		last, e   go_sse.Event
+		duplicate bool
+	}{
+		{name: "next event", last: event("3", "a"), e: event("4", "b")},
+		{name: "next update of the event", last: event("3.0", "a"), e: event("3.1", "b")},
+		{name: "update of an event received whole", last: event("3", "a"), e: event("3.1", "b"), duplicate: true},
+		{name: "earlier event", last: event("3", "a"), e: event("2", "b"), duplicate: true},
+		{name: "same event", last: event("3.1", "a"), e: event("3.1", "a"), duplicate: true},
+		{name: "same ID with other data", last: event("3", "a"), e: event("3", "b")},
+		{name: "first event", e: event("1", "a")},
+		{name: "event without an ID", last: event("3", "a"), e: event("", "b")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := duplicateEvent(tt.last, tt.e); got != tt.duplicate {
+				t.Fatalf("duplicateEvent = %v, want %v", got, tt.duplicate)
+			}
+		})
+	}
+}
+
+func TestBacklogResumesWithinAnEvent(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Streaming"})
+	task := &Task{ID: "t1", Status: TaskStatus{State: TaskStateWorking}, Artifacts: []Artifact{{ArtifactID: "a0"}}}
+	if err := a.createTask(task, "caller"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the artifacts replaced as a whole are logged as one event
+	task.Artifacts = []Artifact{{ArtifactID: "b0"}, {ArtifactID: "b1"}, {ArtifactID: "b2"}}
+	seq, err := a.storeTask(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		after eventPos
+		want  []string
+	}{
+		{name: "before the event", after: afterEvent(seq - 1), want: []string{updateID(seq, 0), updateID(seq, 1), updateID(seq, 2)}},
+		{name: "within the event", after: eventPos{seq: seq, idx: 0}, want: []string{updateID(seq, 1), updateID(seq, 2)}},
+		{name: "after the event", after: afterEvent(seq)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backlog, upTo, err := a.backlog("t1", 1, tt.after, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if upTo != seq {
+				t.Fatalf("the backlog goes up to %d, want %d", upTo, seq)
+			}
+
+			var got []string
+			for _, res := range backlog {
+				got = append(got, res.eventID.String())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("backlog = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("backlog = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// updateID returns the event ID of the update of the event
+func updateID(seq int64, idx int) string {
+	return eventPos{seq: seq, idx: idx}.String()
+}
+
+// gatedHandler streams a working status and then waits for the gate to open before
+// it sends its artifacts
+type gatedHandler struct {
+	gate chan struct{}
+}
+
+func (h gatedHandler) StreamHandler(req JSONRPCRequest, out chan JSONRPCResponse) {
+	defer close(out)
+
+	params := req.Params.(TaskSendParams)
+	out <- JSONRPCResponse{Result: &TaskStatusUpdateEvent{ID: params.ID, Status: TaskStatus{State: TaskStateWorking}}}
+
+	select {
+	case <-h.gate:
+	case <-req.Context().Done():
+		return
+	}
+
+	for _, id := range []string{"a1", "a2"} {
+		out <- JSONRPCResponse{Result: &TaskArtifactUpdateEvent{ID: params.ID, Artifact: Artifact{ArtifactID: id, Parts: []Part{TextPart{Kind: PartTypeText, Text: id}}}}}
+	}
+	out <- JSONRPCResponse{Result: &TaskStatusUpdateEvent{ID: params.ID, Status: TaskStatus{State: TaskStateCompleted}, Final: true}}
+}
+
+// cutWriter drops the connection once the stream wrote its first event
+type cutWriter struct {
+	http.ResponseWriter
+}
+
+func (w cutWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	if bytes.Contains(p, []byte("data:")) {
+		http.NewResponseController(w.ResponseWriter).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	return n, err
+}
+
+func (w cutWriter) Flush() {
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func TestDroppedStreamIsResumed(t *testing.T) {
+	h := gatedHandler{gate: make(chan struct{})}
+	a := NewAgent(AgentCard{Name: "Streaming", Capabilities: &AgentCapabilities{Streaming: true}}, WithAgentStreamHandler(h), WithStreamHeartbeat(-1))
+	srv, paths := serveAgent(t, a)
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	// the first stream opened through the proxy is dropped after its first event,
+	// the client reconnects with the ID of that event
+	var cut atomic.Bool
+	var resumedAfter atomic.Value
+	front := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			proxy.ServeHTTP(w, r)
+			return
+		}
+		if id := r.Header.Get("Last-Event-ID"); id != "" {
+			resumedAfter.Store(id)
+		}
+		if cut.CompareAndSwap(false, true) {
+			proxy.ServeHTTP(cutWriter{w}, r)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	defer front.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := NewA2AClient().SendReqStream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")}, front.URL+paths.Stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nextEvent(t, events, inState(TaskStateWorking))
+	close(h.gate)
+
+	// every event comes once, in order
+	var got []streamed
+	for e := range events {
+		got = append(got, nextEvent(t, singleEvent(e), func(streamed) bool { return true }))
+	}
+
+	want := []streamed{{text: "a1"}, {text: "a2"}, {state: TaskStateCompleted}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	if id, _ := resumedAfter.Load().(string); id == "" {
+		t.Fatal("the stream wasn't resumed with a Last-Event-ID")
+	}
+}
+
+// singleEvent returns a stream holding the event alone
+func singleEvent(e go_sse.Event) chan go_sse.Event {
+	events := make(chan go_sse.Event, 1)
+	events <- e
+	return events
+}
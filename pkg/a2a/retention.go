@@ -0,0 +1,226 @@
+package a2a
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-micro.dev/v5/logger"
+	"go-micro.dev/v5/store"
+)
+
+// DefaultTaskRetention is how long tasks are kept once they reached a state, for
+// the states WithTaskRetention wasn't given for. Tasks in the other states are
+// kept until they move on.
+var DefaultTaskRetention = map[TaskState]time.Duration{
+	TaskStateCompleted:     7 * 24 * time.Hour,
+	TaskStateCanceled:      7 * 24 * time.Hour,
+	TaskStateFailed:        7 * 24 * time.Hour,
+	TaskStateRejected:      7 * 24 * time.Hour,
+	TaskStateInputRequired: 30 * 24 * time.Hour,
+	TaskStateAuthRequired:  30 * 24 * time.Hour,
+}
+
+const (
+	// DefaultSweepInterval is how often the Agent purges the expired tasks when the
+	// WithSweepInterval option is not provided
+	DefaultSweepInterval = 10 * time.Minute
+
+	// DefaultStreamRequestTTL is how long a tasks/sendSubscribe request waits for
+	// its stream to be opened when the WithStreamRequestTTL option is not provided
+	DefaultStreamRequestTTL = time.Minute
+)
+
+// ArchiveFunc is called with every task before it is purged, the task is kept
+// until the next sweep when it returns an error
+type ArchiveFunc func(ctx context.Context, t *Task) error
+
+// taskRecordPrefixes are the prefixes of the records that belong to a task besides
+// the task itself and its index entries, they are purged along with it
+func taskRecordPrefixes(taskID string) []string {
+	return []string{
+		submissionKeyPrefix + taskID + "/",
+		eventPrefix(taskID),
+	}
+}
+
+// retention is how long tasks are kept once they reached the state, 0 is forever
+func (a *Agent) retention(state TaskState) time.Duration {
+	if d, ok := a.options.TaskRetention[state]; ok {
+		return d
+	}
+	return DefaultTaskRetention[state]
+}
+
+func (a *Agent) streamRequestTTL() time.Duration {
+	if a.options.StreamRequestTTL > 0 {
+		return a.options.StreamRequestTTL
+	}
+	return DefaultStreamRequestTTL
+}
+
+// sweeper purges the expired tasks of an Agent in the background
+type sweeper struct {
+	agent *Agent
+
+	start sync.Once
+	stop  sync.Once
+	ctx   context.Context
+	halt  context.CancelFunc
+	done  chan struct{}
+}
+
+func newSweeper(a *Agent) *sweeper {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &sweeper{agent: a, ctx: ctx, halt: cancel, done: make(chan struct{})}
+}
+
+// run starts sweeping every interval, calling it more than once has no effect
+func (s *sweeper) run() {
+	s.start.Do(func() {
+		interval := s.agent.options.SweepInterval
+		if interval <= 0 {
+			interval = DefaultSweepInterval
+		}
+
+		go func() {
+			defer close(s.done)
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					s.agent.sweep(s.ctx, time.Now())
+				case <-s.ctx.Done():
+					return
+				}
+			}
+		}()
+	})
+}
+
+// close stops the sweeper and waits for the sweep in progress, if any
+func (s *sweeper) close() {
+	s.stop.Do(func() {
+		s.halt()
+
+		started := true
+		s.start.Do(func() { started = false })
+		if started {
+			<-s.done
+		}
+	})
+}
+
+// sweep purges the tasks that stayed in a state longer than its retention. Tasks
+// whose handler is still running, on any replica, are left alone.
+func (a *Agent) sweep(ctx context.Context, now time.Time) {
+	states := make(map[TaskState]bool)
+	for state := range DefaultTaskRetention {
+		states[state] = true
+	}
+	for state := range a.options.TaskRetention {
+		states[state] = true
+	}
+
+	for state := range states {
+		ttl := a.retention(state)
+		if ttl <= 0 {
+			continue
+		}
+
+		prefix := stateIndexPrefix(state)
+		keys, err := a.options.Store.List(store.ListPrefix(prefix))
+		if err != nil {
+			a.options.Logger.Log(logger.ErrorLevel, err)
+			continue
+		}
+
+		for _, key := range keys {
+			if ctx.Err() != nil {
+				return
+			}
+
+			_, taskID, ok := parseIndexKey(prefix, key)
+			if !ok {
+				continue
+			}
+			if _, ok := a.liveRun(taskID); ok {
+				continue
+			}
+			if _, ok := a.remoteOwner(taskID); ok {
+				continue
+			}
+
+			t, meta, err := a.loadTaskRecord(taskID)
+			if err == store.ErrNotFound || (err == nil && meta.state != state) {
+				// the entry outlived its task or its state
+				a.deleteRecord(key)
+				continue
+			}
+			if err != nil {
+				a.options.Logger.Log(logger.ErrorLevel, err)
+				continue
+			}
+
+			if now.Sub(meta.changed) < ttl {
+				continue
+			}
+
+			if err := a.purgeTask(ctx, t, meta); err != nil {
+				a.options.Logger.Log(logger.ErrorLevel, "task "+taskID+" not purged: "+err.Error())
+			}
+		}
+	}
+}
+
+// purgeTask archives the task and deletes it along with its index entries and the
+// records that belong to it. The task record goes last so an interrupted purge is
+// picked up again by the next sweep.
+func (a *Agent) purgeTask(ctx context.Context, t *Task, meta taskMeta) error {
+	if a.options.Archiver != nil {
+		if err := a.options.Archiver(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	for _, prefix := range taskRecordPrefixes(t.ID) {
+		keys, err := a.options.Store.List(store.ListPrefix(prefix))
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			a.deleteRecord(key)
+		}
+	}
+
+	prefixes := []string{
+		createdIndexPrefix,
+		stateIndexPrefix(meta.state),
+		contextIndexPrefix(t.ContextID),
+		skillIndexPrefix(meta.skill),
+		callerIndexPrefix(meta.caller),
+	}
+	for _, prefix := range prefixes {
+		if err := a.moveIndex(t.ID, meta.created, prefix, ""); err != nil {
+			return err
+		}
+	}
+
+	a.deleteRecord(ownerKey(t.ID))
+	a.deleteRecord(pushKey(t.ID))
+
+	err := a.options.Store.Delete(taskKey(t.ID))
+	if err == store.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (a *Agent) deleteRecord(key string) {
+	if err := a.options.Store.Delete(key); err != nil && err != store.ErrNotFound {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	}
+}
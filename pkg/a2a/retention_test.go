@@ -0,0 +1,193 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-micro.dev/v5/store"
+)
+
+// taskInState creates a task and moves it to the state
+func taskInState(t *testing.T, a *Agent, id string, state TaskState) {
+	t.Helper()
+
+	if _, _, err := a.prepareTask(TaskSendParams{ID: id, Message: textMessage("m-"+id, "hi")}, "", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.setTaskState(id, state); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// storedKeys returns the keys of the Agent store that name the task
+func storedKeys(t *testing.T, a *Agent, taskID string) []string {
+	t.Helper()
+
+	keys, err := a.options.Store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found []string
+	for _, k := range keys {
+		if strings.HasSuffix(k, "/"+taskID) || strings.Contains(k, "/"+taskID+"/") {
+			found = append(found, k)
+		}
+	}
+	return found
+}
+
+func TestSweepPurgesEverythingOfTheTask(t *testing.T) {
+	card := AgentCard{Name: "Forgetful", Capabilities: &AgentCapabilities{PushNotifications: true}}
+	a := NewAgent(card, WithAgentHandler(completeHandler{}), WithTaskRetention(TaskStateCompleted, time.Hour))
+	srv, paths := serveAgent(t, a)
+
+	for _, id := range []string{"t1", "t10"} {
+		params := TaskSendParams{ID: id, Message: textMessage("m1", "hi"), PushNotification: &PushNotificationConfig{URL: "http://127.0.0.1:1/hook"}}
+		if status, reply := postAs(t, srv.URL+paths.RPC, "", TasksSend, params); status != http.StatusOK {
+			t.Fatalf("tasks/send: %d %v", status, reply)
+		}
+	}
+	// the runs are done writing the records of the tasks
+	for _, id := range []string{"t1", "t10"} {
+		deadline := time.Now().Add(5 * time.Second)
+		for _, ok := a.liveRun(id); ok; _, ok = a.liveRun(id) {
+			if time.Now().After(deadline) {
+				t.Fatalf("the run of %s never ended", id)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	kept := storedKeys(t, a, "t10")
+	if len(storedKeys(t, a, "t1")) == 0 || len(kept) == 0 {
+		t.Fatal("the tasks have no records to purge")
+	}
+
+	// purging t1 leaves the records of t10 alone
+	task, meta, err := a.loadTaskRecord("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.purgeTask(context.Background(), task, meta); err != nil {
+		t.Fatal(err)
+	}
+	if keys := storedKeys(t, a, "t1"); len(keys) > 0 {
+		t.Fatalf("task t1 left %v behind", keys)
+	}
+	if keys := storedKeys(t, a, "t10"); len(keys) != len(kept) {
+		t.Fatalf("task t10 has %v left, want %v", keys, kept)
+	}
+
+	// the sweep purges t10 once it is past its retention
+	a.sweep(context.Background(), time.Now().Add(2*time.Hour))
+
+	if _, err := a.loadTask("t10"); err != store.ErrNotFound {
+		t.Fatalf("err = %v, want store.ErrNotFound", err)
+	}
+	if keys := storedKeys(t, a, "t10"); len(keys) > 0 {
+		t.Fatalf("task t10 left %v behind", keys)
+	}
+}
+
+func TestRetentionPerState(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Forgetful"}, WithAgentHandler(completeHandler{}),
+		WithTaskRetention(TaskStateCompleted, time.Hour),
+		WithTaskRetention(TaskStateFailed, 0),
+	)
+	serveAgent(t, a)
+
+	tests := []struct {
+		id    string
+		state TaskState
+		kept  bool
+	}{
+		// past the retention set for the state
+		{id: "completed", state: TaskStateCompleted},
+		// past the default retention of the state
+		{id: "canceled", state: TaskStateCanceled},
+		// kept forever when the retention is 0
+		{id: "failed", state: TaskStateFailed, kept: true},
+		// within the default retention of the state
+		{id: "input", state: TaskStateInputRequired, kept: true},
+		// states without a retention are kept until they move on
+		{id: "working", state: TaskStateWorking, kept: true},
+	}
+
+	for _, tt := range tests {
+		taskInState(t, a, tt.id, tt.state)
+	}
+
+	a.sweep(context.Background(), time.Now().Add(10*24*time.Hour))
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			_, err := a.loadTask(tt.id)
+			switch {
+			case tt.kept && err != nil:
+				t.Fatalf("the %s task was purged: %v", tt.state, err)
+			case !tt.kept && err != store.ErrNotFound:
+				t.Fatalf("the %s task was kept: %v", tt.state, err)
+			}
+		})
+	}
+}
+
+func TestArchiver(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		archived []string
+		failing  = true
+	)
+	archive := func(ctx context.Context, task *Task) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if failing {
+			return errors.New("archive unavailable")
+		}
+		archived = append(archived, task.ID)
+		return nil
+	}
+
+	a := NewAgent(AgentCard{Name: "Archiving"}, WithAgentHandler(completeHandler{}), WithArchiver(archive))
+	serveAgent(t, a)
+
+	taskInState(t, a, "t1", TaskStateCompleted)
+	later := time.Now().Add(30 * 24 * time.Hour)
+
+	// a task the archiver failed to take is kept until the next sweep
+	a.sweep(context.Background(), later)
+	if _, err := a.loadTask("t1"); err != nil {
+		t.Fatalf("the task wasn't kept: %v", err)
+	}
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+
+	a.sweep(context.Background(), later)
+	if _, err := a.loadTask("t1"); err != store.ErrNotFound {
+		t.Fatalf("err = %v, want store.ErrNotFound", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(archived) != 1 || archived[0] != "t1" {
+		t.Fatalf("archived %v, want t1", archived)
+	}
+}
+
+func TestTaskIDsCantContainASlash(t *testing.T) {
+	srv, paths := serveAgent(t, NewAgent(AgentCard{Name: "Strict"}, WithAgentHandler(completeHandler{})))
+
+	status, reply := postAs(t, srv.URL+paths.RPC, "", TasksSend, TaskSendParams{ID: "t1/x", Message: textMessage("m1", "hi")})
+	if status != http.StatusBadRequest || reply["code"] != float64(ErrorInvalidParams) {
+		t.Fatalf("reply = %d %v, want 400 with ErrorInvalidParams", status, reply)
+	}
+}
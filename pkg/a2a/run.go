@@ -0,0 +1,434 @@
+package a2a
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-micro.dev/v5/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// taskRun tracks a handler invocation from the moment it is queued until the
+// handler returns. It is how follow-up requests reach a handler that is still
+// running, for instance one paused waiting for input.
+type taskRun struct {
+	agent   *Agent
+	taskID  string
+	reqID   any
+	skillID string
+	queued  time.Time
+
+	// resume delivers the follow-up message to a paused handler
+	resume chan Message
+	// paused is signalled every time the handler pauses
+	paused chan struct{}
+	// done is closed once the handler returned and its result was recorded
+	done chan struct{}
+	// res is the TaskHandler result, it is only set for unary runs
+	res *JSONRPCResponse
+	// submissions are the keys of the tasks/send requests the run answers
+	submissions []string
+
+	// ctx is the parent of the handler context, it is cancelled once the run
+	// ends or is interrupted by a shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// requested is the deadline the client asked for and limit the end of the
+	// maximum execution time, remaining is the execution time left while the
+	// handler is paused. deadline is the earliest of them, the run fails with
+	// failure when it overruns it.
+	requested time.Time
+	limit     time.Time
+	remaining time.Duration
+	deadline  time.Time
+	timer     *time.Timer
+	failure   *JSONRPCError
+
+	// span covers the run from the moment it is queued, the handler context carries
+	// it so the calls the handler makes join the trace
+	span    trace.Span
+	traceMu sync.Mutex
+	state   TaskState
+
+	mu      sync.Mutex
+	waiting bool
+	// started is set once a worker picked the run up
+	started bool
+	// closed is set once the run was interrupted, its events are dropped from then on
+	closed bool
+	// subscribers are the streams attached to a StreamHandler run, see attach
+	subscribers []*subscriber
+	// out is the channel the StreamHandler writes to, see relay
+	out chan JSONRPCResponse
+}
+
+type runContextKey struct{}
+
+func runFromContext(ctx context.Context) (*taskRun, bool) {
+	run, ok := ctx.Value(runContextKey{}).(*taskRun)
+	return run, ok
+}
+
+// startRun registers a new run for the task, replacing any previous one. The span
+// of the run is a child of the request span found in parent.
+func (a *Agent) startRun(parent context.Context, taskID string, reqID any, skillID string) *taskRun {
+	run := a.newRun(parent, taskID, reqID, skillID)
+
+	a.runsMu.Lock()
+	a.runs[taskID] = run
+	a.runsMu.Unlock()
+
+	a.own(taskID)
+
+	return run
+}
+
+// joinRun returns the run of the task whose handler hasn't returned yet, or starts
+// a new one like startRun when there is none, it reports whether the run is new.
+// Streams opened at the same time for a task invoke its handler only once.
+func (a *Agent) joinRun(parent context.Context, taskID string, reqID any, skillID string) (*taskRun, bool) {
+	a.runsMu.Lock()
+	if run, ok := a.runs[taskID]; ok {
+		a.runsMu.Unlock()
+		return run, false
+	}
+	run := a.newRun(parent, taskID, reqID, skillID)
+	a.runs[taskID] = run
+	a.runsMu.Unlock()
+
+	a.own(taskID)
+
+	return run, true
+}
+
+func (a *Agent) newRun(parent context.Context, taskID string, reqID any, skillID string) *taskRun {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, span := a.tracer().Start(parent, "a2a.task",
+		trace.WithAttributes(AttributeTaskID.String(taskID)),
+		trace.WithAttributes(skillAttributes(skillID)...),
+	)
+
+	run := &taskRun{
+		agent:   a,
+		taskID:  taskID,
+		reqID:   reqID,
+		skillID: skillID,
+		queued:  time.Now(),
+		resume:  make(chan Message),
+		paused:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		span:    span,
+	}
+
+	return run
+}
+
+// endRun unregisters the run and wakes up everybody waiting for it
+func (a *Agent) endRun(run *taskRun) {
+	a.runsMu.Lock()
+	current := a.runs[run.taskID] == run
+	if current {
+		delete(a.runs, run.taskID)
+	}
+	a.runsMu.Unlock()
+
+	if current {
+		a.disown(run.taskID)
+	}
+
+	run.mu.Lock()
+	for _, sub := range run.detachAll() {
+		sub.close()
+	}
+	run.mu.Unlock()
+
+	run.mu.Lock()
+	if run.timer != nil {
+		run.timer.Stop()
+	}
+	run.mu.Unlock()
+
+	close(run.done)
+	run.cancel()
+	run.endSpan()
+}
+
+// unaryJob returns the job invoking the TaskHandler of the run, the task is set
+// working first and the handler result is recorded on it
+func (a *Agent) unaryJob(run *taskRun, handler AgentHandler, r JSONRPCRequest) func() {
+	return func() {
+		defer a.endRun(run)
+		defer a.completeSubmissions(run)
+
+		// a panic fails the task and the client receives ErrorInternal
+		defer func() {
+			if v := recover(); v != nil {
+				a.handlerPanicked(run, v)
+			}
+		}()
+
+		// the run was interrupted by a shutdown while it was queued
+		if !run.begin() {
+			return
+		}
+
+		r = run.bind(r)
+
+		task, err := a.setTaskState(run.taskID, TaskStateWorking)
+		if err != nil {
+			a.options.Logger.Log(logger.ErrorLevel, err)
+		} else {
+			r = a.withTask(r, task)
+		}
+
+		res := handler.TaskHandler(r)
+
+		// an interrupted task keeps the status the shutdown or the timeout left it in
+		if run.finish() {
+			run.mu.Lock()
+			run.res = &res
+			run.mu.Unlock()
+			a.recordResult(run.taskID, res)
+		}
+	}
+}
+
+// streamJob returns the job invoking the StreamHandler of the run, the task is set
+// working first and every event the handler writes is recorded on the task on its
+// way to the streams attached to the run
+func (a *Agent) streamJob(run *taskRun, handler AgentStreamHandler, r JSONRPCRequest) func() {
+	return func() {
+		// a panic fails the task and ends the streams with ErrorInternal, the relay
+		// ends the run once out is closed
+		var out chan JSONRPCResponse
+		defer func() {
+			if v := recover(); v != nil {
+				a.handlerPanicked(run, v)
+				if out != nil {
+					closeQuietly(out)
+				} else {
+					a.endRun(run)
+				}
+			}
+		}()
+
+		// the run was interrupted by a shutdown while it was queued
+		if !run.begin() {
+			a.endRun(run)
+			return
+		}
+
+		r = run.bind(r)
+
+		task, err := a.setTaskState(run.taskID, TaskStateWorking)
+		if err != nil {
+			a.options.Logger.Log(logger.ErrorLevel, err)
+		} else {
+			r = a.withTask(r, task)
+		}
+
+		out = make(chan JSONRPCResponse, 1)
+		run.relay(out)
+
+		handler.StreamHandler(r, out)
+	}
+}
+
+// liveRun returns the run of a task whose handler hasn't returned yet
+func (a *Agent) liveRun(taskID string) (*taskRun, bool) {
+	a.runsMu.Lock()
+	defer a.runsMu.Unlock()
+
+	run, ok := a.runs[taskID]
+	return run, ok
+}
+
+// claimPaused returns the run of the task when its handler is paused waiting for
+// a follow-up message, only one caller can claim a given pause
+func (a *Agent) claimPaused(taskID string) (*taskRun, bool) {
+	run, ok := a.liveRun(taskID)
+	if !ok {
+		return nil, false
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	if !run.waiting {
+		return nil, false
+	}
+	run.waiting = false
+
+	// forget a pause nobody waited for, the next one is what the caller wants
+	select {
+	case <-run.paused:
+	default:
+	}
+
+	return run, true
+}
+
+// bind returns the request carrying the run in its context, the context is done
+// once the run ends, is interrupted or reaches its deadline
+func (run *taskRun) bind(r JSONRPCRequest) JSONRPCRequest {
+	ctx := deadlineContext{Context: trace.ContextWithSpan(run.ctx, run.span), run: run}
+	return r.WithContext(context.WithValue(ctx, runContextKey{}, run))
+}
+
+// begin is called by the worker before invoking the handler, it returns false when
+// the run was interrupted while it was queued or its deadline passed meanwhile.
+// The run times out at its deadline from then on.
+func (run *taskRun) begin() bool {
+	run.mu.Lock()
+	if run.closed {
+		run.mu.Unlock()
+		return false
+	}
+	run.started = true
+	run.mu.Unlock()
+
+	run.span.AddEvent("a2a.task.started")
+
+	run.startClock()
+	if run.overran() {
+		run.agent.expire(run)
+		return false
+	}
+
+	return true
+}
+
+// failRun fails the task of a run that hasn't finished, the client receives e and
+// the task is left failed with text as its status message
+func (a *Agent) failRun(run *taskRun, e JSONRPCError, text string) {
+	// a run that finished keeps its result
+	run.mu.Lock()
+	if run.closed {
+		run.mu.Unlock()
+		return
+	}
+	run.failure = &e
+	run.mu.Unlock()
+
+	a.interrupt(run, agentStatus(TaskStateFailed, text), &e)
+}
+
+// finish is called once a TaskHandler returned, it returns false when the run was
+// interrupted before. A run that overran its deadline fails with ErrorTimeout even
+// when the handler returned before the timer fired.
+func (run *taskRun) finish() bool {
+	if run.overran() {
+		run.agent.expire(run)
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	if run.closed || run.failure != nil {
+		return false
+	}
+	run.closed = true
+
+	if run.timer != nil {
+		run.timer.Stop()
+	}
+
+	return true
+}
+
+// deliver hands the follow-up message over to the paused handler
+func (run *taskRun) deliver(msg Message) bool {
+	select {
+	case run.resume <- msg:
+		return true
+	case <-run.done:
+		return false
+	}
+}
+
+// wait blocks until the handler either pauses again or returns
+func (run *taskRun) wait(ctx context.Context) {
+	select {
+	case <-run.paused:
+	case <-run.done:
+	case <-run.ctx.Done():
+	case <-ctx.Done():
+	}
+}
+
+// relay publishes everything a StreamHandler writes in the background until it
+// closes the channel, the run ends then
+func (run *taskRun) relay(out chan JSONRPCResponse) {
+	run.mu.Lock()
+	run.out = out
+	run.mu.Unlock()
+
+	go func() {
+		for res := range out {
+			if res.flushed != nil {
+				close(res.flushed)
+				continue
+			}
+			run.publish(res)
+		}
+
+		run.agent.endRun(run)
+	}()
+}
+
+// flush waits until the events the StreamHandler wrote so far are published, the
+// events the run publishes itself then follow them
+func (run *taskRun) flush() {
+	run.mu.Lock()
+	out := run.out
+	run.mu.Unlock()
+
+	if out == nil {
+		return
+	}
+
+	flushed := make(chan struct{})
+	select {
+	case out <- JSONRPCResponse{flushed: flushed}:
+	case <-run.done:
+		return
+	}
+
+	select {
+	case <-flushed:
+	case <-run.done:
+	}
+}
+
+// response builds the reply to a unary request waiting on the run, that is the
+// error the run failed with, the handler result when it returned or the task as
+// it is stored otherwise
+func (run *taskRun) response(reqID any) (JSONRPCResponse, error) {
+	run.mu.Lock()
+	failure := run.failure
+	result := run.res
+	run.mu.Unlock()
+
+	if failure != nil {
+		return JSONRPCResponse{JSONRPC: "2.0", ID: reqID, Error: failure}, nil
+	}
+
+	if result != nil {
+		res := *result
+		res.ID = reqID
+		return res, nil
+	}
+
+	t, err := run.agent.loadTask(run.taskID)
+	if err != nil {
+		run.agent.options.Logger.Log(logger.ErrorLevel, err)
+		return JSONRPCResponse{}, err
+	}
+
+	return JSONRPCResponse{JSONRPC: "2.0", ID: reqID, Result: t}, nil
+}
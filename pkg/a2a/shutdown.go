@@ -0,0 +1,375 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go-micro.dev/v5/logger"
+	"go-micro.dev/v5/store"
+)
+
+// DefaultShutdownGracePeriod is how long an Agent waits for running handlers when
+// it stops and the WithShutdownGracePeriod option is not provided
+const DefaultShutdownGracePeriod = 30 * time.Second
+
+// ShutdownPolicy decides the state of the tasks whose handlers didn't return
+// within the shutdown grace period
+type ShutdownPolicy int
+
+const (
+	// ShutdownFail leaves unfinished tasks in the failed state
+	ShutdownFail ShutdownPolicy = iota
+	// ShutdownResumable leaves unfinished tasks submitted and flagged resumable,
+	// the Agent invokes their handlers again with the full history once it is back
+	ShutdownResumable
+)
+
+// how often Shutdown checks whether the running handlers returned
+const drainInterval = 100 * time.Millisecond
+
+// how long an interrupted stream is given to take its final event
+const finalEventTimeout = time.Second
+
+// resumableKey flags, in the task metadata, the tasks a ShutdownResumable shutdown
+// left for the Agent to resume
+const resumableKey = "resumable"
+
+// Shutdown stops the Agent from accepting tasks and waits for the running handlers
+// to return until ctx is done. Handlers paused waiting for input don't hold the
+// shutdown, their tasks stay paused. Open streams receive the final status of their
+// task and the tasks still running are left failed or resumable depending on the
+// ShutdownPolicy. SwitchOn calls it with the grace period once the service stops.
+//
+// Returns:
+//   - error: ctx.Err() when handlers were still running at the deadline
+func (a *Agent) Shutdown(ctx context.Context) error {
+	a.draining.Store(true)
+	a.sweeper.close()
+
+	// the gRPC streams end with their task, like the SSE ones
+	defer a.grpc.stop()
+
+	// the queued tasks never got a worker, they won't get one anymore
+	for _, run := range a.liveRuns() {
+		run.mu.Lock()
+		queued := !run.started
+		run.mu.Unlock()
+
+		if queued {
+			a.interrupt(run, a.interruptedStatus(), nil)
+		}
+	}
+
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	var err error
+	for err == nil && a.busy() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	for _, run := range a.liveRuns() {
+		run.mu.Lock()
+		waiting := run.waiting
+		run.mu.Unlock()
+
+		if waiting {
+			// the task keeps its input-required or auth-required status
+			a.interrupt(run, nil, nil)
+			continue
+		}
+
+		a.options.Logger.Log(logger.WarnLevel, "task "+run.taskID+" interrupted by the agent shutdown")
+		a.interrupt(run, a.interruptedStatus(), nil)
+	}
+
+	// the tasks left are abandoned, the other replicas stop waiting for them
+	a.replica.close()
+	defer a.pusher.close()
+
+	// give the streams the time to write their final event
+	deadline := time.NewTimer(finalEventTimeout)
+	defer deadline.Stop()
+
+	for a.streams.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return err
+		}
+	}
+
+	return err
+}
+
+// shutdownAll shuts the Agents down concurrently, each one with its own grace period
+func shutdownAll(agents []*Agent) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(agents))
+
+	for i, a := range agents {
+		wg.Add(1)
+		go func(i int, a *Agent) {
+			defer wg.Done()
+			errs[i] = a.shutdown()
+		}(i, a)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shutdown runs Shutdown with the configured grace period
+func (a *Agent) shutdown() error {
+	grace := a.options.ShutdownGracePeriod
+	if grace <= 0 {
+		grace = DefaultShutdownGracePeriod
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	return a.Shutdown(ctx)
+}
+
+// isDraining reports whether the Agent is shutting down, new tasks are rejected
+func (a *Agent) isDraining() bool {
+	return a.draining.Load()
+}
+
+// errShuttingDown is returned to the requests reaching a draining Agent
+func errShuttingDown() JSONRPCError {
+	return NewError(ErrorServiceUnavailable, "the agent is shutting down, try again later", nil)
+}
+
+// liveRuns returns the runs whose handlers haven't returned yet
+func (a *Agent) liveRuns() []*taskRun {
+	a.runsMu.Lock()
+	defer a.runsMu.Unlock()
+
+	runs := make([]*taskRun, 0, len(a.runs))
+	for _, run := range a.runs {
+		runs = append(runs, run)
+	}
+
+	return runs
+}
+
+// busy reports whether a handler is running and not paused
+func (a *Agent) busy() bool {
+	for _, run := range a.liveRuns() {
+		run.mu.Lock()
+		running := run.started && !run.waiting && !run.closed
+		run.mu.Unlock()
+
+		if running {
+			return true
+		}
+	}
+
+	return false
+}
+
+// interruptedStatus is the status the ShutdownPolicy leaves unfinished tasks in
+func (a *Agent) interruptedStatus() *TaskStatus {
+	if a.options.ShutdownPolicy == ShutdownResumable {
+		return agentStatus(TaskStateSubmitted, "the agent stopped before the task completed, it resumes once the agent is back")
+	}
+
+	return agentStatus(TaskStateFailed, "the agent shut down before the task completed")
+}
+
+// agentStatus is a status explaining why the agent stopped working on a task
+func agentStatus(state TaskState, text string) *TaskStatus {
+	return &TaskStatus{
+		State: state,
+		Message: &Message{
+			Kind:      MessageKind,
+			MessageId: uuid.NewString(),
+			Role:      MessageRoleAgent,
+			Parts:     []Part{TextPart{Kind: PartTypeText, Text: text}},
+			Metadata:  map[string]any{"interrupted": true},
+		},
+	}
+}
+
+// interrupt stops the run from recording and publishing events, the task is left
+// with status, or with its current status when status is nil, and the attached
+// streams receive it as their final event. When e is set the streams receive the
+// error instead.
+func (a *Agent) interrupt(run *taskRun, status *TaskStatus, e *JSONRPCError) {
+	run.mu.Lock()
+	if run.closed {
+		run.mu.Unlock()
+		return
+	}
+	run.closed = true
+	subs := run.detachAll()
+	run.mu.Unlock()
+
+	task, meta, err := a.loadTaskRecord(run.taskID)
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	} else {
+		seq := meta.seq
+		if status != nil {
+			task.Status = *status
+			if task.Status.Message != nil {
+				task.Status.Message.TaskId = task.ID
+				task.Status.Message.ContextId = task.ContextID
+			}
+			if status.State == TaskStateSubmitted {
+				setMetadata(task, resumableKey, true)
+			}
+			if seq, err = a.storeTask(task); err != nil {
+				a.options.Logger.Log(logger.ErrorLevel, err)
+			}
+		}
+
+		if len(subs) > 0 {
+			final := JSONRPCResponse{
+				JSONRPC: "2.0",
+				Result: &TaskStatusUpdateEvent{
+					ID:     task.ID,
+					Status: task.Status,
+					Final:  true,
+				},
+				eventID: afterEvent(seq),
+			}
+			if e != nil {
+				final.Result = nil
+				final.Error = e
+			}
+
+			for _, sub := range subs {
+				sub.enqueue(final)
+			}
+		}
+	}
+
+	// the streams end once they got their pending events and the final one
+	for _, sub := range subs {
+		sub.close()
+	}
+
+	// handlers watching their context stop, paused ones return from RequestInput
+	run.cancel()
+}
+
+// interruptTask leaves a task that has no run in the state of the ShutdownPolicy
+func (a *Agent) interruptTask(taskID string) {
+	task, err := a.loadTask(taskID)
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		return
+	}
+
+	task.Status = *a.interruptedStatus()
+	task.Status.Message.TaskId = task.ID
+	task.Status.Message.ContextId = task.ContextID
+	if task.Status.State == TaskStateSubmitted {
+		setMetadata(task, resumableKey, true)
+	}
+
+	if err := a.saveTask(task); err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+	}
+}
+
+// resumeInterrupted resubmits the tasks a ShutdownResumable shutdown left behind,
+// their handlers are invoked again with the full history of the task. The tasks
+// run by another replica are left to it.
+func (a *Agent) resumeInterrupted() {
+	prefix := stateIndexPrefix(TaskStateSubmitted)
+	keys, err := a.options.Store.List(store.ListPrefix(prefix))
+	if err != nil {
+		a.options.Logger.Log(logger.ErrorLevel, err)
+		return
+	}
+
+	for _, k := range keys {
+		_, taskID, ok := parseIndexKey(prefix, k)
+		if !ok {
+			continue
+		}
+
+		task, err := a.loadTask(taskID)
+		if err != nil {
+			continue
+		}
+		if resumable, _ := task.Metadata[resumableKey].(bool); !resumable || task.Status.State != TaskStateSubmitted {
+			continue
+		}
+		if _, ok := a.liveRun(taskID); ok {
+			continue
+		}
+		if _, ok := a.remoteOwner(taskID); ok {
+			continue
+		}
+
+		if err := a.resubmit(task); err != nil {
+			a.options.Logger.Log(logger.ErrorLevel, fmt.Sprintf("task %s can't be resumed: %v", taskID, err))
+		}
+	}
+}
+
+// resubmit queues the handler of a resumable task, with the last message of its
+// history, the TaskHandler of its skill is preferred over the StreamHandler
+func (a *Agent) resubmit(task *Task) error {
+	var msg Message
+	for i := len(task.History) - 1; i >= 0; i-- {
+		if task.History[i].Role == MessageRoleUser {
+			msg = task.History[i]
+			break
+		}
+	}
+
+	delete(task.Metadata, resumableKey)
+	task.Status = TaskStatus{State: TaskStateSubmitted}
+	if err := a.saveTask(task); err != nil {
+		return err
+	}
+
+	skillID := taskSkill(task)
+	r := JSONRPCRequest{
+		ID:      uuid.NewString(),
+		JSONRPC: "2.0",
+		Method:  TasksSend,
+		Params:  TaskSendParams{ID: task.ID, Message: msg},
+	}
+
+	run := a.startRun(context.Background(), task.ID, r.ID, skillID)
+
+	var job func()
+	if handler, ok := a.taskHandler(skillID); ok {
+		job = a.unaryJob(run, handler, r)
+	} else if handler, ok := a.streamHandler(skillID); ok {
+		// the events are recorded on the task, streams resubscribing get them
+		r.Method = TasksSendSubscribe
+		job = a.streamJob(run, handler, r)
+	} else {
+		a.endRun(run)
+		return fmt.Errorf("no handler serves the skill %q", skillID)
+	}
+
+	if _, err := a.executor.submit(run, job); err != nil {
+		a.endRun(run)
+		a.rejectTask(task.ID)
+		return err
+	}
+
+	return nil
+}
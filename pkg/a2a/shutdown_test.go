@@ -0,0 +1,65 @@
+package a2a
+
+import (
+	"testing"
+	"time"
+)
+
+type echoHandler struct {
+	calls chan int
+}
+
+func (h echoHandler) TaskHandler(req JSONRPCRequest) JSONRPCResponse {
+	task, _ := TaskFromContext(req.Context())
+	h.calls <- len(task.History)
+
+	return JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: Task{ID: task.ID, Status: TaskStatus{State: TaskStateCompleted}}}
+}
+
+func TestResumableTasksRunAgainOnStartUp(t *testing.T) {
+	h := echoHandler{calls: make(chan int, 1)}
+	a := NewAgent(AgentCard{Name: "Test"}, WithAgentHandler(h), WithShutdownPolicy(ShutdownResumable))
+
+	msg := Message{Kind: MessageKind, MessageId: "m1", Role: MessageRoleUser, Parts: []Part{TextPart{Kind: PartTypeText, Text: "hi"}}}
+	_, params, err := a.prepareTask(TaskSendParams{Message: msg}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.interruptTask(params.ID)
+
+	task, err := a.loadTask(params.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status.State != TaskStateSubmitted || task.Metadata[resumableKey] != true {
+		t.Fatalf("interrupted task is %s with metadata %v, want a resumable submitted task", task.Status.State, task.Metadata)
+	}
+
+	a.executor.start()
+	a.resumeInterrupted()
+
+	select {
+	case n := <-h.calls:
+		if n != 1 {
+			t.Fatalf("the handler got %d messages, want the history of the task", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the resumable task wasn't resubmitted")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		task, err = a.loadTask(params.ID)
+		if err == nil && task.Status.State == TaskStateCompleted {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task = %+v, err = %v, want it completed", task, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, ok := task.Metadata[resumableKey]; ok {
+		t.Fatal("the resumed task is still flagged resumable")
+	}
+}
@@ -0,0 +1,154 @@
+package a2a
+
+import (
+	"fmt"
+	"strings"
+
+	"go-micro.dev/v5/store"
+)
+
+// SkillMatcher picks the skill a message is meant for when the client didn't name
+// one in the message metadata, it returns false when none of the skills fits
+type SkillMatcher func(msg Message, skills []AgentSkill) (string, bool)
+
+// TagSkillMatcher is a SkillMatcher that picks the first skill having a tag that
+// shows up in the text parts of the message, ignoring case
+func TagSkillMatcher(msg Message, skills []AgentSkill) (string, bool) {
+	var text strings.Builder
+	for _, p := range msg.Parts {
+		switch v := p.(type) {
+		case TextPart:
+			text.WriteString(v.Text)
+		case *TextPart:
+			text.WriteString(v.Text)
+		}
+		text.WriteString(" ")
+	}
+
+	lower := strings.ToLower(text.String())
+	for _, s := range skills {
+		for _, tag := range s.Tags {
+			if tag != "" && strings.Contains(lower, strings.ToLower(tag)) {
+				return s.ID, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// skill returns the advertised skill with the given ID
+func (a *Agent) skill(id string) (AgentSkill, bool) {
+	for _, s := range a.options.AgentCard.Skills {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return AgentSkill{}, false
+}
+
+// routeSkill resolves the skill a TaskSendParams is meant for. An explicit hint in
+// the metadata wins, then the skill of the task being continued and last the
+// SkillMatcher. An empty ID means the request goes to the default handlers. The
+// message must fit the input modes of the skill and the client must accept at
+// least one of its output modes.
+func (a *Agent) routeSkill(params TaskSendParams) (string, error) {
+	id := skillHint(params)
+
+	if id == "" {
+		taskID := params.ID
+		if taskID == "" {
+			taskID = params.Message.TaskId
+		}
+
+		if taskID != "" {
+			t, err := a.loadTask(taskID)
+			if err != nil && err != store.ErrNotFound {
+				return "", err
+			}
+			if err == nil {
+				id = taskSkill(t)
+			}
+		}
+	}
+
+	if id == "" && a.options.SkillMatcher != nil {
+		id, _ = a.options.SkillMatcher(params.Message, a.options.AgentCard.Skills)
+	}
+
+	if id != "" {
+		if _, ok := a.skill(id); !ok {
+			return "", NewError(ErrorInvalidParams, "the agent doesn't advertise the requested skill", map[string]any{"skillId": id})
+		}
+	}
+
+	if err := a.options.AgentCard.CheckMessage(params.Message, id); err != nil {
+		return "", err
+	}
+
+	if err := a.options.AgentCard.CheckOutputModes(params.AcceptedOutputModes, id); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// taskSkill returns the skill a task was routed to
+func taskSkill(t *Task) string {
+	id, _ := t.Metadata["skillId"].(string)
+	return id
+}
+
+// taskHandler returns the handler of the skill, falling back to the AgentHandler
+func (a *Agent) taskHandler(skillID string) (AgentHandler, bool) {
+	if h, ok := a.options.SkillHandlers[skillID]; ok {
+		return h, true
+	}
+
+	if a.options.AgentHandler != nil {
+		return *a.options.AgentHandler, true
+	}
+
+	return nil, false
+}
+
+// streamHandler returns the stream handler of the skill, falling back to the
+// AgentStreamHandler
+func (a *Agent) streamHandler(skillID string) (AgentStreamHandler, bool) {
+	if h, ok := a.options.SkillStreamHandlers[skillID]; ok {
+		return h, true
+	}
+
+	if a.options.AgentStreamHandler != nil {
+		return *a.options.AgentStreamHandler, true
+	}
+
+	return nil, false
+}
+
+// verifySkills checks that every advertised skill can be served, either by its own
+// handlers or by the default ones, and that no handler is registered for a skill
+// missing from the AgentCard
+func (a *Agent) verifySkills() error {
+	for _, s := range a.options.AgentCard.Skills {
+		_, unary := a.taskHandler(s.ID)
+		_, stream := a.streamHandler(s.ID)
+		if !unary && !stream {
+			return fmt.Errorf("skill %q has no handler", s.ID)
+		}
+	}
+
+	for id := range a.options.SkillHandlers {
+		if _, ok := a.skill(id); !ok {
+			return fmt.Errorf("handler registered for skill %q which the AgentCard doesn't advertise", id)
+		}
+	}
+
+	for id := range a.options.SkillStreamHandlers {
+		if _, ok := a.skill(id); !ok {
+			return fmt.Errorf("stream handler registered for skill %q which the AgentCard doesn't advertise", id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package a2a
+
+import (
+	"net/http"
+	"testing"
+)
+
+// namedHandler completes every task, telling in its metadata which handler served it
+type namedHandler string
+
+func (h namedHandler) TaskHandler(req JSONRPCRequest) JSONRPCResponse {
+	task, _ := TaskFromContext(req.Context())
+	return JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: Task{ID: task.ID, Status: TaskStatus{State: TaskStateCompleted}, Metadata: map[string]any{"servedBy": string(h)}}}
+}
+
+// skilledAgent advertises a translate and a summarize skill, only the first one has
+// its own handler
+func skilledAgent(opts ...AgentOption) *Agent {
+	card := AgentCard{
+		Name: "Skilled",
+		Skills: []AgentSkill{
+			{ID: "translate", Name: "Translate", Tags: []string{"translate"}},
+			{ID: "summarize", Name: "Summarize", Tags: []string{"summary"}},
+		},
+	}
+	opts = append([]AgentOption{WithAgentHandler(namedHandler("default")), WithSkillHandler("translate", namedHandler("translate"))}, opts...)
+	return NewAgent(card, opts...)
+}
+
+// servedBy returns the handler that served a tasks/send reply
+func servedBy(t *testing.T, reply map[string]any) string {
+	t.Helper()
+
+	result, ok := reply["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("tasks/send failed: %v", reply)
+	}
+	md, _ := result["metadata"].(map[string]any)
+	name, _ := md["servedBy"].(string)
+	return name
+}
+
+func TestRouteSkill(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []AgentOption
+		metadata map[string]any
+		text     string
+		want     string
+	}{
+		{name: "explicit skill", metadata: map[string]any{"skillId": "translate"}, text: "hi", want: "translate"},
+		{name: "skill without its own handler", metadata: map[string]any{"skillId": "summarize"}, text: "hi", want: "default"},
+		{name: "no skill", text: "translate this", want: "default"},
+		{name: "matched skill", opts: []AgentOption{WithSkillMatcher(TagSkillMatcher)}, text: "translate this", want: "translate"},
+		{name: "explicit skill over the matcher", opts: []AgentOption{WithSkillMatcher(TagSkillMatcher)}, metadata: map[string]any{"skillId": "summarize"}, text: "translate this", want: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, paths := serveAgent(t, skilledAgent(tt.opts...))
+
+			msg := textMessage("m1", tt.text)
+			msg.Metadata = tt.metadata
+			status, reply := postAs(t, srv.URL+paths.RPC, "", TasksSend, TaskSendParams{ID: "t1", Message: msg})
+			if status != http.StatusOK {
+				t.Fatalf("tasks/send: %d %v", status, reply)
+			}
+			if got := servedBy(t, reply); got != tt.want {
+				t.Fatalf("served by %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteSkillKeepsTheSkillOfTheTask(t *testing.T) {
+	a := skilledAgent()
+	srv, paths := serveAgent(t, a)
+
+	msg := textMessage("m1", "hi")
+	msg.Metadata = map[string]any{"skillId": "translate"}
+	if status, reply := postAs(t, srv.URL+paths.RPC, "", TasksSend, TaskSendParams{ID: "t1", Message: msg}); status != http.StatusOK {
+		t.Fatalf("tasks/send: %d %v", status, reply)
+	}
+
+	// a message for the same task that doesn't name a skill goes to the task's skill
+	skillID, err := a.routeSkill(TaskSendParams{ID: "t1", Message: textMessage("m2", "again")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skillID != "translate" {
+		t.Fatalf("routed to %q, want translate", skillID)
+	}
+}
+
+func TestRouteSkillRejectsUnknownSkills(t *testing.T) {
+	srv, paths := serveAgent(t, skilledAgent())
+
+	msg := textMessage("m1", "hi")
+	msg.Metadata = map[string]any{"skillId": "paint"}
+	status, reply := postAs(t, srv.URL+paths.RPC, "", TasksSend, TaskSendParams{ID: "t1", Message: msg})
+	if status != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", status)
+	}
+	if code := reply["code"]; code != float64(ErrorInvalidParams) {
+		t.Fatalf("code = %v, want ErrorInvalidParams: %v", code, reply)
+	}
+}
+
+func TestVerifySkills(t *testing.T) {
+	card := AgentCard{Name: "Skilled", Skills: []AgentSkill{{ID: "translate"}}}
+
+	tests := []struct {
+		name string
+		opts []AgentOption
+		ok   bool
+	}{
+		{name: "default handler", opts: []AgentOption{WithAgentHandler(completeHandler{})}, ok: true},
+		{name: "skill handler", opts: []AgentOption{WithSkillHandler("translate", completeHandler{})}, ok: true},
+		{name: "skill stream handler", opts: []AgentOption{WithSkillStreamHandler("translate", streamHandler{})}, ok: true},
+		{name: "no handler", opts: nil},
+		{name: "handler of a skill not advertised", opts: []AgentOption{WithAgentHandler(completeHandler{}), WithSkillHandler("paint", completeHandler{})}},
+		{name: "stream handler of a skill not advertised", opts: []AgentOption{WithAgentHandler(completeHandler{}), WithSkillStreamHandler("paint", streamHandler{})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewAgent(card, tt.opts...).verifySkills()
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
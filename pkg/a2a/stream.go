@@ -0,0 +1,189 @@
+package a2a
+
+import (
+	"sync"
+	"time"
+)
+
+// SlowConsumerPolicy decides what happens to the events of a stream whose client
+// reads them slower than they are produced, once the buffer of the stream is full.
+// The handler and the other streams of the task are never held by a slow client,
+// and the final event is always sent, over the limit if need be.
+type SlowConsumerPolicy int
+
+const (
+	// SlowConsumerDisconnect ends the stream with ErrorServiceUnavailable, the task
+	// goes on and the client can resubscribe to it
+	SlowConsumerDisconnect SlowConsumerPolicy = iota
+	// SlowConsumerDrop drops the intermediate events
+	SlowConsumerDrop
+	// SlowConsumerCoalesce replaces the pending status updates with the latest one,
+	// an artifact update that doesn't fit disconnects the stream
+	SlowConsumerCoalesce
+)
+
+const (
+	// DefaultStreamBuffer is how many events a stream holds for its client when the
+	// WithStreamBuffer option is not provided
+	DefaultStreamBuffer = 16
+
+	// DefaultStreamHeartbeat is how long a stream stays silent before it sends a
+	// comment to keep proxies from closing it, when the WithStreamHeartbeat option
+	// is not provided
+	DefaultStreamHeartbeat = 15 * time.Second
+)
+
+func (a *Agent) streamBuffer() int {
+	if a.options.StreamBuffer > 0 {
+		return a.options.StreamBuffer
+	}
+	return DefaultStreamBuffer
+}
+
+// streamHeartbeat is the heartbeat interval of the streams, 0 disables them
+func (a *Agent) streamHeartbeat() time.Duration {
+	switch {
+	case a.options.StreamHeartbeat < 0:
+		return 0
+	case a.options.StreamHeartbeat > 0:
+		return a.options.StreamHeartbeat
+	}
+	return DefaultStreamHeartbeat
+}
+
+// streamQueue holds the events of a stream between the producer and the client,
+// pushing never waits for the client, the policy applies once the queue is full
+type streamQueue struct {
+	limit  int
+	policy SlowConsumerPolicy
+
+	mu     sync.Mutex
+	events []JSONRPCResponse
+	// done is set once no more events are pushed
+	done    bool
+	dropped int
+
+	// ready is signalled when events are pushed or the queue is done
+	ready chan struct{}
+}
+
+func newStreamQueue(limit int, policy SlowConsumerPolicy) *streamQueue {
+	return &streamQueue{
+		limit:  limit,
+		policy: policy,
+		ready:  make(chan struct{}, 1),
+	}
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// push queues the event applying the policy when the queue is full, it returns
+// false once the stream ended
+func (q *streamQueue) push(res JSONRPCResponse) bool {
+	q.mu.Lock()
+
+	if q.done {
+		q.mu.Unlock()
+		return false
+	}
+
+	full := len(q.events) >= q.limit
+	switch {
+	case !full, isFinalEvent(res):
+		// the final event goes over the limit
+
+	case q.policy == SlowConsumerDrop:
+		q.dropped++
+		q.mu.Unlock()
+		return true
+
+	case q.policy == SlowConsumerCoalesce && isStatusUpdate(res) && q.coalesce():
+		// the pending status updates made room for the latest one
+
+	default:
+		e := NewError(ErrorServiceUnavailable, "the client didn't keep up with the stream", nil)
+		q.dropped += len(q.events) + 1
+		q.events = []JSONRPCResponse{{JSONRPC: "2.0", ID: res.ID, Error: &e}}
+		q.done = true
+		q.mu.Unlock()
+		signal(q.ready)
+		return false
+	}
+
+	q.events = append(q.events, res)
+	q.mu.Unlock()
+	signal(q.ready)
+	return true
+}
+
+// coalesce drops the pending status updates that aren't final, it reports whether
+// that made room
+func (q *streamQueue) coalesce() bool {
+	kept := q.events[:0]
+	for _, res := range q.events {
+		if isStatusUpdate(res) && !isFinalEvent(res) {
+			q.dropped++
+			continue
+		}
+		kept = append(kept, res)
+	}
+	q.events = kept
+
+	return len(q.events) < q.limit
+}
+
+// take returns the pending events and whether the stream ended
+func (q *streamQueue) take() ([]JSONRPCResponse, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events, done := q.events, q.done
+	q.events = nil
+	return events, done
+}
+
+// close ends the stream once the pending events are taken
+func (q *streamQueue) close() {
+	q.mu.Lock()
+	q.done = true
+	q.mu.Unlock()
+
+	signal(q.ready)
+}
+
+// droppedEvents returns how many events the policy dropped
+func (q *streamQueue) droppedEvents() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.dropped
+}
+
+// isFinalEvent reports whether the event ends its stream, that is an error or the
+// last status update of the task
+func isFinalEvent(res JSONRPCResponse) bool {
+	if res.Error != nil {
+		return true
+	}
+
+	switch e := res.Result.(type) {
+	case *TaskStatusUpdateEvent:
+		return e.Final || isTerminal(e.Status.State)
+	case TaskStatusUpdateEvent:
+		return e.Final || isTerminal(e.Status.State)
+	}
+	return false
+}
+
+func isStatusUpdate(res JSONRPCResponse) bool {
+	switch res.Result.(type) {
+	case *TaskStatusUpdateEvent, TaskStatusUpdateEvent:
+		return true
+	}
+	return false
+}
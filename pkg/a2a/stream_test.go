@@ -0,0 +1,201 @@
+package a2a
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamQueuePolicies(t *testing.T) {
+	status := func(state TaskState) JSONRPCResponse {
+		return JSONRPCResponse{Result: &TaskStatusUpdateEvent{Status: TaskStatus{State: state}}}
+	}
+	artifact := func(id string) JSONRPCResponse {
+		return JSONRPCResponse{Result: &TaskArtifactUpdateEvent{Artifact: Artifact{ArtifactID: id}}}
+	}
+	describe := func(res JSONRPCResponse) string {
+		switch e := res.Result.(type) {
+		case *TaskStatusUpdateEvent:
+			return string(e.Status.State)
+		case *TaskArtifactUpdateEvent:
+			return e.Artifact.ArtifactID
+		}
+		if res.Error != nil {
+			return fmt.Sprint(res.Error.Code)
+		}
+		return "?"
+	}
+
+	disconnected := fmt.Sprint(ErrorServiceUnavailable)
+
+	tests := []struct {
+		name    string
+		policy  SlowConsumerPolicy
+		pushed  []JSONRPCResponse
+		want    []string
+		ended   bool
+		dropped int
+	}{
+		{
+			name:   "within the buffer",
+			policy: SlowConsumerDisconnect,
+			pushed: []JSONRPCResponse{status(TaskStateWorking), artifact("a1")},
+			want:   []string{"working", "a1"},
+		},
+		{
+			name:    "disconnect",
+			policy:  SlowConsumerDisconnect,
+			pushed:  []JSONRPCResponse{status(TaskStateWorking), artifact("a1"), artifact("a2")},
+			want:    []string{disconnected},
+			ended:   true,
+			dropped: 3,
+		},
+		{
+			name:    "drop",
+			policy:  SlowConsumerDrop,
+			pushed:  []JSONRPCResponse{artifact("a1"), artifact("a2"), artifact("a3"), status(TaskStateWorking)},
+			want:    []string{"a1", "a2"},
+			dropped: 2,
+		},
+		{
+			name:    "coalesce the status updates",
+			policy:  SlowConsumerCoalesce,
+			pushed:  []JSONRPCResponse{status(TaskStateWorking), artifact("a1"), status(TaskStateInputRequired)},
+			want:    []string{"a1", "input-required"},
+			dropped: 1,
+		},
+		{
+			name:    "coalesce with only artifacts pending",
+			policy:  SlowConsumerCoalesce,
+			pushed:  []JSONRPCResponse{artifact("a1"), artifact("a2"), status(TaskStateWorking)},
+			want:    []string{disconnected},
+			ended:   true,
+			dropped: 3,
+		},
+		{
+			name:    "coalesce with an artifact that doesn't fit",
+			policy:  SlowConsumerCoalesce,
+			pushed:  []JSONRPCResponse{status(TaskStateWorking), status(TaskStateWorking), artifact("a1")},
+			want:    []string{disconnected},
+			ended:   true,
+			dropped: 3,
+		},
+	}
+
+	for _, policy := range []SlowConsumerPolicy{SlowConsumerDisconnect, SlowConsumerDrop, SlowConsumerCoalesce} {
+		tests = append(tests, struct {
+			name    string
+			policy  SlowConsumerPolicy
+			pushed  []JSONRPCResponse
+			want    []string
+			ended   bool
+			dropped int
+		}{
+			name:   fmt.Sprintf("final event over the limit with policy %d", policy),
+			policy: policy,
+			pushed: []JSONRPCResponse{artifact("a1"), artifact("a2"), status(TaskStateCompleted)},
+			want:   []string{"a1", "a2", "completed"},
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newStreamQueue(2, tt.policy)
+			for _, res := range tt.pushed {
+				q.push(res)
+			}
+
+			events, ended := q.take()
+			var got []string
+			for _, res := range events {
+				got = append(got, describe(res))
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("events = %v, want %v", got, tt.want)
+			}
+			if ended != tt.ended {
+				t.Fatalf("ended = %v, want %v", ended, tt.ended)
+			}
+			if n := q.droppedEvents(); n != tt.dropped {
+				t.Fatalf("dropped %d events, want %d", n, tt.dropped)
+			}
+		})
+	}
+}
+
+func TestStreamQueueEndsAfterDisconnect(t *testing.T) {
+	q := newStreamQueue(1, SlowConsumerDisconnect)
+	q.push(JSONRPCResponse{Result: &TaskArtifactUpdateEvent{}})
+
+	if q.push(JSONRPCResponse{Result: &TaskArtifactUpdateEvent{}}) {
+		t.Fatal("the push overflowing the queue didn't end the stream")
+	}
+	if q.push(JSONRPCResponse{Result: &TaskStatusUpdateEvent{Status: TaskStatus{State: TaskStateCompleted}}}) {
+		t.Fatal("an event was queued after the stream ended")
+	}
+}
+
+func TestStreamSettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []AgentOption
+		buffer    int
+		heartbeat time.Duration
+	}{
+		{name: "defaults", buffer: DefaultStreamBuffer, heartbeat: DefaultStreamHeartbeat},
+		{name: "set", opts: []AgentOption{WithStreamBuffer(4), WithStreamHeartbeat(time.Second)}, buffer: 4, heartbeat: time.Second},
+		{name: "heartbeats disabled", opts: []AgentOption{WithStreamHeartbeat(-1)}, buffer: DefaultStreamBuffer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAgent(AgentCard{Name: "Streaming"}, tt.opts...)
+			if n := a.streamBuffer(); n != tt.buffer {
+				t.Fatalf("stream buffer = %d, want %d", n, tt.buffer)
+			}
+			if d := a.streamHeartbeat(); d != tt.heartbeat {
+				t.Fatalf("stream heartbeat = %v, want %v", d, tt.heartbeat)
+			}
+		})
+	}
+}
+
+func TestIdleStreamsGetHeartbeats(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Streaming", Capabilities: &AgentCapabilities{Streaming: true}}, WithAgentStreamHandler(streamHandler{}), WithStreamHeartbeat(50*time.Millisecond))
+	srv, paths := serveAgent(t, a)
+	url := srv.URL + paths.Stream
+
+	// the handler pauses for input, the stream has nothing to send meanwhile
+	params := TaskSendParams{ID: "t1", Message: textMessage("m1", "hi"), Metadata: map[string]any{"ask": true}}
+	if status, reply := postAs(t, url, "", TasksSendSubscribe, params); status != http.StatusOK {
+		t.Fatalf("tasks/sendSubscribe: %d %v", status, reply)
+	}
+
+	res, err := http.Get(url + "?id=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	keepalives := make(chan struct{}, 16)
+	go func() {
+		lines := bufio.NewScanner(res.Body)
+		for lines.Scan() {
+			if strings.HasPrefix(lines.Text(), ":") {
+				keepalives <- struct{}{}
+			}
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-keepalives:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d heartbeats, want 2", i)
+		}
+	}
+}
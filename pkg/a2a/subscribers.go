@@ -0,0 +1,205 @@
+package a2a
+
+import (
+	"context"
+	"slices"
+)
+
+// subscriber is a stream attached to a run, every subscriber gets the events of the
+// run under the ID of its own request. The run queues them on the subscriber, whose
+// own goroutine drains them to its sink, so a slow client never holds the run or the
+// other subscribers: once its queue is full the SlowConsumerPolicy applies. One
+// going away doesn't affect the run or the other ones.
+type subscriber struct {
+	reqID any
+	sink  ResultChan
+	ctx   context.Context
+	queue *streamQueue
+	// stop cancels the detaching of the subscriber once ctx is done
+	stop func() bool
+}
+
+// newSubscriber returns a subscriber whose events are held until it is started
+func (run *taskRun) newSubscriber(ctx context.Context, reqID any, sink ResultChan) *subscriber {
+	a := run.agent
+	return &subscriber{
+		reqID: reqID,
+		sink:  sink,
+		ctx:   ctx,
+		queue: newStreamQueue(a.streamBuffer(), a.options.SlowConsumerPolicy),
+	}
+}
+
+// enqueue queues the event for the subscriber without blocking, it returns false
+// once the stream ended
+func (sub *subscriber) enqueue(res JSONRPCResponse) bool {
+	res.ID = sub.reqID
+	return sub.queue.push(res)
+}
+
+// close ends the stream of the subscriber once its queued events are drained
+func (sub *subscriber) close() {
+	sub.queue.close()
+}
+
+// start drains the backlog and then the queued events to the sink, the queued
+// events the backlog goes up to are skipped
+func (sub *subscriber) start(backlog []JSONRPCResponse, seq int64) {
+	go sub.drain(backlog, seq)
+}
+
+// drain forwards the events to the sink until the stream ended or its client is
+// gone, it is the only one closing the sink
+func (sub *subscriber) drain(backlog []JSONRPCResponse, seq int64) {
+	defer close(sub.sink)
+
+	send := func(res JSONRPCResponse) bool {
+		select {
+		case sub.sink <- res:
+			return !isFinalEvent(res)
+		case <-sub.ctx.Done():
+			return false
+		}
+	}
+
+	for _, res := range backlog {
+		if !send(res) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-sub.queue.ready:
+		case <-sub.ctx.Done():
+			return
+		}
+
+		events, done := sub.queue.take()
+		for _, res := range events {
+			if res.eventID.seq > 0 && res.eventID.seq <= seq {
+				continue
+			}
+			if !send(res) {
+				return
+			}
+		}
+
+		if done {
+			return
+		}
+	}
+}
+
+// attach adds the stream to the destinations of the run events, until ctx is done
+func (run *taskRun) attach(ctx context.Context, reqID any, sink ResultChan) {
+	run.mu.Lock()
+	sub := run.subscribe(ctx, reqID, sink)
+	run.mu.Unlock()
+
+	if sub == nil {
+		// an interrupted run has nothing more to send
+		close(sink)
+		return
+	}
+	sub.start(nil, 0)
+}
+
+// attachBacklog attaches a new stream that first gets the backlog of the task, see
+// Agent.backlog. The stream is attached before the backlog is read, without holding
+// the run, the events published meanwhile that the backlog covers are skipped.
+func (run *taskRun) attachBacklog(ctx context.Context, reqID any, after eventPos, replay bool) (ResultChan, error) {
+	sink := make(ResultChan)
+
+	run.mu.Lock()
+	sub := run.subscribe(ctx, reqID, sink)
+	run.mu.Unlock()
+
+	backlog, seq, err := run.agent.backlog(run.taskID, reqID, after, replay)
+	if err != nil {
+		if sub != nil {
+			sub.stop()
+			run.detach(sub)
+		}
+		return nil, err
+	}
+
+	if sub == nil {
+		// an interrupted run has nothing more to send than the backlog
+		sink = make(ResultChan, len(backlog))
+		for _, res := range backlog {
+			sink <- res
+		}
+		close(sink)
+		return sink, nil
+	}
+
+	sub.start(backlog, seq)
+	return sink, nil
+}
+
+// subscribe adds a subscriber to the run, nil once the run was interrupted. run.mu
+// must be held.
+func (run *taskRun) subscribe(ctx context.Context, reqID any, sink ResultChan) *subscriber {
+	if run.closed {
+		return nil
+	}
+
+	sub := run.newSubscriber(ctx, reqID, sink)
+	sub.stop = context.AfterFunc(ctx, func() { run.detach(sub) })
+	run.subscribers = append(run.subscribers, sub)
+
+	return sub
+}
+
+// detach forgets the subscriber once its client is gone, its goroutine closes its
+// sink
+func (run *taskRun) detach(sub *subscriber) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	i := slices.Index(run.subscribers, sub)
+	if i < 0 {
+		return
+	}
+	run.subscribers = slices.Delete(run.subscribers, i, i+1)
+}
+
+// detachAll forgets every subscriber and returns them, the caller closes them.
+// run.mu must be held.
+func (run *taskRun) detachAll() []*subscriber {
+	subs := run.subscribers
+	run.subscribers = nil
+
+	for _, sub := range subs {
+		sub.stop()
+	}
+	return subs
+}
+
+// publish records the event on the task and queues it on every subscriber, in the
+// order of the events, without waiting for any of them. Once they are gone the
+// events are dropped so the handler can finish and free its worker, the run goes on
+// until the handler returns or the task is canceled. A subscriber whose stream the
+// SlowConsumerPolicy ended is detached.
+func (run *taskRun) publish(res JSONRPCResponse) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	// the task keeps the status it was left with when the run was interrupted
+	if run.closed {
+		return
+	}
+
+	if seq := run.agent.recordEvent(run.taskID, res); seq > 0 {
+		res.eventID = afterEvent(seq)
+	}
+
+	run.subscribers = slices.DeleteFunc(run.subscribers, func(sub *subscriber) bool {
+		if sub.enqueue(res) {
+			return false
+		}
+		sub.stop()
+		return true
+	})
+}
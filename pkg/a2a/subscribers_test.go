@@ -0,0 +1,123 @@
+package a2a
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSlowSubscriberDoesNotHoldTheRun(t *testing.T) {
+	const buffer = 4
+	a := NewAgent(AgentCard{Name: "Streaming"}, WithStreamBuffer(buffer))
+	if err := a.createTask(&Task{ID: "t1", Status: TaskStatus{State: TaskStateWorking}}, "caller"); err != nil {
+		t.Fatal(err)
+	}
+
+	run := a.startRun(context.Background(), "t1", "r0", "")
+	defer a.endRun(run)
+
+	// the slow client never reads and never leaves
+	slow := make(ResultChan)
+	run.attach(context.Background(), "slow", slow)
+
+	fast := make(ResultChan, 100)
+	run.attach(context.Background(), "fast", fast)
+
+	// the slow subscriber holds at most a buffer in its queue and a buffer it took
+	const n = 2*buffer + 2
+	for i := 0; i < n; i++ {
+		published := make(chan struct{})
+		go func() {
+			defer close(published)
+			run.publish(JSONRPCResponse{JSONRPC: "2.0", Result: &TaskStatusUpdateEvent{ID: "t1", Status: TaskStatus{State: TaskStateWorking}}})
+		}()
+
+		select {
+		case <-published:
+		case <-time.After(time.Second):
+			t.Fatalf("event %d is held by the slow subscriber", i)
+		}
+
+		// the fast subscriber gets every event
+		select {
+		case res := <-fast:
+			if res.ID != "fast" {
+				t.Fatalf("event for %v, want fast", res.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("the fast subscriber didn't get event %d", i)
+		}
+	}
+
+	// the slow subscriber was disconnected and detached
+	run.mu.Lock()
+	subscribers := len(run.subscribers)
+	run.mu.Unlock()
+	if subscribers != 1 {
+		t.Fatalf("%d subscribers attached, want the fast one only", subscribers)
+	}
+
+	var last JSONRPCResponse
+	timeout := time.After(time.Second)
+	for done := false; !done; {
+		select {
+		case res, ok := <-slow:
+			if !ok {
+				done = true
+				break
+			}
+			last = res
+		case <-timeout:
+			t.Fatal("the stream of the slow subscriber never ended")
+		}
+	}
+	if last.Error == nil || last.Error.Code != ErrorServiceUnavailable || last.ID != "slow" {
+		t.Fatalf("the slow stream ended with %+v, want ErrorServiceUnavailable", last)
+	}
+}
+
+func TestAttachBacklogThenLiveEvents(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Streaming"})
+	if err := a.createTask(&Task{ID: "t1", Status: TaskStatus{State: TaskStateWorking}}, "caller"); err != nil {
+		t.Fatal(err)
+	}
+
+	run := a.startRun(context.Background(), "t1", "r0", "")
+	defer a.endRun(run)
+
+	artifact := func(id string) JSONRPCResponse {
+		return JSONRPCResponse{JSONRPC: "2.0", Result: &TaskArtifactUpdateEvent{ID: "t1", Artifact: Artifact{ArtifactID: id}}}
+	}
+	run.publish(artifact("a1"))
+
+	// the stream gets the backlog first and then the live events, each once
+	sink, err := run.attachBacklog(context.Background(), "late", eventPos{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run.publish(artifact("a2"))
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case res := <-sink:
+			if e, ok := res.Result.(TaskArtifactUpdateEvent); ok {
+				got = append(got, e.Artifact.ArtifactID)
+			}
+			if e, ok := res.Result.(*TaskArtifactUpdateEvent); ok {
+				got = append(got, e.Artifact.ArtifactID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("got %v, want a1 and a2", got)
+		}
+	}
+	if got[0] != "a1" || got[1] != "a2" {
+		t.Fatalf("got %v, want a1 then a2", got)
+	}
+
+	select {
+	case res := <-sink:
+		t.Fatalf("got %+v after a2", res)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -384,3 +384,7 @@ type TaskPushNotificationConfig struct {
 	ID                     string                 `json:"id"` // task id
 	PushNotificationConfig PushNotificationConfig `json:"pushNotificationConfig"`
 }
+
+func (t TaskPushNotificationConfig) paramGlue() {}
+
+func (t TaskPushNotificationConfig) resultGlue() {}
@@ -0,0 +1,152 @@
+package a2a
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-micro.dev/v5/store"
+)
+
+// Tasks are indexed with empty records whose keys end with the creation time of the
+// task and its ID, so listing a prefix returns the tasks of an index in the order
+// they were created:
+//
+//	index/created/<created>/<task>           every task
+//	index/state/<state>/<created>/<task>     tasks by TaskState, moved as it changes
+//	index/skill/<skill>/<created>/<task>     tasks by AgentSkill.ID
+//	index/caller/<caller>/<created>/<task>   tasks by the caller who created them
+//	context/<context>/<created>/<task>       tasks by ContextID, see Conversation
+//
+// The indexes may lag behind the tasks, readers check the task itself.
+const (
+	indexKeyPrefix     = "index/"
+	createdIndexPrefix = indexKeyPrefix + "created/"
+	contextKeyPrefix   = "context/"
+)
+
+func stateIndexPrefix(state TaskState) string {
+	return valueIndexPrefix(indexKeyPrefix+"state/", string(state))
+}
+
+func skillIndexPrefix(skillID string) string {
+	return valueIndexPrefix(indexKeyPrefix+"skill/", skillID)
+}
+
+func callerIndexPrefix(caller string) string {
+	return valueIndexPrefix(indexKeyPrefix+"caller/", caller)
+}
+
+func contextIndexPrefix(contextID string) string {
+	return valueIndexPrefix(contextKeyPrefix, contextID)
+}
+
+// valueIndexPrefix is the prefix of the index entries for the value, there is no
+// index for the empty value
+func valueIndexPrefix(index, value string) string {
+	if value == "" {
+		return ""
+	}
+	return index + url.PathEscape(value) + "/"
+}
+
+// indexKey is the key of the entry of the task in the index with the prefix, it
+// sorts by creation time
+func indexKey(prefix string, created time.Time, taskID string) string {
+	return fmt.Sprintf("%s%020d/%s", prefix, created.UnixNano(), taskID)
+}
+
+// parseIndexKey returns the creation time and the ID of the task of an index entry
+func parseIndexKey(prefix, key string) (time.Time, string, bool) {
+	created, taskID, ok := strings.Cut(strings.TrimPrefix(key, prefix), "/")
+	if !ok {
+		return time.Time{}, "", false
+	}
+
+	nanos, err := strconv.ParseInt(created, 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	return time.Unix(0, nanos), taskID, true
+}
+
+// moveIndex moves the entry of the task from one index to another, an empty prefix
+// meaning no index
+func (a *Agent) moveIndex(taskID string, created time.Time, from, to string) error {
+	if from == to {
+		return nil
+	}
+
+	if from != "" {
+		if err := a.options.Store.Delete(indexKey(from, created, taskID)); err != nil && err != store.ErrNotFound {
+			return err
+		}
+	}
+
+	if to == "" {
+		return nil
+	}
+	return a.options.Store.Write(&store.Record{Key: indexKey(to, created, taskID)})
+}
+
+// taskMeta is kept in the metadata of the task records, it is what the task is
+// indexed by
+type taskMeta struct {
+	state   TaskState
+	created time.Time
+	// changed is when the task reached its state
+	changed time.Time
+	skill   string
+	caller  string
+	// seq is the sequence number of the last event of the task
+	seq int64
+}
+
+// times are kept as strings, stores encoding the metadata as JSON would round them
+// as floats
+func (m taskMeta) record() map[string]interface{} {
+	return map[string]interface{}{
+		"state":   string(m.state),
+		"created": strconv.FormatInt(m.created.UnixNano(), 10),
+		"changed": strconv.FormatInt(m.changed.UnixNano(), 10),
+		"skill":   m.skill,
+		"caller":  m.caller,
+		"seq":     strconv.FormatInt(m.seq, 10),
+	}
+}
+
+func parseTaskMeta(md map[string]interface{}) taskMeta {
+	var m taskMeta
+
+	if v, ok := md["state"].(string); ok {
+		m.state = TaskState(v)
+	}
+	m.created = parseNanos(md["created"])
+	m.changed = parseNanos(md["changed"])
+	if m.changed.IsZero() {
+		m.changed = m.created
+	}
+	m.skill, _ = md["skill"].(string)
+	m.caller, _ = md["caller"].(string)
+	if v, ok := md["seq"].(string); ok {
+		m.seq, _ = strconv.ParseInt(v, 10, 64)
+	}
+
+	return m
+}
+
+func parseNanos(v interface{}) time.Time {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}
+	}
+
+	nanos, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
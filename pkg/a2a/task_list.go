@@ -0,0 +1,211 @@
+package a2a
+
+import (
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-micro.dev/v5/store"
+)
+
+// DefaultTaskListPageSize is the number of tasks per page of tasks/list when the
+// request doesn't set one, pages never hold more than MaxTaskListPageSize
+const (
+	DefaultTaskListPageSize = 50
+	MaxTaskListPageSize     = 500
+)
+
+// TaskListParams filters the tasks returned by tasks/list, every filter that is set
+// must match. Tasks are listed newest first.
+type TaskListParams struct {
+	State     TaskState `json:"state,omitempty"`
+	ContextID string    `json:"contextId,omitempty"`
+	SkillID   string    `json:"skillId,omitempty"`
+	// Caller is the principal that created the tasks as identified by the Agent,
+	// see WithCaller. Callers only list their own tasks unless the Agent was
+	// created WithListAllCallers.
+	Caller string `json:"caller,omitempty"`
+	// tasks created at or after CreatedAfter and before CreatedBefore, RFC 3339
+	CreatedAfter  string `json:"createdAfter,omitempty"`
+	CreatedBefore string `json:"createdBefore,omitempty"`
+
+	// Cursor is the NextCursor of the previous page, empty for the first one
+	Cursor   string `json:"cursor,omitempty"`
+	PageSize int    `json:"pageSize,omitempty"`
+	// number of recent messages of each task to return, 0 returns none
+	HistoryLength int            `json:"historyLength,omitempty"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+}
+
+func (t TaskListParams) paramGlue() {}
+
+// TaskList is a page of tasks/list, NextCursor is empty on the last page
+type TaskList struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+func (t TaskList) resultGlue() {}
+
+// taskFilter is a validated TaskListParams
+type taskFilter struct {
+	TaskListParams
+	after, before time.Time
+	cursor        string
+	pageSize      int
+}
+
+func newTaskFilter(p TaskListParams) (taskFilter, error) {
+	f := taskFilter{TaskListParams: p, pageSize: p.PageSize}
+
+	var err error
+	if p.CreatedAfter != "" {
+		if f.after, err = time.Parse(time.RFC3339Nano, p.CreatedAfter); err != nil {
+			return f, NewError(ErrorInvalidParams, "createdAfter must be an RFC 3339 time", map[string]any{"createdAfter": p.CreatedAfter})
+		}
+	}
+	if p.CreatedBefore != "" {
+		if f.before, err = time.Parse(time.RFC3339Nano, p.CreatedBefore); err != nil {
+			return f, NewError(ErrorInvalidParams, "createdBefore must be an RFC 3339 time", map[string]any{"createdBefore": p.CreatedBefore})
+		}
+	}
+
+	if p.Cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+		if err != nil {
+			return f, NewError(ErrorInvalidParams, "invalid cursor", map[string]any{"cursor": p.Cursor})
+		}
+		f.cursor = string(raw)
+	}
+
+	switch {
+	case f.pageSize <= 0:
+		f.pageSize = DefaultTaskListPageSize
+	case f.pageSize > MaxTaskListPageSize:
+		f.pageSize = MaxTaskListPageSize
+	}
+
+	return f, nil
+}
+
+// index picks the most selective index for the filter
+func (f taskFilter) index() string {
+	switch {
+	case f.ContextID != "":
+		return contextIndexPrefix(f.ContextID)
+	case f.State != "":
+		return stateIndexPrefix(f.State)
+	case f.SkillID != "":
+		return skillIndexPrefix(f.SkillID)
+	case f.Caller != "":
+		return callerIndexPrefix(f.Caller)
+	}
+	return createdIndexPrefix
+}
+
+// matches checks the filters against the task itself, the index it was found in
+// may be stale
+func (f taskFilter) matches(t *Task, meta taskMeta) bool {
+	return (f.State == "" || t.Status.State == f.State) &&
+		(f.ContextID == "" || t.ContextID == f.ContextID) &&
+		(f.SkillID == "" || meta.skill == f.SkillID) &&
+		(f.Caller == "" || meta.caller == f.Caller)
+}
+
+// listTasks returns a page of the tasks matching the filter, newest first. The
+// cursor is the position of the last task returned within the index.
+func (a *Agent) listTasks(f taskFilter) (TaskList, error) {
+	prefix := f.index()
+
+	keys, err := a.options.Store.List(store.ListPrefix(prefix))
+	if err != nil {
+		return TaskList{}, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+
+	list := TaskList{Tasks: []Task{}}
+	last := ""
+	for _, key := range keys {
+		position := key[len(prefix):]
+		if f.cursor != "" && position >= f.cursor {
+			continue
+		}
+
+		created, taskID, ok := parseIndexKey(prefix, key)
+		if !ok {
+			continue
+		}
+		if !f.before.IsZero() && !created.Before(f.before) {
+			continue
+		}
+		// the rest of the index is older
+		if !f.after.IsZero() && created.Before(f.after) {
+			break
+		}
+
+		if len(list.Tasks) == f.pageSize {
+			list.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(last))
+			break
+		}
+
+		t, meta, err := a.loadTaskRecord(taskID)
+		if err == store.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return TaskList{}, err
+		}
+		if !f.matches(t, meta) {
+			continue
+		}
+
+		if f.HistoryLength > 0 {
+			trimHistory(t, f.HistoryLength)
+		} else {
+			t.History = nil
+		}
+
+		list.Tasks = append(list.Tasks, *t)
+		last = position
+	}
+
+	return list, nil
+}
+
+// listTasksHandler serves tasks/list
+func (a *Agent) listTasksHandler(c *gin.Context, r JSONRPCRequest) {
+	params, ok := (r.Params).(TaskListParams)
+	if !ok {
+		e := NewError(ErrorInvalidRequest, "request should include a TaskListParams as params", nil)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	// a caller only sees its own tasks
+	if !a.options.ListAllCallers {
+		caller := a.caller(c.Request)
+		if params.Caller != "" && params.Caller != caller {
+			e := NewError(ErrorInvalidParams, "only the tasks of the caller can be listed", map[string]any{"caller": params.Caller})
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		params.Caller = caller
+	}
+
+	f, err := newTaskFilter(params)
+	if e, ok := err.(JSONRPCError); ok {
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	list, err := a.listTasks(f)
+	if err != nil {
+		e := NewError(ErrorInternal, err.Error(), nil)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, JSONRPCResponse{JSONRPC: "2.0", ID: r.ID, Result: list})
+}
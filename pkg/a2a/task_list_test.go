@@ -0,0 +1,91 @@
+package a2a
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// headerCaller tells the callers apart by a header the test sets
+func headerCaller(r *http.Request) string {
+	return r.Header.Get("X-Test-Caller")
+}
+
+// postAs sends a JSON-RPC request on behalf of the caller and decodes the reply
+func postAs(t *testing.T, url, caller string, method Method, params any) (int, map[string]any) {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Caller", caller)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var reply map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, reply
+}
+
+// listedTasks returns the IDs of the tasks in a tasks/list reply
+func listedTasks(t *testing.T, reply map[string]any) []string {
+	t.Helper()
+
+	result, ok := reply["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("tasks/list failed: %v", reply)
+	}
+
+	var ids []string
+	for _, task := range result["tasks"].([]any) {
+		ids = append(ids, task.(map[string]any)["id"].(string))
+	}
+	return ids
+}
+
+func TestListTasksOnlyReturnsTheTasksOfTheCaller(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Lister"}, WithAgentHandler(completeHandler{}), WithCaller(headerCaller))
+	srv, paths := serveAgent(t, a)
+	url := srv.URL + paths.RPC
+
+	for id, caller := range map[string]string{"alice-task": "alice", "bob-task": "bob"} {
+		if status, reply := postAs(t, url, caller, TasksSend, TaskSendParams{ID: id, Message: textMessage(id, "hi")}); status != http.StatusOK {
+			t.Fatalf("tasks/send of %s: %d %v", caller, status, reply)
+		}
+	}
+
+	_, reply := postAs(t, url, "alice", TasksList, TaskListParams{})
+	if ids := listedTasks(t, reply); len(ids) != 1 || ids[0] != "alice-task" {
+		t.Fatalf("alice listed %v, want only alice-task", ids)
+	}
+
+	if status, _ := postAs(t, url, "alice", TasksList, TaskListParams{Caller: "bob"}); status != http.StatusBadRequest {
+		t.Fatalf("alice listing the tasks of bob got %d, want 400", status)
+	}
+}
+
+func TestListAllCallers(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Lister"}, WithAgentHandler(completeHandler{}), WithCaller(headerCaller), WithListAllCallers())
+	srv, paths := serveAgent(t, a)
+	url := srv.URL + paths.RPC
+
+	for id, caller := range map[string]string{"alice-task": "alice", "bob-task": "bob"} {
+		postAs(t, url, caller, TasksSend, TaskSendParams{ID: id, Message: textMessage(id, "hi")})
+	}
+
+	if _, reply := postAs(t, url, "admin", TasksList, TaskListParams{}); len(listedTasks(t, reply)) != 2 {
+		t.Fatalf("listed %v, want the tasks of both callers", reply)
+	}
+
+	_, reply := postAs(t, url, "admin", TasksList, TaskListParams{Caller: "bob"})
+	if ids := listedTasks(t, reply); len(ids) != 1 || ids[0] != "bob-task" {
+		t.Fatalf("listed %v for bob, want only bob-task", ids)
+	}
+}
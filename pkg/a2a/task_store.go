@@ -0,0 +1,296 @@
+package a2a
+
+import (
+	"encoding/json"
+	"time"
+
+	"go-micro.dev/v5/store"
+)
+
+// tasks are kept in the Agent store next to the streaming requests, the prefix
+// keeps them apart from the JSON-RPC IDs used as keys for the latter
+const taskKeyPrefix = "task/"
+
+func taskKey(id string) string {
+	return taskKeyPrefix + id
+}
+
+// saveTask logs the changes to the task and writes the task to the Agent store,
+// stamping the status timestamp when the handler didn't provide one. The state and
+// context indexes follow the task.
+func (a *Agent) saveTask(t *Task) error {
+	_, err := a.storeTask(t)
+	return err
+}
+
+// storeTask saves the task like saveTask, it returns the sequence number of the
+// last event of the task
+func (a *Agent) storeTask(t *Task) (int64, error) {
+	// the events are published once the lock is released, broker subscribers and
+	// webhooks may call the Agent back
+	var events []TaskEvent
+	defer func() {
+		a.publishEvents(t, events)
+		a.notifyPush(t, events)
+	}()
+
+	a.tasksMu.Lock()
+	defer a.tasksMu.Unlock()
+
+	prev, meta, err := a.loadTaskRecord(t.ID)
+	if err != nil && err != store.ErrNotFound {
+		return 0, err
+	}
+	if meta.created.IsZero() {
+		meta.created = time.Now()
+	}
+
+	previous := meta
+	meta.state = t.Status.State
+	if meta.state != previous.state {
+		meta.changed = time.Now()
+	}
+	if skillID, ok := t.Metadata["skillId"].(string); ok {
+		meta.skill = skillID
+	}
+
+	// commitTask projects the events on prev
+	var previousContext string
+	if prev != nil {
+		previousContext = prev.ContextID
+	}
+
+	events, err = a.commitTask(prev, t, &meta)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := a.moveIndex(t.ID, meta.created, stateIndexPrefix(previous.state), stateIndexPrefix(meta.state)); err != nil {
+		return 0, err
+	}
+
+	if err := a.moveIndex(t.ID, meta.created, contextIndexPrefix(previousContext), contextIndexPrefix(t.ContextID)); err != nil {
+		return 0, err
+	}
+
+	return meta.seq, a.moveIndex(t.ID, meta.created, skillIndexPrefix(previous.skill), skillIndexPrefix(meta.skill))
+}
+
+// createTask logs and writes a new task to the Agent store and indexes it by
+// creation time, state, context, skill and the caller who sent it
+func (a *Agent) createTask(t *Task, caller string) error {
+	var events []TaskEvent
+	defer func() {
+		a.publishEvents(t, events)
+		a.notifyPush(t, events)
+	}()
+
+	a.tasksMu.Lock()
+	defer a.tasksMu.Unlock()
+
+	now := time.Now()
+	meta := taskMeta{
+		state:   t.Status.State,
+		created: now,
+		changed: now,
+		caller:  caller,
+	}
+	if skillID, ok := t.Metadata["skillId"].(string); ok {
+		meta.skill = skillID
+	}
+
+	events, err := a.commitTask(nil, t, &meta)
+	if err != nil {
+		return err
+	}
+
+	prefixes := []string{
+		createdIndexPrefix,
+		stateIndexPrefix(meta.state),
+		contextIndexPrefix(t.ContextID),
+		skillIndexPrefix(meta.skill),
+		callerIndexPrefix(meta.caller),
+	}
+
+	for _, prefix := range prefixes {
+		if err := a.moveIndex(t.ID, meta.created, "", prefix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commitTask logs the events taking the stored task prev, nil for a new one, to t
+// and writes their projection as the task record. The events go first so the log
+// is never behind the record. It returns the events logged.
+func (a *Agent) commitTask(prev, t *Task, meta *taskMeta) ([]TaskEvent, error) {
+	if t.Kind == "" {
+		t.Kind = "task"
+	}
+
+	if t.Status.Timestamp == "" {
+		t.Status.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	events := diffTask(prev, t)
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	seq, err := a.appendEvents(t.ID, meta.seq, events)
+	if err != nil {
+		return nil, err
+	}
+	meta.seq = seq
+
+	if prev == nil {
+		prev = &Task{}
+	}
+	projectTask(prev, events)
+
+	return events, a.writeTask(prev, *meta)
+}
+
+// writeTask writes the task record, its metadata carries what the indexes are
+// built from
+func (a *Agent) writeTask(t *Task, meta taskMeta) error {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	if run, ok := a.liveRun(t.ID); ok {
+		run.traceState(t.Status.State)
+	}
+
+	return a.options.Store.Write(&store.Record{
+		Key:      taskKey(t.ID),
+		Value:    raw,
+		Metadata: meta.record(),
+	})
+}
+
+// loadTask reads the task from the Agent store, store.ErrNotFound is returned
+// as is so callers can map it to ErrorTaskNotFound
+func (a *Agent) loadTask(id string) (*Task, error) {
+	t, _, err := a.loadTaskRecord(id)
+	return t, err
+}
+
+// loadTaskRecord reads the task along with the metadata of its record
+func (a *Agent) loadTaskRecord(id string) (*Task, taskMeta, error) {
+	record, err := a.readTaskRecord(id)
+	if err != nil {
+		return nil, taskMeta{}, err
+	}
+
+	var t Task
+	if err := json.Unmarshal(record.Value, &t); err != nil {
+		return nil, taskMeta{}, err
+	}
+
+	return &t, parseTaskMeta(record.Metadata), nil
+}
+
+func (a *Agent) readTaskRecord(id string) (*store.Record, error) {
+	records, err := a.options.Store.Read(taskKey(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	return records[0], nil
+}
+
+// setTaskState moves a stored task to a new state, keeping everything else as is
+func (a *Agent) setTaskState(id string, state TaskState) (*Task, error) {
+	t, err := a.loadTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Status = TaskStatus{State: state}
+
+	return t, a.saveTask(t)
+}
+
+// newSubmittedTask builds the task that represents a TaskSendParams before any
+// handler has seen it
+func newSubmittedTask(params TaskSendParams) *Task {
+	contextID := params.Message.ContextId
+	if contextID == "" {
+		contextID = params.SessionID
+	}
+
+	var metadata map[string]any
+	if len(params.Metadata) > 0 {
+		metadata = make(map[string]any, len(params.Metadata))
+		for k, v := range params.Metadata {
+			metadata[k] = v
+		}
+	}
+
+	return &Task{
+		Kind:      "task",
+		ID:        params.ID,
+		ContextID: contextID,
+		Status:    TaskStatus{State: TaskStateSubmitted},
+		History:   []Message{params.Message},
+		Metadata:  metadata,
+	}
+}
+
+// setMetadata sets a task metadata entry, creating the map when needed
+func setMetadata(t *Task, key string, value any) {
+	if t.Metadata == nil {
+		t.Metadata = make(map[string]any)
+	}
+	t.Metadata[key] = value
+}
+
+// resultTask extracts the Task from a handler Result, handlers may return
+// either a Task or a *Task
+func resultTask(r Result) (*Task, bool) {
+	switch t := r.(type) {
+	case *Task:
+		return t, t != nil
+	case Task:
+		return &t, true
+	}
+	return nil, false
+}
+
+// applyEvent projects a streamed event onto the task, status events replace the
+// current status and artifact events are appended to the artifacts
+func applyEvent(t *Task, r Result) {
+	switch e := r.(type) {
+	case *TaskStatusUpdateEvent:
+		t.Status = e.Status
+	case TaskStatusUpdateEvent:
+		t.Status = e.Status
+	case *TaskArtifactUpdateEvent:
+		t.Artifacts = append(t.Artifacts, e.Artifact)
+	case TaskArtifactUpdateEvent:
+		t.Artifacts = append(t.Artifacts, e.Artifact)
+	}
+}
+
+// isTerminal reports whether the task reached a state it doesn't leave
+func isTerminal(state TaskState) bool {
+	switch state {
+	case TaskStateCompleted, TaskStateCanceled, TaskStateFailed, TaskStateRejected:
+		return true
+	}
+	return false
+}
+
+// trimHistory keeps only the last n messages of the task history, n <= 0 keeps all
+func trimHistory(t *Task, n int) {
+	if n > 0 && len(t.History) > n {
+		t.History = t.History[len(t.History)-n:]
+	}
+}
@@ -0,0 +1,235 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans started by the package
+const tracerName = "github.com/micro/micro-a2a/pkg/a2a"
+
+// Span attributes describing A2A tasks, next to the OpenTelemetry rpc.* ones
+const (
+	AttributeTaskID    = attribute.Key("a2a.task.id")
+	AttributeSkillID   = attribute.Key("a2a.skill.id")
+	AttributeTaskState = attribute.Key("a2a.task.state")
+)
+
+// defaultPropagator carries the W3C traceparent, tracestate and baggage headers, it
+// is used when no propagator is configured since the global one is a no-op
+var defaultPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+func tracerFrom(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(tracerName)
+}
+
+func propagatorFrom(p propagation.TextMapPropagator) propagation.TextMapPropagator {
+	if p == nil {
+		return defaultPropagator
+	}
+	return p
+}
+
+// tracer returns the tracer of the Agent, the global provider is resolved on use so
+// it can be set after the Agent is created
+func (a *Agent) tracer() trace.Tracer {
+	return tracerFrom(a.options.TracerProvider)
+}
+
+// recordError marks the span as failed with a JSON-RPC error
+func recordError(span trace.Span, e JSONRPCError) {
+	span.SetAttributes(
+		semconv.RPCJsonrpcErrorCode(int(e.Code)),
+		semconv.RPCJsonrpcErrorMessage(e.Message),
+	)
+	span.SetStatus(codes.Error, e.Message)
+}
+
+// paramsTaskID returns the task the params refer to
+func paramsTaskID(p Params) string {
+	switch v := p.(type) {
+	case TaskSendParams:
+		if v.ID == "" {
+			return v.Message.TaskId
+		}
+		return v.ID
+	case TaskQueryParams:
+		return v.ID
+	case TaskIDParams:
+		return v.ID
+	}
+	return ""
+}
+
+// requestAttributes describe a JSON-RPC request on a span
+func requestAttributes(r JSONRPCRequest) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.RPCSystemKey.String("jsonrpc"),
+		semconv.RPCMethod(string(r.Method)),
+	}
+
+	if r.ID != nil {
+		attrs = append(attrs, semconv.RPCJsonrpcRequestID(fmt.Sprintf("%v", r.ID)))
+	}
+
+	if id := paramsTaskID(r.Params); id != "" {
+		attrs = append(attrs, AttributeTaskID.String(id))
+	}
+
+	return attrs
+}
+
+// skillAttributes describe the skill a request is routed to, requests going to the
+// default handlers have none
+func skillAttributes(skillID string) []attribute.KeyValue {
+	if skillID == "" {
+		return nil
+	}
+	return []attribute.KeyValue{AttributeSkillID.String(skillID)}
+}
+
+// annotate adds attributes to the span of the request being served, the ones
+// describing the request end up in the request log as well
+func annotate(c *gin.Context, attrs ...attribute.KeyValue) {
+	trace.SpanFromContext(c.Request.Context()).SetAttributes(attrs...)
+	collectLogFields(c, attrs)
+}
+
+// rpcMethodKey is the gin context key the handlers store the JSON-RPC method under
+const rpcMethodKey = "rpcMethod"
+
+// instrumentMiddleware starts a server span for every request, joining the trace
+// of the caller when the request carries a traceparent header. The handlers name
+// the span after the JSON-RPC method and the JSON-RPC error replied, if any, is
+// recorded on it, counted in the request metrics and logged.
+func instrumentMiddleware(a *Agent) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		ctx := propagatorFrom(a.options.Propagator).Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		ctx, span := a.tracer().Start(ctx, c.Request.Method+" "+c.FullPath(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.RPCSystemKey.String("jsonrpc"),
+				semconv.RPCService(a.name()),
+			),
+		)
+		defer span.End()
+
+		w := &errorCapture{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(w.Status()))
+
+		code := codeOK
+		if w.Status() >= http.StatusBadRequest {
+			var e JSONRPCError
+			if err := json.Unmarshal(w.body.Bytes(), &e); err == nil && e.Code != 0 {
+				recordError(span, e)
+				code = errorCode(e)
+			} else {
+				span.SetStatus(codes.Error, http.StatusText(w.Status()))
+				code = strconv.Itoa(w.Status())
+			}
+		}
+
+		// streams are measured by the active_streams gauge instead
+		if method := c.GetString(rpcMethodKey); method != "" {
+			a.metrics.observeRequest(method, code, start)
+		}
+
+		a.logRequest(c, code, start)
+	}
+}
+
+// errorCapture keeps the body of error replies so the JSON-RPC error can be
+// recorded on the span
+type errorCapture struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// error replies are a single JSONRPCError, there is no need to keep more
+const maxCapturedError = 4096
+
+func (w *errorCapture) Write(b []byte) (int, error) {
+	if w.Status() >= http.StatusBadRequest && w.body.Len() < maxCapturedError {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *errorCapture) WriteString(s string) (int, error) {
+	if w.Status() >= http.StatusBadRequest && w.body.Len() < maxCapturedError {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// traceState records a state transition of the task on the span of its run
+func (run *taskRun) traceState(state TaskState) {
+	run.traceMu.Lock()
+	defer run.traceMu.Unlock()
+
+	if state == run.state {
+		return
+	}
+	run.state = state
+
+	run.span.AddEvent("a2a.task.state", trace.WithAttributes(AttributeTaskState.String(string(state))))
+	if state == TaskStateFailed {
+		run.span.SetStatus(codes.Error, "task failed")
+	}
+}
+
+// endSpan ends the span of the run with the state the task was left in and
+// measures the run
+func (run *taskRun) endSpan() {
+	run.traceMu.Lock()
+	defer run.traceMu.Unlock()
+
+	if run.state != "" {
+		run.span.SetAttributes(AttributeTaskState.String(string(run.state)))
+	}
+	run.span.End()
+
+	run.agent.metrics.observeTask(run.skillID, run.state, run.queued)
+}
+
+// startSpan starts the client span of a request
+func (o ClientOptions) startSpan(ctx context.Context, req JSONRPCRequest) (context.Context, trace.Span) {
+	return tracerFrom(o.TracerProvider).Start(ctx, string(req.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(requestAttributes(req)...),
+	)
+}
+
+// injectTrace writes the trace context of ctx to the request headers
+func (o ClientOptions) injectTrace(ctx context.Context, headers map[string]string) {
+	carrier := propagation.HeaderCarrier(http.Header{})
+	propagatorFrom(o.Propagator).Inject(ctx, carrier)
+
+	for _, k := range carrier.Keys() {
+		headers[k] = carrier.Get(k)
+	}
+}
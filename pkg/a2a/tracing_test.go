@@ -0,0 +1,99 @@
+package a2a
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracePropagatesFromClientToAgent(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	a := NewAgent(AgentCard{Name: "Traced"}, WithAgentHandler(completeHandler{}), WithTracerProvider(tp))
+	srv, paths := serveAgent(t, a)
+
+	c := NewA2AClient(WithClientTracerProvider(tp))
+	res, err := c.SendReq(context.Background(), TasksSend, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")}, srv.URL+paths.RPC)
+	if err != nil || res.Error != nil {
+		t.Fatalf("tasks/send: err = %v, response error = %v", err, res.Error)
+	}
+
+	// the run span ends once the handler returned, possibly after the reply
+	var spans tracetest.SpanStubs
+	for deadline := time.Now().Add(time.Second); len(spans) < 3 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		spans = exporter.GetSpans()
+	}
+	client := findSpan(t, spans, trace.SpanKindClient, string(TasksSend))
+	server := findSpan(t, spans, trace.SpanKindServer, string(TasksSend))
+	run := findSpan(t, spans, trace.SpanKindInternal, "a2a.task")
+
+	// the traceparent header makes the server span a child of the client span
+	if server.Parent.SpanID() != client.SpanContext.SpanID() || server.SpanContext.TraceID() != client.SpanContext.TraceID() {
+		t.Fatalf("the server span isn't a child of the client span: parent %v, client %v", server.Parent, client.SpanContext)
+	}
+	if run.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Fatalf("the run span isn't a child of the server span: parent %v, server %v", run.Parent, server.SpanContext)
+	}
+
+	for _, want := range []attribute.KeyValue{
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", string(TasksSend)),
+		AttributeTaskID.String("t1"),
+	} {
+		for _, s := range []tracetest.SpanStub{client, server} {
+			if !hasAttribute(s, want) {
+				t.Errorf("span %q (%v) lacks %v: %v", s.Name, s.SpanKind, want, s.Attributes)
+			}
+		}
+	}
+	if !hasAttribute(run, AttributeTaskState.String(string(TaskStateCompleted))) {
+		t.Errorf("the run span lacks the final state of the task: %v", run.Attributes)
+	}
+}
+
+func TestTraceRecordsJSONRPCErrors(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	a := NewAgent(AgentCard{Name: "Traced"}, WithAgentHandler(completeHandler{}), WithTracerProvider(tp))
+	srv, paths := serveAgent(t, a)
+
+	c := NewA2AClient(WithClientTracerProvider(tp))
+	if _, err := c.SendReq(context.Background(), TasksGet, TaskQueryParams{ID: "missing"}, srv.URL+paths.RPC); err != nil {
+		t.Fatal(err)
+	}
+
+	server := findSpan(t, exporter.GetSpans(), trace.SpanKindServer, string(TasksGet))
+	if !hasAttribute(server, attribute.Int("rpc.jsonrpc.error_code", int(ErrorTaskNotFound))) {
+		t.Fatalf("the server span lacks the JSON-RPC error code: %v", server.Attributes)
+	}
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, kind trace.SpanKind, name string) tracetest.SpanStub {
+	t.Helper()
+
+	for _, s := range spans {
+		if s.SpanKind == kind && s.Name == name {
+			return s
+		}
+	}
+
+	t.Fatalf("no %v span named %q among %d spans", kind, name, len(spans))
+	return tracetest.SpanStub{}
+}
+
+func hasAttribute(s tracetest.SpanStub, want attribute.KeyValue) bool {
+	for _, kv := range s.Attributes {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
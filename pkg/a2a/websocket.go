@@ -0,0 +1,275 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go-micro.dev/v5/logger"
+)
+
+// A WebSocket session carries JSON-RPC requests and their responses in both
+// directions over one connection, a client can answer a task waiting for input
+// while it streams the task. Every message is a request or a response, the
+// responses of the requests in flight are told apart by their ID:
+//
+//	tasks/sendSubscribe, tasks/resubscribe   a response per event, until the final one
+//	any other method                         a single response
+//
+// The requests go through the same handlers as their HTTP counterparts, with the
+// headers the session was opened with, the events of the streams are written to
+// the session as the tasks publish them.
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+const (
+	// webSocketWriteTimeout is how long writing a message to a session may take
+	// before the session is deemed gone
+	webSocketWriteTimeout = 10 * time.Second
+
+	// webSocketReadLimit is the size of the largest message a session reads, a
+	// larger one closes the session
+	webSocketReadLimit = 4 << 20
+
+	// webSocketMaxInflight is how many requests of a session are served at once,
+	// streams included. The requests over it are refused with
+	// ErrorServiceUnavailable.
+	webSocketMaxInflight = 32
+)
+
+// wsSession serves the requests a client sends over its WebSocket connection
+type wsSession struct {
+	agent *Agent
+	paths agentPaths
+	conn  *websocket.Conn
+	// req is the upgrade request, its headers and remote address are those of the
+	// requests of the session
+	req *http.Request
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// inflight counts the requests being served, slots bounds them
+	inflight sync.WaitGroup
+	slots    chan struct{}
+
+	writeMu sync.Mutex
+}
+
+// webSocketHandler upgrades the connection and serves the session until the client
+// closes it
+func webSocketHandler(a *Agent, paths agentPaths) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// the Upgrader replies to the requests it refuses
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			a.options.Logger.Log(logger.ErrorLevel, err)
+			return
+		}
+
+		// the streams of the session are its own, whatever IDs its requests have
+		ctx, cancel := context.WithCancel(withStreamSession(context.Background(), uuid.NewString()))
+		s := &wsSession{
+			agent:  a,
+			paths:  paths,
+			conn:   conn,
+			req:    c.Request,
+			ctx:    ctx,
+			cancel: cancel,
+			slots:  make(chan struct{}, webSocketMaxInflight),
+		}
+		s.serve()
+	}
+}
+
+// serve reads the requests of the session and serves each one concurrently, the
+// streams of the session end with it but not their tasks
+func (s *wsSession) serve() {
+	defer s.conn.Close()
+	defer s.inflight.Wait()
+	defer s.cancel()
+
+	if d := s.agent.streamHeartbeat(); d > 0 {
+		go s.keepAlive(d)
+	}
+
+	s.conn.SetReadLimit(webSocketReadLimit)
+
+	for {
+		_, msg, err := s.conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.agent.options.Logger.Log(logger.DebugLevel, err)
+			}
+			return
+		}
+
+		// the session keeps reading, a client answering a task while it streams
+		// others learns it has too many requests in flight
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			s.refuse(msg)
+			continue
+		}
+
+		s.inflight.Add(1)
+		go func() {
+			defer s.inflight.Done()
+			defer func() { <-s.slots }()
+			s.dispatch(msg)
+		}()
+	}
+}
+
+// refuse replies to a request the session has no room for
+func (s *wsSession) refuse(msg []byte) {
+	var head struct {
+		ID any `json:"id"`
+	}
+	_ = json.Unmarshal(msg, &head)
+
+	s.reply(head.ID, NewError(ErrorServiceUnavailable, "too many requests in flight on the session", map[string]any{"limit": webSocketMaxInflight}))
+}
+
+// keepAlive pings the client so proxies don't close an idle session
+func (s *wsSession) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketWriteTimeout))
+			if err != nil {
+				return
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// write sends a message to the client, one writer at a time
+func (s *wsSession) write(msg []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout)); err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(websocket.TextMessage, msg)
+}
+
+// reply sends the error of the request with the given ID
+func (s *wsSession) reply(id any, e JSONRPCError) {
+	raw, err := json.Marshal(JSONRPCResponse{JSONRPC: "2.0", ID: id, Error: &e})
+	if err != nil {
+		s.agent.options.Logger.Log(logger.ErrorLevel, err)
+		return
+	}
+
+	if err := s.write(raw); err != nil {
+		s.agent.options.Logger.Log(logger.DebugLevel, err)
+	}
+}
+
+// forward sends what the handler of the request with the given ID replied, the
+// errors handlers reply with as bare JSONRPCError are wrapped in a response
+func (s *wsSession) forward(id any, w *frameWriter) {
+	if !w.failed() {
+		if err := s.write(bytes.TrimSpace(w.body.Bytes())); err != nil {
+			s.agent.options.Logger.Log(logger.DebugLevel, err)
+		}
+		return
+	}
+
+	var e JSONRPCError
+	if err := json.Unmarshal(w.body.Bytes(), &e); err != nil || e.Code == 0 {
+		var res JSONRPCResponse
+		if err := json.Unmarshal(w.body.Bytes(), &res); err == nil && res.Error != nil {
+			e = *res.Error
+		} else {
+			e = NewError(ErrorInternal, http.StatusText(w.status), nil)
+		}
+	}
+	s.reply(id, e)
+}
+
+// dispatch serves a request of the session, the streaming methods get their
+// events, any other method its response
+func (s *wsSession) dispatch(msg []byte) {
+	var head struct {
+		ID     any    `json:"id"`
+		Method Method `json:"method"`
+	}
+	if err := json.Unmarshal(msg, &head); err != nil {
+		s.reply(nil, NewError(ErrorParse, "the message isn't a JSON-RPC request", nil))
+		return
+	}
+
+	switch head.Method {
+	case TasksSendSubscribe, TasksResubscribe:
+		if s.paths.Stream == "" {
+			s.reply(head.ID, NewError(ErrorUnsupportedOperation, "the agent doesn't support streaming", nil))
+			return
+		}
+
+		// the request is accepted first, its stream is opened then
+		w := &frameWriter{}
+		s.agent.local.ServeHTTP(w, s.request(s.ctx, http.MethodPost, s.paths.Stream, msg))
+		if w.failed() {
+			s.forward(head.ID, w)
+			return
+		}
+
+		// the events go to the client as the task publishes them, the stream ends
+		// when one can't be written
+		ctx, cancel := context.WithCancel(s.ctx)
+		defer cancel()
+
+		sink := func(res JSONRPCResponse) error {
+			raw, err := json.Marshal(res)
+			if err != nil {
+				return err
+			}
+			return s.write(raw)
+		}
+
+		stream := &frameWriter{}
+		s.agent.local.ServeHTTP(stream, s.request(withStreamSink(ctx, sink), http.MethodGet, s.paths.Stream+"?id="+url.QueryEscape(fmt.Sprint(head.ID)), nil))
+		if stream.failed() {
+			s.forward(head.ID, stream)
+		}
+
+	default:
+		w := &frameWriter{}
+		s.agent.local.ServeHTTP(w, s.request(s.ctx, http.MethodPost, s.paths.RPC, msg))
+		s.forward(head.ID, w)
+	}
+}
+
+// request builds the HTTP request a message of the session is served as, it is
+// done with ctx, once the session ends at the latest
+func (s *wsSession) request(ctx context.Context, method, target string, body []byte) *http.Request {
+	header := s.req.Header.Clone()
+	for _, h := range []string{"Connection", "Upgrade", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions", "Sec-Websocket-Protocol", DeadlineHeader, "Last-Event-ID"} {
+		header.Del(h)
+	}
+
+	r := localRequest(ctx, method, target, body, header)
+	r.RemoteAddr = s.req.RemoteAddr
+	r.Host = s.req.Host
+
+	return r
+}
@@ -0,0 +1,302 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ErrSessionClosed is returned by the requests of a Session that is closed
+var ErrSessionClosed = errors.New("the session is closed")
+
+// Session is a WebSocket connection to an agent served with the WithWebSocket
+// option, the requests sent over it are in flight concurrently and their responses
+// are told apart by their ID. It is safe for concurrent use.
+type Session struct {
+	client *A2AClient
+	conn   *websocket.Conn
+
+	writeMu sync.Mutex
+
+	mu    sync.Mutex
+	calls map[string]*call
+	// err is why the session ended, once it did
+	err  error
+	done chan struct{}
+}
+
+// call is a request of a session waiting for its responses
+type call struct {
+	res    chan JSONRPCResponse
+	ctx    context.Context
+	stream bool
+	// stop cancels forgetting the call once ctx is done
+	stop func() bool
+}
+
+// DialWebSocket opens a session with the agent WebSocket endpoint at addr, e.g.
+// ws://localhost:8081/MyAgent/ws, http and https URLs are dialed as ws and wss. The
+// session carries the trace context of ctx, ctx doesn't bound it.
+func (c *A2AClient) DialWebSocket(ctx context.Context, addr string) (*Session, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, NewError(ErrorInvalidRequest, err.Error(), nil)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	}
+
+	header := http.Header{}
+	for k, v := range c.options.headers(ctx) {
+		if k != DeadlineHeader {
+			header.Set(k, v)
+		}
+	}
+
+	conn, res, err := websocket.DefaultDialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if res != nil {
+			return nil, NewError(ErrorInternal, fmt.Sprintf("failed to establish a connection with [%v]: %v (%v)", u, err, res.Status), nil)
+		}
+		return nil, NewError(ErrorInternal, fmt.Sprintf("failed to establish a connection with [%v]: %v", u, err), nil)
+	}
+
+	s := &Session{
+		client: c,
+		conn:   conn,
+		calls:  make(map[string]*call),
+		done:   make(chan struct{}),
+	}
+	go s.read()
+
+	return s, nil
+}
+
+// Send sends a request over the session and returns its response, like SendReq.
+// The streaming methods go through Stream.
+func (s *Session) Send(ctx context.Context, method Method, params Params) (JSONRPCResponse, error) {
+	if err := validateMethodParams(method, params); err != nil {
+		return JSONRPCResponse{}, NewError(ErrorInvalidRequest, err.Error(), nil)
+	}
+	if method == TasksSendSubscribe || method == TasksResubscribe {
+		return JSONRPCResponse{}, NewError(ErrorInvalidRequest, fmt.Sprintf("%s responds with a stream, use Stream", method), nil)
+	}
+
+	req := JSONRPCRequest{ID: uuid.NewString(), JSONRPC: "2.0", Method: method, Params: params}
+
+	ctx, span := s.client.options.startSpan(ctx, req)
+	defer span.End()
+
+	start := time.Now()
+	code := codeOK
+	defer func() { s.client.metrics.observeRequest(method, code, start) }()
+
+	results, err := s.call(ctx, req, false)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		code = "error"
+		return JSONRPCResponse{}, NewError(ErrorInternal, fmt.Sprintf("failed to send request: %v", err), nil)
+	}
+
+	select {
+	case res, ok := <-results:
+		if !ok {
+			err = s.closeErr()
+			break
+		}
+		if res.Error != nil {
+			recordError(span, *res.Error)
+			code = errorCode(*res.Error)
+		}
+		return res, nil
+
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	code = "error"
+	return JSONRPCResponse{}, NewError(ErrorInternal, fmt.Sprintf("failed to send request: %v", err), nil)
+}
+
+// Stream sends a tasks/sendSubscribe or tasks/resubscribe request over the session
+// and returns the events of the task, the channel is closed after the final event,
+// once ctx is done or when the session ends. An error the agent replied with is
+// the only event of the stream.
+func (s *Session) Stream(ctx context.Context, method Method, params Params) (chan JSONRPCResponse, error) {
+	if err := validateMethodParams(method, params); err != nil {
+		return nil, NewError(ErrorInvalidRequest, err.Error(), nil)
+	}
+	if method != TasksSendSubscribe && method != TasksResubscribe {
+		return nil, NewError(ErrorInvalidRequest, fmt.Sprintf("%s doesn't respond with a stream, use Send", method), nil)
+	}
+
+	req := JSONRPCRequest{ID: uuid.NewString(), JSONRPC: "2.0", Method: method, Params: params}
+
+	_, span := s.client.options.startSpan(ctx, req)
+	defer span.End()
+
+	results, err := s.call(ctx, req, true)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, NewError(ErrorInternal, fmt.Sprintf("failed to send request: %v", err), nil)
+	}
+
+	return results, nil
+}
+
+// Close ends the session, the requests still in flight are abandoned
+func (s *Session) Close() error {
+	s.writeMu.Lock()
+	err := s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	s.writeMu.Unlock()
+
+	if cerr := s.conn.Close(); err == nil {
+		err = cerr
+	}
+	<-s.done
+
+	if errors.Is(err, websocket.ErrCloseSent) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// Done is closed once the session ended, Err tells why
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the session ended, nil while it is open
+func (s *Session) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// call registers the request and sends it, the responses come on the channel
+func (s *Session) call(ctx context.Context, req JSONRPCRequest, stream bool) (chan JSONRPCResponse, error) {
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	id := fmt.Sprint(req.ID)
+	cl := &call{res: make(chan JSONRPCResponse, 100), ctx: ctx, stream: stream}
+
+	s.mu.Lock()
+	if s.calls == nil {
+		s.mu.Unlock()
+		return nil, s.closeErr()
+	}
+	s.calls[id] = cl
+	cl.stop = context.AfterFunc(ctx, func() { s.forget(id) })
+	s.mu.Unlock()
+
+	s.writeMu.Lock()
+	err = s.conn.WriteMessage(websocket.TextMessage, raw)
+	s.writeMu.Unlock()
+
+	if err != nil {
+		s.forget(id)
+		return nil, err
+	}
+	return cl.res, nil
+}
+
+// forget stops waiting for the responses of the request
+func (s *Session) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cl, ok := s.calls[id]; ok {
+		delete(s.calls, id)
+		cl.stop()
+		close(cl.res)
+	}
+}
+
+// read hands the responses over to their requests until the session ends
+func (s *Session) read() {
+	defer close(s.done)
+
+	for {
+		_, msg, err := s.conn.ReadMessage()
+		if err != nil {
+			s.end(err)
+			return
+		}
+
+		var res JSONRPCResponse
+		if err := json.Unmarshal(msg, &res); err != nil {
+			log.Println(err)
+			continue
+		}
+		s.deliver(res, finalEvent(string(msg)))
+	}
+}
+
+func (s *Session) deliver(res JSONRPCResponse, final bool) {
+	id := fmt.Sprint(res.ID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cl, ok := s.calls[id]
+	if !ok {
+		return
+	}
+
+	select {
+	case cl.res <- res:
+	case <-cl.ctx.Done():
+	}
+
+	if !cl.stream || final {
+		delete(s.calls, id)
+		cl.stop()
+		close(cl.res)
+	}
+}
+
+// end abandons the requests in flight once the session ended
+func (s *Session) end(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure) || errors.Is(err, net.ErrClosed) {
+		err = ErrSessionClosed
+	}
+	s.err = err
+
+	for _, cl := range s.calls {
+		cl.stop()
+		close(cl.res)
+	}
+	s.calls = nil
+}
+
+func (s *Session) closeErr() error {
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return ErrSessionClosed
+}
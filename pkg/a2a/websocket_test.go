@@ -0,0 +1,293 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialSession opens a WebSocket session with the agent served at srvURL
+func dialSession(t *testing.T, srvURL string, paths agentPaths) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srvURL, "http")+paths.WebSocket, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// sendRequest sends a JSON-RPC request over the session
+func sendRequest(t *testing.T, conn *websocket.Conn, id any, method Method, params any) {
+	t.Helper()
+
+	if err := conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sessionReply is a response read off a session
+type sessionReply struct {
+	ID     any           `json:"id"`
+	Error  *JSONRPCError `json:"error"`
+	Result struct {
+		ID     string      `json:"id"`
+		Status *TaskStatus `json:"status"`
+	} `json:"result"`
+}
+
+func readReply(t *testing.T, conn *websocket.Conn) sessionReply {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var res sessionReply
+	if err := conn.ReadJSON(&res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func webSocketAgent(t *testing.T) (string, agentPaths) {
+	a := NewAgent(AgentCard{Name: "Sockets", Capabilities: &AgentCapabilities{Streaming: true}}, WithAgentStreamHandler(streamHandler{}), WithWebSocket())
+	srv, paths := serveAgent(t, a)
+	return srv.URL, paths
+}
+
+func TestWebSocketSessionsKeepTheirStreamsApart(t *testing.T) {
+	srvURL, paths := webSocketAgent(t)
+
+	// both sessions use the same request ID for different tasks
+	sessions := map[string]*websocket.Conn{}
+	for _, taskID := range []string{"ta", "tb"} {
+		sessions[taskID] = dialSession(t, srvURL, paths)
+	}
+	for taskID, conn := range sessions {
+		sendRequest(t, conn, 1, TasksSendSubscribe, TaskSendParams{ID: taskID, Message: textMessage(taskID, "hi"), Metadata: map[string]any{"ask": true}})
+	}
+
+	for taskID, conn := range sessions {
+		for {
+			res := readReply(t, conn)
+			if res.Error != nil {
+				t.Fatalf("session of %s: %v", taskID, res.Error)
+			}
+			if res.Result.ID != taskID {
+				t.Fatalf("the session of %s got an event of %s", taskID, res.Result.ID)
+			}
+			if res.Result.Status != nil && res.Result.Status.State == TaskStateInputRequired {
+				break
+			}
+		}
+	}
+}
+
+func TestStreamRequestKeysArePerSession(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Keys"})
+
+	key := func(ctx context.Context) string {
+		r, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		k, err := a.streamRequestKey(r, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+
+	one := key(withStreamSession(context.Background(), "one"))
+	two := key(withStreamSession(context.Background(), "two"))
+	plain := key(context.Background())
+	if one == two || one == plain || two == plain {
+		t.Fatalf("keys %q, %q and %q collide", one, two, plain)
+	}
+}
+
+func TestWebSocketSessionLimitsRequestsInFlight(t *testing.T) {
+	srvURL, paths := webSocketAgent(t)
+	conn := dialSession(t, srvURL, paths)
+
+	// paused streams stay in flight
+	for i := 0; i <= webSocketMaxInflight; i++ {
+		id := "t" + string(rune('A'+i))
+		sendRequest(t, conn, id, TasksSendSubscribe, TaskSendParams{ID: id, Message: textMessage(id, "hi"), Metadata: map[string]any{"ask": true}})
+	}
+
+	for {
+		res := readReply(t, conn)
+		if res.Error != nil {
+			if res.Error.Code != ErrorServiceUnavailable {
+				t.Fatalf("error %v, want ErrorServiceUnavailable", res.Error)
+			}
+			return
+		}
+	}
+}
+
+func TestWebSocketSessionRefusesLargeMessages(t *testing.T) {
+	srvURL, paths := webSocketAgent(t)
+	conn := dialSession(t, srvURL, paths)
+
+	large := strings.Repeat("a", webSocketReadLimit+1)
+	sendRequest(t, conn, 1, TasksSend, TaskSendParams{ID: "t1", Message: textMessage("m1", large)})
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var res json.RawMessage
+	if err := conn.ReadJSON(&res); !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Fatalf("read %s, %v, want the session closed", res, err)
+	}
+}
+
+// responseState returns the state and the artifact text a streamed response carries
+func responseState(res JSONRPCResponse) (TaskState, string) {
+	switch r := res.Result.(type) {
+	case Task:
+		return r.Status.State, ""
+	case TaskStatusUpdateEvent:
+		return r.Status.State, ""
+	case TaskArtifactUpdateEvent:
+		if t, ok := r.Artifact.Parts[0].(TextPart); ok {
+			return "", t.Text
+		}
+	}
+	return "", ""
+}
+
+// nextResponse waits for the next response of the stream matching want
+func nextResponse(t *testing.T, results chan JSONRPCResponse, want func(TaskState, string) bool) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case res, ok := <-results:
+			if !ok {
+				t.Fatal("the stream ended early")
+			}
+			if res.Error != nil {
+				t.Fatalf("the stream failed: %v", res.Error)
+			}
+			if want(responseState(res)) {
+				return
+			}
+		case <-timeout:
+			t.Fatal("no matching response")
+		}
+	}
+}
+
+func TestWebSocketClientRoundTrip(t *testing.T) {
+	card := AgentCard{
+		Name:         "Sockets",
+		Capabilities: &AgentCapabilities{Streaming: true},
+		Skills:       []AgentSkill{{ID: "unary", Name: "Unary"}, {ID: "streaming", Name: "Streaming"}},
+	}
+	a := NewAgent(card, WithSkillHandler("unary", completeHandler{}), WithSkillStreamHandler("streaming", streamHandler{}), WithWebSocket())
+	srv, paths := serveAgent(t, a)
+
+	ctx := context.Background()
+	s, err := NewA2AClient().DialWebSocket(ctx, srv.URL+paths.WebSocket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	unary := textMessage("m1", "hi")
+	unary.Metadata = map[string]any{"skillId": "unary"}
+	res, err := s.Send(ctx, TasksSend, TaskSendParams{ID: "t1", Message: unary})
+	if err != nil || res.Error != nil {
+		t.Fatalf("tasks/send: %v %v", err, res.Error)
+	}
+	if state, _ := responseState(res); state != TaskStateCompleted {
+		t.Fatalf("tasks/send result = %+v, want the task completed", res.Result)
+	}
+
+	// the session streams the task while it answers it
+	streaming := textMessage("m2", "hi")
+	streaming.Metadata = map[string]any{"skillId": "streaming"}
+	results, err := s.Stream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t2", Message: streaming, Metadata: map[string]any{"ask": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextResponse(t, results, func(state TaskState, _ string) bool { return state == TaskStateInputRequired })
+
+	followUp, err := s.Stream(ctx, TasksSendSubscribe, TaskSendParams{ID: "t2", Message: textMessage("m3", "Ada")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stream := range []chan JSONRPCResponse{results, followUp} {
+		nextResponse(t, stream, func(_ TaskState, text string) bool { return text == "hello Ada" })
+		nextResponse(t, stream, func(state TaskState, _ string) bool { return state == TaskStateCompleted })
+	}
+
+	res, err = s.Send(ctx, TasksGet, TaskQueryParams{ID: "missing"})
+	if err != nil || res.Error == nil || res.Error.Code != ErrorTaskNotFound {
+		t.Fatalf("tasks/get of a missing task: %v %+v, want ErrorTaskNotFound", err, res.Error)
+	}
+}
+
+func TestWebSocketStreamsAreAdmitted(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Sockets", Capabilities: &AgentCapabilities{Streaming: true}}, WithAgentStreamHandler(streamHandler{}), WithWebSocket(), WithRateLimit(0.01, 1))
+	srv, paths := serveAgent(t, a)
+	conn := dialSession(t, srv.URL, paths)
+
+	sendRequest(t, conn, 1, TasksSendSubscribe, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi")})
+	for {
+		res := readReply(t, conn)
+		if res.Error != nil {
+			t.Fatalf("stream of t1: %+v", res.Error)
+		}
+		if res.Result.Status != nil && res.Result.Status.State == TaskStateCompleted {
+			break
+		}
+	}
+
+	// the second one is over the rate limit
+	sendRequest(t, conn, 2, TasksSendSubscribe, TaskSendParams{ID: "t2", Message: textMessage("m2", "hi")})
+	if res := readReply(t, conn); res.Error == nil || res.Error.Code != ErrorRateLimitExceeded {
+		t.Fatalf("reply = %+v, want ErrorRateLimitExceeded", res)
+	}
+}
+
+func TestWebSocketStreamsLeaveTheirTaskWithTheSession(t *testing.T) {
+	a := NewAgent(AgentCard{Name: "Sockets", Capabilities: &AgentCapabilities{Streaming: true}}, WithAgentStreamHandler(streamHandler{}), WithWebSocket())
+	srv, paths := serveAgent(t, a)
+	conn := dialSession(t, srv.URL, paths)
+
+	sendRequest(t, conn, 1, TasksSendSubscribe, TaskSendParams{ID: "t1", Message: textMessage("m1", "hi"), Metadata: map[string]any{"ask": true}})
+	for {
+		res := readReply(t, conn)
+		if res.Result.Status != nil && res.Result.Status.State == TaskStateInputRequired {
+			break
+		}
+	}
+
+	run, ok := a.liveRun("t1")
+	if !ok {
+		t.Fatal("the task isn't running")
+	}
+	conn.Close()
+
+	// the stream is detached from the paused task
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		run.mu.Lock()
+		n := len(run.subscribers)
+		run.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d streams still attached", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}